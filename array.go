@@ -18,6 +18,9 @@ var arrayPool = &sync.Pool{
 // which can be re-used to add to log messages.
 type Array struct {
 	buf []byte
+
+	depth     int  // number of containers around the array, see Event.depth
+	truncated bool // containers deeper than MaxNestingDepth were dropped
 }
 
 func putArray(a *Array) {
@@ -38,6 +41,8 @@ func putArray(a *Array) {
 func Arr() *Array {
 	a := arrayPool.Get().(*Array)
 	a.buf = a.buf[:0]
+	a.depth = 0
+	a.truncated = false
 	return a
 }
 
@@ -59,10 +64,16 @@ func (a *Array) write(dst []byte) []byte {
 // Object marshals an object that implement the LogObjectMarshaler
 // interface and appends it to the array.
 func (a *Array) Object(obj LogObjectMarshaler) *Array {
+	if a.depth >= MaxNestingDepth {
+		a.truncated = true
+		return a
+	}
 	e := Dict()
+	e.depth = a.depth + 1
 	obj.MarshalZerologObject(e)
 	e.buf = enc.AppendEndMarker(e.buf)
 	a.buf = append(enc.AppendArrayDelim(a.buf), e.buf...)
+	a.truncated = a.truncated || e.truncated
 	putEvent(e)
 	return a
 }
@@ -238,5 +249,6 @@ func (a *Array) MACAddr(ha net.HardwareAddr) *Array {
 func (a *Array) Dict(dict *Event) *Array {
 	dict.buf = enc.AppendEndMarker(dict.buf)
 	a.buf = append(enc.AppendArrayDelim(a.buf), dict.buf...)
+	a.truncated = a.truncated || dict.truncated
 	return a
 }
@@ -0,0 +1,122 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// SegmentedWriter is implemented by the writers writing to successive output
+// segments, like the files of RotateWriter, to let BannerWriter start each of
+// them with a banner.
+type SegmentedWriter interface {
+	// SetSegmentHeader sets a function returning the header written at the
+	// start of each segment, before its first write. It is called with the
+	// writer locked, so it must not log to the writer.
+	SetSegmentHeader(header func() []byte)
+}
+
+// BannerLevelWriter is a LevelWriter writing a banner event, like the
+// version of the program, before the first event written to it, so that
+// each output starts with the context needed to read it:
+//
+//	log := zerolog.New(zerolog.BannerWriter(w, zerolog.BuildInfoBanner()))
+//
+// If the underlying writer is a SegmentedWriter, like RotateWriter, the
+// banner is written at the start of each of its segments instead, like
+// after each rotation or Reopen. It is safe for concurrent use if the
+// underlying writer is.
+type BannerLevelWriter struct {
+	// written is accessed atomically.
+	written uint32
+
+	w         io.Writer
+	banner    func() []byte
+	segmented bool
+	mu        sync.Mutex
+}
+
+// BannerWriter creates a BannerLevelWriter writing to w the banner returned
+// by banner before the first event, or at the start of each segment if w is
+// a SegmentedWriter. The WriteLevel method of w is used if it implements
+// LevelWriter.
+func BannerWriter(w io.Writer, banner func() []byte) *BannerLevelWriter {
+	bw := &BannerLevelWriter{w: w, banner: banner}
+	if sw, ok := w.(SegmentedWriter); ok {
+		sw.SetSegmentHeader(banner)
+		bw.segmented = true
+	}
+	return bw
+}
+
+// Write implements the io.Writer interface.
+func (w *BannerLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The events written
+// concurrently with the banner wait for it to be written first.
+func (w *BannerLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	if !w.segmented && atomic.LoadUint32(&w.written) == 0 {
+		if err := w.writeBanner(); err != nil {
+			return 0, err
+		}
+	}
+	if lw, ok := w.w.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.w.Write(p)
+}
+
+// writeBanner writes the banner unless it was already written. It is
+// written again by the next write if it fails.
+func (w *BannerLevelWriter) writeBanner() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.written != 0 {
+		return nil
+	}
+	if _, err := w.w.Write(w.banner()); err != nil {
+		return err
+	}
+	atomic.StoreUint32(&w.written, 1)
+	return nil
+}
+
+// Close closes the underlying writer, see closeOutput.
+func (w *BannerLevelWriter) Close() error {
+	return closeOutput(w.w)
+}
+
+// BuildInfoBanner returns a banner function for BannerWriter returning an
+// event with the build information of the program, read with
+// debug.ReadBuildInfo, and the time of the banner:
+//
+//	{"time":"2006-01-02T15:04:05Z","go_version":"go1.22.0","path":"example.com/app","version":"v1.2.3","vcs.revision":"4f2e1d0","vcs.time":"2006-01-02T10:00:00Z","vcs.modified":"false","message":"build info"}
+//
+// The module version and the version control settings are omitted when
+// unknown, like in the programs built without module support.
+func BuildInfoBanner() func() []byte {
+	info, _ := debug.ReadBuildInfo()
+	return func() []byte {
+		buf := &bytes.Buffer{}
+		e := New(buf).With().Timestamp().Logger().Log().Str("go_version", runtime.Version())
+		if info != nil {
+			e.Str("path", info.Main.Path)
+			if v := info.Main.Version; v != "" {
+				e.Str("version", v)
+			}
+			for _, s := range info.Settings {
+				switch s.Key {
+				case "vcs.revision", "vcs.time", "vcs.modified":
+					e.Str(s.Key, s.Value)
+				}
+			}
+		}
+		e.Msg("build info")
+		return buf.Bytes()
+	}
+}
@@ -0,0 +1,83 @@
+package zerolog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBannerWriter(t *testing.T) {
+	out := &lockedBuffer{}
+	banners := 0
+	w := BannerWriter(out, func() []byte {
+		banners++
+		return []byte("banner\n")
+	})
+	const goroutines = 8
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fmt.Fprintf(w, "line %d\n", i)
+		}(i)
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != goroutines+1 || lines[0] != "banner" || banners != 1 {
+		t.Errorf("invalid output, banner function called %d times:\n%s", banners, out.String())
+	}
+}
+
+func TestBannerWriterRotate(t *testing.T) {
+	dir := t.TempDir()
+	rw := &RotateWriter{Filename: filepath.Join(dir, "app.log"), MaxSizeBytes: 10}
+	defer rw.Close()
+	w := BannerWriter(rw, func() []byte { return []byte("B\n") })
+	for _, s := range []string{"12345\n", "6789\n", "abcde\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups := rotateBackups(t, dir, "app.log")
+	sort.Strings(backups)
+	var got []string
+	for _, name := range append(backups, "app.log") {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, string(b))
+	}
+	if want := []string{"B\n12345\n", "B\n6789\n", "B\nabcde\n"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("invalid files:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	if err := os.Rename(rw.Filename, filepath.Join(dir, "moved.log")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rw.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("x\n")); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(rw.Filename); string(b) != "B\nx\n" {
+		t.Errorf("invalid file after Reopen: %q", b)
+	}
+}
+
+func TestBuildInfoBanner(t *testing.T) {
+	var banner map[string]interface{}
+	if err := json.Unmarshal([]byte(decodeIfBinaryToString(BuildInfoBanner()())), &banner); err != nil {
+		t.Fatal(err)
+	}
+	if banner["message"] != "build info" || banner["go_version"] == nil || banner["time"] == nil {
+		t.Errorf("invalid banner: %v", banner)
+	}
+}
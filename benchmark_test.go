@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io"
 	"net"
+	"os"
 	"testing"
 	"time"
 )
@@ -44,6 +45,32 @@ func BenchmarkInfo(b *testing.B) {
 	})
 }
 
+func BenchmarkTemplate(b *testing.B) {
+	logger := New(io.Discard).With().Str("service", "api").Logger()
+	b.Run("Event", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			var i int64
+			for pb.Next() {
+				i++
+				logger.Info().Str("op", "read").Int64("bytes", i).Int64("offset", -i).Bool("cached", true).Msg(fakeMessage)
+			}
+		})
+	})
+	b.Run("Template", func(b *testing.B) {
+		tpl := Precompile(logger, InfoLevel, func(e *Event) {
+			e.Str("op", "read").Slot("bytes", 0).Slot("offset", 0).Bool("cached", true).Msg(fakeMessage)
+		})
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			var i int64
+			for pb.Next() {
+				i++
+				tpl.Emit(i, -i)
+			}
+		})
+	})
+}
+
 func BenchmarkContextFields(b *testing.B) {
 	logger := New(io.Discard).With().
 		Str("string", "four!").
@@ -234,6 +261,33 @@ func BenchmarkLogFieldType(b *testing.B) {
 	}
 }
 
+func BenchmarkAnyVsInterface(b *testing.B) {
+	logger := New(io.Discard)
+	values := map[string]interface{}{
+		"string": "four!",
+		"int":    123,
+	}
+	for name, val := range values {
+		val := val
+		b.Run("Any/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info().Any("k", val).Msg("")
+				}
+			})
+		})
+		b.Run("Interface/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					logger.Info().Interface("k", val).Msg("")
+				}
+			})
+		})
+	}
+}
+
 func BenchmarkContextFieldType(b *testing.B) {
 	oldFormat := TimeFieldFormat
 	TimeFieldFormat = TimeFormatUnix
@@ -364,3 +418,48 @@ func BenchmarkContextFieldType(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkFileWriter(b *testing.B) {
+	f, err := os.CreateTemp(b.TempDir(), "zerolog")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { _ = f.Close() })
+	b.Run("Unbuffered", func(b *testing.B) {
+		logger := New(f)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("foo", "bar").Msg(fakeMessage)
+		}
+	})
+	b.Run("Buffered", func(b *testing.B) {
+		w := BufferedWriter(f, 0, time.Second)
+		logger := New(w)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			logger.Info().Str("foo", "bar").Msg(fakeMessage)
+		}
+		_ = w.Sync()
+	})
+}
+
+func BenchmarkMetricsWriter(b *testing.B) {
+	b.Run("Direct", func(b *testing.B) {
+		logger := New(io.Discard)
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info().Msg(fakeMessage)
+			}
+		})
+	})
+	b.Run("Metrics", func(b *testing.B) {
+		logger := New(MetricsWriter(io.Discard))
+		b.ResetTimer()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				logger.Info().Msg(fakeMessage)
+			}
+		})
+	})
+}
@@ -0,0 +1,160 @@
+package zerolog
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelLine is an event sent by a LevelChannelWriter, with its level.
+type LevelLine struct {
+	Level Level
+	Line  []byte
+}
+
+// ChannelLevelWriter is a LevelWriter sending a copy of each event to a
+// channel, for the tests and the custom shippers reading the events from a
+// goroutine:
+//
+//	ch := make(chan []byte, 1024)
+//	log := zerolog.New(zerolog.ChannelWriter(ch, zerolog.ChannelNonBlocking()))
+//	go func() {
+//	    for line := range ch {
+//	        ship(line)
+//	    }
+//	}()
+//
+// The events are copied, as the loggers reuse their buffers once written.
+// It is safe for concurrent use.
+type ChannelLevelWriter struct {
+	// dropped is accessed atomically. It comes first to keep it 64-bit
+	// aligned on 32-bit platforms.
+	dropped uint64
+
+	lines        chan<- []byte    // channel of ChannelWriter, or nil
+	levelLines   chan<- LevelLine // channel of LevelChannelWriter, or nil
+	nonBlocking  bool
+	closeChannel bool
+
+	// mu is held for reading by the sends and for writing by Close, so that
+	// the channel is never closed during a send.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// ChannelWriter creates a ChannelLevelWriter sending the events to ch.
+// Unless the ChannelNonBlocking option is set, the writes block while ch
+// is full.
+func ChannelWriter(ch chan<- []byte, options ...func(w *ChannelLevelWriter)) *ChannelLevelWriter {
+	w := &ChannelLevelWriter{lines: ch}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// LevelChannelWriter is like ChannelWriter but sends the events with their
+// level. The events written with Write are sent with NoLevel.
+func LevelChannelWriter(ch chan<- LevelLine, options ...func(w *ChannelLevelWriter)) *ChannelLevelWriter {
+	w := &ChannelLevelWriter{levelLines: ch}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// ChannelNonBlocking is a ChannelWriter and LevelChannelWriter option
+// dropping the events when the channel is full instead of waiting for the
+// reader. The dropped events are counted, see Dropped.
+func ChannelNonBlocking() func(w *ChannelLevelWriter) {
+	return func(w *ChannelLevelWriter) {
+		w.nonBlocking = true
+	}
+}
+
+// ChannelCloseOnClose is a ChannelWriter and LevelChannelWriter option
+// closing the channel when the writer is closed, to end the range loops of
+// the readers.
+func ChannelCloseOnClose() func(w *ChannelLevelWriter) {
+	return func(w *ChannelLevelWriter) {
+		w.closeChannel = true
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *ChannelLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The events dropped
+// because the channel is full are reported as written.
+func (w *ChannelLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		return 0, errors.New("zerolog: write to closed ChannelLevelWriter")
+	}
+	line := append([]byte(nil), p...)
+	var sent bool
+	if w.levelLines != nil {
+		sent = w.sendLevelLine(LevelLine{Level: l, Line: line})
+	} else {
+		sent = w.sendLine(line)
+	}
+	if !sent {
+		atomic.AddUint64(&w.dropped, 1)
+	}
+	return len(p), nil
+}
+
+func (w *ChannelLevelWriter) sendLine(line []byte) bool {
+	if !w.nonBlocking {
+		w.lines <- line
+		return true
+	}
+	select {
+	case w.lines <- line:
+		return true
+	default:
+		return false
+	}
+}
+
+func (w *ChannelLevelWriter) sendLevelLine(line LevelLine) bool {
+	if !w.nonBlocking {
+		w.levelLines <- line
+		return true
+	}
+	select {
+	case w.levelLines <- line:
+		return true
+	default:
+		return false
+	}
+}
+
+// Dropped returns the number of events dropped because the channel was
+// full, with the ChannelNonBlocking option.
+func (w *ChannelLevelWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+// Close makes the next writes fail, and closes the channel with the
+// ChannelCloseOnClose option. In blocking mode, it waits for the pending
+// writes to be sent.
+func (w *ChannelLevelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	if w.closeChannel {
+		if w.levelLines != nil {
+			close(w.levelLines)
+		} else {
+			close(w.lines)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package zerolog
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestChannelWriter(t *testing.T) {
+	ch := make(chan []byte)
+	w := ChannelWriter(ch, ChannelCloseOnClose())
+	var got []string
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for line := range ch {
+			got = append(got, decodeIfBinaryToString(line))
+		}
+	}()
+	log := New(w)
+	log.Info().Msg("a")
+	log.Warn().Msg("b")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	want := []string{`{"level":"info","message":"a"}` + "\n", `{"level":"warn","message":"b"}` + "\n"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if _, err := w.Write([]byte("c\n")); err == nil {
+		t.Error("Write after Close did not fail")
+	}
+}
+
+func TestChannelWriterNonBlocking(t *testing.T) {
+	ch := make(chan []byte, 2)
+	w := ChannelWriter(ch, ChannelNonBlocking())
+	for i := 0; i < 5; i++ {
+		if _, err := fmt.Fprintf(w, "line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, want := w.Dropped(), uint64(3); got != want {
+		t.Errorf("Dropped() = %d, want %d", got, want)
+	}
+	for _, want := range []string{"line 0\n", "line 1\n"} {
+		if got := string(<-ch); got != want {
+			t.Errorf("invalid line: got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestChannelWriterCopy(t *testing.T) {
+	ch := make(chan []byte, 1)
+	w := ChannelWriter(ch)
+	p := []byte("line\n")
+	if _, err := w.Write(p); err != nil {
+		t.Fatal(err)
+	}
+	copy(p, "XXXX")
+	if got, want := string(<-ch), "line\n"; got != want {
+		t.Errorf("invalid line: got %q, want %q", got, want)
+	}
+}
+
+func TestLevelChannelWriter(t *testing.T) {
+	const goroutines, events = 4, 100
+	ch := make(chan LevelLine, goroutines*events)
+	w := LevelChannelWriter(ch, ChannelCloseOnClose())
+	log := New(w)
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < events; j++ {
+				log.Error().Int("i", i).Int("j", j).Send()
+			}
+		}(i)
+	}
+	wg.Wait()
+	w.Close()
+	seen := map[string]bool{}
+	for line := range ch {
+		if line.Level != ErrorLevel {
+			t.Errorf("invalid level %v", line.Level)
+		}
+		seen[decodeIfBinaryToString(line.Line)] = true
+	}
+	if len(seen) != goroutines*events {
+		t.Fatalf("received %d distinct events, want %d", len(seen), goroutines*events)
+	}
+	if want := `{"level":"error","i":3,"j":99}` + "\n"; !seen[want] {
+		t.Errorf("event %q not received", want)
+	}
+}
@@ -2,15 +2,21 @@ package zerolog
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"runtime/debug"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
 
 	"github.com/goccy/go-json"
 
@@ -42,6 +48,10 @@ var (
 
 const (
 	consoleDefaultTimeFormat = time.Kitchen
+
+	// ConsoleTimeFormatElapsed is a ConsoleWriter.TimeFormat rendering the
+	// timestamps as the duration elapsed since ReferenceTime, like "+1.5s".
+	ConsoleTimeFormatElapsed = "ELAPSED"
 )
 
 // Formatter transforms the input into a formatted string.
@@ -49,25 +59,116 @@ type Formatter func(interface{}) string
 
 // ConsoleWriter parses the JSON input and writes it in an
 // (optionally) colorized, human-friendly format to Out.
+//
+// ConsoleWriter is safe for concurrent use: each line is written with a
+// single call to the Write method of its output. The writes to os.Stdout and
+// os.Stderr are serialized with the ones of the other ConsoleWriters, and
+// the writes of a ConsoleWriter created by NewConsoleWriter, and of its
+// copies, are serialized, so that the lines don't interleave even if the
+// Write method of the output isn't atomic.
 type ConsoleWriter struct {
 	// Out is the output destination.
 	Out io.Writer
 
-	// NoColor disables the colorized output.
+	// ErrOut, if set, is the output destination of the lines whose level is
+	// ErrLevel or above, like stderr for a command line tool writing its
+	// other lines to stdout. The lines without a level go to Out.
+	ErrOut io.Writer
+
+	// ErrLevel is the lowest level of the lines written to ErrOut.
+	// NewConsoleWriter sets it to WarnLevel.
+	ErrLevel Level
+
+	// Colors is the color theme of the parts and fields rendered by the
+	// default formatters, ConsoleColorsDefault if nil. The formatters set by
+	// the Format fields take precedence over it.
+	Colors *ConsoleColors
+
+	// NoColor disables the colorized output. NewConsoleWriter sets it by
+	// default, before applying the options, when the NO_COLOR environment
+	// variable is set or when os.Stdout, the default Out, is a file which
+	// is not a terminal. So an option setting NoColor always wins, and an
+	// option setting Out to another file should set NoColor as well.
 	NoColor bool
 
 	// TimeFormat specifies the format for timestamp in output.
 	TimeFormat string
 
+	// TimeLocation is the location the timestamps are displayed in, the
+	// local one if nil.
+	TimeLocation *time.Location
+
+	// FormatTime, if set, formats the timestamps instead of TimeFormat. It
+	// gets the parsed time, in TimeLocation, whether it was logged as a
+	// string or as a UNIX time. FormatTimestamp takes precedence over it.
+	FormatTime func(t time.Time) string
+
 	// PartsOrder defines the order of parts in output.
 	PartsOrder []string
 
 	// PartsExclude defines parts to not display in output.
 	PartsExclude []string
 
-	// FieldsExclude defines contextual fields to not display in output.
+	// FieldsExclude defines contextual fields to not display in output. It
+	// also hides the parts with the same name, like "caller".
 	FieldsExclude []string
 
+	// FieldsOrder defines fields to display first, in the given order. The
+	// other fields follow in alphabetical order, the error field first.
+	FieldsOrder []string
+
+	// LevelMarkers overrides the rendering of the level part with a custom
+	// marker per level, e.g. a single glyph. Markers are padded to the same
+	// display width. Levels missing from the map use the default rendering.
+	// The map must not be modified once the writer is in use.
+	LevelMarkers map[Level]string
+
+	// Minimal enables a compact preset: levels are rendered as glyphs (unless
+	// LevelMarkers is set), the timestamp is omitted and the caller is
+	// shortened to its file base name. The default parts order becomes
+	// level, caller and message, followed by the fields.
+	Minimal bool
+
+	// Multiline renders the fields on their own indented line each, under
+	// the line of the parts.
+	Multiline bool
+
+	// Strict makes Write return an error for input which is not an event.
+	// By default, input which doesn't start with '{' and is not a valid
+	// binary event, like the output of a child process or a panic, is
+	// written verbatim.
+	Strict bool
+
+	// VerbatimMarker prefixes the lines of the input written verbatim with a
+	// dimmed "???" level.
+	VerbatimMarker bool
+
+	// FieldsIndent, if greater than 0, renders the fields whose value is an
+	// object or an array, like the ones added with Dict or RawJSON, as JSON
+	// indented with this number of spaces per level, on the lines following
+	// the field name. The other values are rendered inline.
+	FieldsIndent int
+
+	// MessageColumn, if greater than 0, aligns the message of all the lines
+	// by padding the parts before it so that it starts at this column, the
+	// first one being 0, and pads the level part to the width of the longest
+	// level. Parts too long for the column overflow and shift the message.
+	MessageColumn int
+
+	// SingleLineStack keeps the ErrorStackFieldName field on the event line
+	// like the other fields. By default, a stack made of an array of frames
+	// or of a multi-line string is rendered under the event, one frame per
+	// indented line.
+	SingleLineStack bool
+
+	// CallerTrimPrefix, if set, is trimmed from the callers rendered by the
+	// default caller formatter when they start with it, for instance to
+	// render /home/ci/build/src/service/internal/foo/bar.go:42 as
+	// internal/foo/bar.go:42. The separators of the paths are compared
+	// regardless of their style. The other callers are rendered relative to
+	// the working directory. See ConsoleTrimModuleCaller.
+	CallerTrimPrefix string
+
 	FormatTimestamp     Formatter
 	FormatLevel         Formatter
 	FormatCaller        Formatter
@@ -77,42 +178,82 @@ type ConsoleWriter struct {
 	FormatErrFieldName  Formatter
 	FormatErrFieldValue Formatter
 
+	// FormatFieldValueByKey overrides the formatter of the values of the
+	// fields by key, e.g. with ConsoleFormatDuration or ConsoleFormatBytes.
+	// It takes precedence over FormatFieldValue and FormatErrFieldValue.
+	FormatFieldValueByKey map[string]Formatter
+
+	// FormatExtra, if set, is called with the decoded event after the fields
+	// have been rendered and before the final newline, to append extra text
+	// to buf. An error it returns is returned by Write and the line is not
+	// written. The map is reused by the next writes, so it must not be
+	// retained.
 	FormatExtra func(map[string]interface{}, *bytes.Buffer) error
+
+	// outMu and errOutMu serialize the writes to Out and ErrOut, see
+	// NewConsoleWriter.
+	outMu, errOutMu *sync.Mutex
 }
 
 // NewConsoleWriter creates and initializes a new ConsoleWriter.
 func NewConsoleWriter(options ...func(w *ConsoleWriter)) ConsoleWriter {
 	w := ConsoleWriter{
 		Out:        os.Stdout,
+		ErrLevel:   WarnLevel,
 		TimeFormat: consoleDefaultTimeFormat,
 		PartsOrder: consoleDefaultPartsOrder(),
 	}
+	w.NoColor = consoleDefaultNoColor(w.Out)
 
 	for _, opt := range options {
 		opt(&w)
 	}
 
-	// Fix color on Windows
-	if w.Out == os.Stdout || w.Out == os.Stderr {
-		w.Out = colorable.NewColorable(w.Out.(*os.File))
+	w.outMu = consoleOutputLock(w.Out)
+	if w.ErrOut != nil && writerIn(w.ErrOut, []io.Writer{w.Out}) {
+		w.errOutMu = w.outMu
+	} else {
+		w.errOutMu = consoleOutputLock(w.ErrOut)
 	}
+	// Fix color on Windows
+	w.Out, w.ErrOut = consoleColorable(w.Out), consoleColorable(w.ErrOut)
 
 	return w
 }
 
-// Write transforms the JSON input with formatters and appends to w.Out.
+// consoleDefaultNoColor returns true if the colors must be disabled by
+// default on out: if the NO_COLOR environment variable is set or if out is
+// a file which is not a terminal.
+func consoleDefaultNoColor(out io.Writer) bool {
+	return os.Getenv("NO_COLOR") != "" || !consoleIsTerminal(out)
+}
+
+// Write transforms the JSON input with formatters and appends to w.Out, or
+// to w.ErrOut if the level field of the event is ErrLevel or above.
 func (w ConsoleWriter) Write(p []byte) (n int, err error) {
-	// Fix color on Windows
-	if w.Out == os.Stdout || w.Out == os.Stderr {
-		out, ok := w.Out.(*os.File)
-		if !ok {
-			return 0, fmt.Errorf("invalid output")
-		}
-		w.Out = colorable.NewColorable(out)
+	return w.write(p, NoLevel, false)
+}
+
+// WriteLevel implements the LevelWriter interface. It writes like Write,
+// using level instead of the level field of the event to choose between
+// w.Out and w.ErrOut.
+func (w ConsoleWriter) WriteLevel(level Level, p []byte) (n int, err error) {
+	return w.write(p, level, true)
+}
+
+// write writes p to the output of level, read from the event unless
+// hasLevel is true.
+func (w ConsoleWriter) write(p []byte, level Level, hasLevel bool) (n int, err error) {
+	if consoleForceNoColor {
+		w.NoColor = true
 	}
 
 	if w.PartsOrder == nil {
-		w.PartsOrder = consoleDefaultPartsOrder()
+		if w.Minimal {
+			w.PartsOrder = consoleMinimalParts
+		} else {
+			w.PartsOrder = consoleDefaultParts
+		}
 	}
 
 	var buf = consoleBufPool.Get().(*bytes.Buffer)
@@ -121,20 +262,48 @@ func (w ConsoleWriter) Write(p []byte) (n int, err error) {
 		consoleBufPool.Put(buf)
 	}()
 
-	var evt map[string]interface{}
-	p = decodeIfBinaryToBytes(p)
-	d := json.NewDecoder(bytes.NewReader(p))
-	d.UseNumber()
-	err = d.Decode(&evt)
+	if j, ok := decodeBinaryEvent(p); ok {
+		p = j
+	} else if !w.Strict && !consoleIsEvent(p) {
+		return w.writeVerbatim(buf, p, level)
+	}
+
+	evt := consoleEventPool.Get().(map[string]interface{})
+	defer func() {
+		for k := range evt {
+			delete(evt, k)
+		}
+		consoleEventPool.Put(evt)
+	}()
+	err = consoleDecodeEvent(p, evt)
 	if err != nil {
 		return n, fmt.Errorf("cannot decode event: %s", err)
 	}
+	if !hasLevel {
+		level = consoleEventLevel(evt)
+	}
+	consoleMessageKey(evt)
 
 	for _, p := range w.PartsOrder {
 		w.writePart(buf, evt, p)
 	}
 
-	w.writeFields(evt, buf)
+	// The stack is rendered after the fields, so hide it from them while
+	// keeping it for FormatExtra.
+	stack, hasStack := w.stackLines(evt)
+	if hasStack {
+		v := evt[ErrorStackFieldName]
+		delete(evt, ErrorStackFieldName)
+		w.writeFields(evt, buf)
+		evt[ErrorStackFieldName] = v
+	} else {
+		w.writeFields(evt, buf)
+	}
+
+	for _, line := range stack {
+		buf.WriteString("\n  ")
+		buf.WriteString(colorize(line, w.colors().Stack))
+	}
 
 	if w.FormatExtra != nil {
 		err = w.FormatExtra(evt, buf)
@@ -148,13 +317,128 @@ func (w ConsoleWriter) Write(p []byte) (n int, err error) {
 		return n, err
 	}
 
-	_, err = buf.WriteTo(w.Out)
+	out, mu := w.output(level)
+	err = consoleWrite(out, mu, buf.Bytes())
 	return len(p), err
 }
 
+// output returns the destination of the lines of level and the lock
+// serializing the writes to it, if any.
+func (w ConsoleWriter) output(level Level) (io.Writer, *sync.Mutex) {
+	if w.ErrOut != nil && level != NoLevel && level >= w.ErrLevel {
+		if w.errOutMu != nil {
+			return w.ErrOut, w.errOutMu
+		}
+		return w.ErrOut, consoleStdLock(w.ErrOut)
+	}
+	if w.outMu != nil {
+		return w.Out, w.outMu
+	}
+	return w.Out, consoleStdLock(w.Out)
+}
+
+// Close closes Out and ErrOut if they implement io.Closer, once each,
+// unless they are os.Stdout or os.Stderr, and returns their errors joined.
+func (w ConsoleWriter) Close() error {
+	var errs []error
+	var closed []io.Writer
+	for _, out := range []io.Writer{w.Out, w.ErrOut} {
+		if out == nil || writerIn(out, closed) {
+			continue
+		}
+		closed = append(closed, out)
+		if err := closeOutput(out); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync calls the Sync method of Out and ErrOut if they have one, unless
+// they are os.Stdout or os.Stderr, and returns their errors joined.
+func (w ConsoleWriter) Sync() error {
+	var errs []error
+	for _, out := range []io.Writer{w.Out, w.ErrOut} {
+		if out == nil {
+			continue
+		}
+		if err := syncOutput(out); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// consoleStdoutLock and consoleStderrLock serialize the writes of all the
+// ConsoleWriters to os.Stdout and os.Stderr.
+var consoleStdoutLock, consoleStderrLock sync.Mutex
+
+// consoleStdLock returns the lock of out if it is os.Stdout or os.Stderr,
+// or nil.
+func consoleStdLock(out io.Writer) *sync.Mutex {
+	switch out {
+	case os.Stdout:
+		return &consoleStdoutLock
+	case os.Stderr:
+		return &consoleStderrLock
+	}
+	return nil
+}
+
+// consoleOutputLock returns the lock serializing the writes of a
+// ConsoleWriter to out: the shared one of os.Stdout and os.Stderr, or a new
+// one. It returns nil if out is nil.
+func consoleOutputLock(out io.Writer) *sync.Mutex {
+	if out == nil {
+		return nil
+	}
+	if mu := consoleStdLock(out); mu != nil {
+		return mu
+	}
+	return &sync.Mutex{}
+}
+
+// consoleColorable wraps out to render the colors on Windows if it is
+// os.Stdout or os.Stderr.
+func consoleColorable(out io.Writer) io.Writer {
+	if out == os.Stdout || out == os.Stderr {
+		return colorable.NewColorable(out.(*os.File))
+	}
+	return out
+}
+
+// consoleWrite writes p to out with a single call, holding mu if not nil.
+func consoleWrite(out io.Writer, mu *sync.Mutex, p []byte) error {
+	if mu != nil {
+		mu.Lock()
+		defer mu.Unlock()
+	}
+	_, err := out.Write(p)
+	return err
+}
+
+// consoleEventLevel returns the level of the decoded event evt, NoLevel if
+// it has none.
+func consoleEventLevel(evt map[string]interface{}) Level {
+	s, ok := evt[LevelFieldName].(string)
+	if !ok {
+		return NoLevel
+	}
+	l, err := ParseLevel(s)
+	if err != nil {
+		return NoLevel
+	}
+	return l
+}
+
 // writeFields appends formatted key-value pairs to buf.
 func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer) {
-	var fields = make([]string, 0, len(evt))
+	fp := consoleFieldsPool.Get().(*[]string)
+	fields := (*fp)[:0]
+	defer func() {
+		*fp = fields[:0]
+		consoleFieldsPool.Put(fp)
+	}()
 	for field := range evt {
 		var isExcluded bool
 		for _, excluded := range w.FieldsExclude {
@@ -173,84 +457,231 @@ func (w ConsoleWriter) writeFields(evt map[string]interface{}, buf *bytes.Buffer
 		}
 		fields = append(fields, field)
 	}
-	sort.Strings(fields)
+	sortFields(fields)
+
+	sep := " "
+	if w.Multiline {
+		sep = "\n  "
+	}
 
 	// Write space only if something has already been written to the buffer, and if there are fields.
 	if buf.Len() > 0 && len(fields) > 0 {
-		buf.WriteByte(' ')
+		buf.WriteString(sep)
+	} else if w.Multiline && len(fields) > 0 {
+		buf.WriteString("  ")
 	}
 
 	// Move the "error" field to the front
 	ei := sort.Search(len(fields), func(i int) bool { return fields[i] >= ErrorFieldName })
 	if ei < len(fields) && fields[ei] == ErrorFieldName {
-		fields[ei] = ""
-		fields = append([]string{ErrorFieldName}, fields...)
-		var xfields = make([]string, 0, len(fields))
-		for _, field := range fields {
-			if field == "" { // Skip empty fields
-				continue
-			}
-			xfields = append(xfields, field)
-		}
-		fields = xfields
+		copy(fields[1:ei+1], fields[:ei])
+		fields[0] = ErrorFieldName
 	}
 
-	for i, field := range fields {
-		var fn Formatter
-		var fv Formatter
+	ordered := fields
+	if len(w.FieldsOrder) > 0 {
+		ordered = orderFields(fields, w.FieldsOrder)
+	}
 
+	colors := w.colors()
+	for i, field := range ordered {
+		// A nil formatter stands for the default one, which is inlined to
+		// spare the allocation of the formatted strings.
+		fn, fv := w.FormatFieldName, w.FormatFieldValue
+		nameColor, valueColor := colors.FieldName, ""
 		if field == ErrorFieldName {
-			if w.FormatErrFieldName == nil {
-				fn = consoleDefaultFormatErrFieldName(w.NoColor)
-			} else {
-				fn = w.FormatErrFieldName
-			}
+			fn, fv = w.FormatErrFieldName, w.FormatErrFieldValue
+			nameColor, valueColor = colors.ErrFieldName, colors.ErrFieldValue
+		}
+		if f, ok := w.FormatFieldValueByKey[field]; ok {
+			fv = f
+		}
 
-			if w.FormatErrFieldValue == nil {
-				fv = consoleDefaultFormatErrFieldValue(w.NoColor)
-			} else {
-				fv = w.FormatErrFieldValue
-			}
+		if fn != nil {
+			buf.WriteString(fn(field))
 		} else {
-			if w.FormatFieldName == nil {
-				fn = consoleDefaultFormatFieldName(w.NoColor)
-			} else {
-				fn = w.FormatFieldName
-			}
-
-			if w.FormatFieldValue == nil {
-				fv = consoleDefaultFormatFieldValue
-			} else {
-				fv = w.FormatFieldValue
-			}
+			writeColorized(buf, field, "=", nameColor)
 		}
 
-		buf.WriteString(fn(field))
-
-		switch fValue := evt[field].(type) {
+		switch v := evt[field]; fValue := v.(type) {
 		case string:
 			if needsQuote(fValue) {
-				buf.WriteString(fv(strconv.Quote(fValue)))
+				fValue = strconv.Quote(fValue)
+				v = fValue
+			}
+			if fv != nil {
+				buf.WriteString(fv(v))
 			} else {
-				buf.WriteString(fv(fValue))
+				writeColorized(buf, fValue, "", valueColor)
 			}
 		case json.Number:
-			buf.WriteString(fv(fValue))
+			if fv != nil {
+				buf.WriteString(fv(v))
+			} else {
+				writeColorized(buf, string(fValue), "", valueColor)
+			}
 		default:
 			b, err := InterfaceMarshalFunc(fValue)
 			if err != nil {
-				_, _ = fmt.Fprintf(buf, colorize("[error: %v]", colorRed, w.NoColor), err)
+				_, _ = fmt.Fprintf(buf, colorize("[error: %v]", colors.ErrFieldValue), err)
 			} else {
-				_, _ = fmt.Fprint(buf, fv(b))
+				if w.FieldsIndent > 0 {
+					b = w.indentFieldValue(fValue, b, sep[1:])
+				}
+				if fv != nil {
+					buf.WriteString(fv(b))
+				} else {
+					writeColorized(buf, string(b), "", valueColor)
+				}
 			}
 		}
 
-		if i < len(fields)-1 { // Skip space for last field
-			buf.WriteByte(' ')
+		if i < len(ordered)-1 { // Skip space for last field
+			buf.WriteString(sep)
 		}
 	}
 }
 
+// consoleFieldsPool pools the slices writeFields sorts the field names in.
+var consoleFieldsPool = sync.Pool{
+	New: func() interface{} {
+		fields := make([]string, 0, 16)
+		return &fields
+	},
+}
+
+// sortFields sorts fields in increasing order, without allocating for the
+// usual numbers of fields.
+func sortFields(fields []string) {
+	if len(fields) > 16 {
+		sort.Strings(fields)
+		return
+	}
+	for i := 1; i < len(fields); i++ {
+		for j := i; j > 0 && fields[j] < fields[j-1]; j-- {
+			fields[j], fields[j-1] = fields[j-1], fields[j]
+		}
+	}
+}
+
+// consoleIsEvent returns true if p looks like a JSON event. The binary
+// events are detected by decodeBinaryEvent.
+func consoleIsEvent(p []byte) bool {
+	p = bytes.TrimLeft(p, " \t\r\n")
+	return len(p) > 0 && p[0] == '{'
+}
+
+// writeVerbatim writes p, which is not an event, to the output of level as
+// is, or with its lines prefixed by VerbatimMarker.
+func (w ConsoleWriter) writeVerbatim(buf *bytes.Buffer, p []byte, level Level) (n int, err error) {
+	out, mu := w.output(level)
+	if !w.VerbatimMarker {
+		if err = consoleWrite(out, mu, p); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	marker := colorize("???", w.colors().Verbatim) + " "
+	for rest := p; len(rest) > 0; {
+		i := bytes.IndexByte(rest, '\n') + 1
+		if i == 0 {
+			i = len(rest)
+		}
+		buf.WriteString(marker)
+		buf.Write(rest[:i])
+		rest = rest[i:]
+	}
+	if err = consoleWrite(out, mu, buf.Bytes()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// indentFieldValue returns b, the JSON encoding of v, indented with
+// FieldsIndent spaces on lines starting with prefix if v is an object or an
+// array.
+func (w ConsoleWriter) indentFieldValue(v interface{}, b []byte, prefix string) []byte {
+	switch v.(type) {
+	case map[string]interface{}, []interface{}:
+	default:
+		return b
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, prefix, strings.Repeat(" ", w.FieldsIndent)); err != nil {
+		return b
+	}
+	return out.Bytes()
+}
+
+// stackLines returns the lines of the ErrorStackFieldName field of evt, if it
+// is to be rendered under the event.
+func (w ConsoleWriter) stackLines(evt map[string]interface{}) ([]string, bool) {
+	if w.SingleLineStack || containsField(w.FieldsExclude, ErrorStackFieldName) {
+		return nil, false
+	}
+	switch stack := evt[ErrorStackFieldName].(type) {
+	case string:
+		if !strings.Contains(stack, "\n") {
+			return nil, false
+		}
+		return strings.Split(strings.TrimRight(stack, "\n"), "\n"), true
+	case []interface{}:
+		lines := make([]string, 0, len(stack))
+		for _, frame := range stack {
+			lines = append(lines, consoleStackFrame(frame))
+		}
+		return lines, true
+	}
+	return nil, false
+}
+
+// consoleStackFrame formats a frame of a stack, like the ones of
+// pkgerrors.MarshalStack, on a single line.
+func consoleStackFrame(frame interface{}) string {
+	switch frame := frame.(type) {
+	case string:
+		return frame
+	case map[string]interface{}:
+		fn, okFn := frame["func"].(string)
+		source, okSource := frame["source"].(string)
+		line, okLine := frame["line"].(string)
+		if okFn && okSource && okLine && len(frame) == 3 {
+			return fn + " " + source + ":" + line
+		}
+	}
+	b, err := InterfaceMarshalFunc(frame)
+	if err != nil {
+		return fmt.Sprintf("[error: %v]", err)
+	}
+	return string(b)
+}
+
+// orderFields returns fields with the ones listed in order moved to the
+// front, in the given order.
+func orderFields(fields, order []string) []string {
+	res := make([]string, 0, len(fields))
+	for _, o := range order {
+		if containsField(fields, o) && !containsField(res, o) {
+			res = append(res, o)
+		}
+	}
+	for _, field := range fields {
+		if !containsField(res, field) {
+			res = append(res, field)
+		}
+	}
+	return res
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
 // writePart appends a formatted part to buf.
 func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{}, p string) {
 	var f Formatter
@@ -262,31 +693,43 @@ func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{},
 			}
 		}
 	}
+	for _, exclude := range w.FieldsExclude {
+		if exclude == p {
+			return
+		}
+	}
+	if w.Minimal && p == TimestampFieldName {
+		return
+	}
 
 	switch p {
 	case LevelFieldName:
-		if w.FormatLevel == nil {
-			f = consoleDefaultFormatLevel(w.NoColor)
-		} else {
+		if w.FormatLevel != nil {
 			f = w.FormatLevel
+		} else if markers := w.levelMarkers(); markers != nil {
+			f = consoleMarkerFormatLevel(markers, w.colors())
+		} else {
+			f = consoleDefaultFormatLevel(w.colors())
 		}
 	case TimestampFieldName:
 		if w.FormatTimestamp == nil {
-			f = consoleDefaultFormatTimestamp(w.TimeFormat, w.NoColor)
+			f = consoleDefaultFormatTimestamp(w.timeFormatter(), w.colors())
 		} else {
 			f = w.FormatTimestamp
 		}
 	case MessageFieldName:
 		if w.FormatMessage == nil {
-			f = consoleDefaultFormatMessage
+			f = consoleDefaultFormatMessage(w.colors())
 		} else {
 			f = w.FormatMessage
 		}
 	case CallerFieldName:
-		if w.FormatCaller == nil {
-			f = consoleDefaultFormatCaller(w.NoColor)
-		} else {
+		if w.FormatCaller != nil {
 			f = w.FormatCaller
+		} else if w.Minimal {
+			f = consoleMinimalFormatCaller(w.colors())
+		} else {
+			f = consoleDefaultFormatCaller(w.colors(), w.CallerTrimPrefix)
 		}
 	default:
 		if w.FormatFieldValue == nil {
@@ -302,10 +745,90 @@ func (w ConsoleWriter) writePart(buf *bytes.Buffer, evt map[string]interface{},
 		if buf.Len() > 0 {
 			buf.WriteByte(' ') // Write space only if not the first part
 		}
+		if w.MessageColumn > 0 {
+			switch p {
+			case LevelFieldName:
+				s = padRight(s, consoleLevelWidth())
+			case MessageFieldName:
+				for col := consoleVisibleWidth(buf.String()); col < w.MessageColumn; col++ {
+					buf.WriteByte(' ')
+				}
+			}
+		}
 		buf.WriteString(s)
 	}
 }
 
+// consoleMessageKey moves the message key of an event logged with
+// Event.MsgKey to its message when it has no rendered message, and hides it
+// otherwise, the message being the human form of the key.
+func consoleMessageKey(evt map[string]interface{}) {
+	key, ok := evt[MessageKeyFieldName]
+	if !ok {
+		return
+	}
+	delete(evt, MessageKeyFieldName)
+	if _, ok := evt[MessageFieldName]; !ok {
+		evt[MessageFieldName] = key
+	}
+}
+
+// padRight pads s with spaces up to width visible columns.
+func padRight(s string, width int) string {
+	if n := consoleVisibleWidth(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// consoleLevelWidth returns the width of the longest short level name.
+func consoleLevelWidth() int {
+	width := 3
+	consoleLevelsMu.RLock()
+	defer consoleLevelsMu.RUnlock()
+	for _, cl := range consoleLevels {
+		if n := displayWidth(cl.short); n > width {
+			width = n
+		}
+	}
+	return width
+}
+
+// consoleVisibleWidth returns the display width of s without its ANSI
+// escape sequences.
+func consoleVisibleWidth(s string) int {
+	if !strings.Contains(s, "\x1b[") {
+		return displayWidth(s)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "\x1b[")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i])
+		s = s[i+2:]
+		if j := strings.IndexFunc(s, func(r rune) bool { return r >= 0x40 && r <= 0x7e }); j >= 0 {
+			s = s[j+1:]
+		} else {
+			s = ""
+		}
+	}
+	return displayWidth(b.String())
+}
+
+// levelMarkers returns the level markers in use, if any.
+func (w ConsoleWriter) levelMarkers() map[Level]string {
+	if w.LevelMarkers != nil {
+		return w.LevelMarkers
+	}
+	if w.Minimal {
+		return consoleDefaultLevelMarkers
+	}
+	return nil
+}
+
 // needsQuote returns true when the string s should be quoted in output.
 func needsQuote(s string) bool {
 	for i := range s {
@@ -316,16 +839,211 @@ func needsQuote(s string) bool {
 	return false
 }
 
-// colorize returns the string s wrapped in ANSI code c, unless disabled is true.
-func colorize(s interface{}, c int, disabled bool) string {
-	if disabled {
-		return fmt.Sprintf("%s", s)
+// colorize returns the string s wrapped in the SGR parameters sgr, unless sgr
+// is empty.
+func colorize(s interface{}, sgr string) string {
+	if sgr == "" {
+		return consoleString(s)
+	}
+	if ss, ok := s.(string); ok {
+		return "\x1b[" + sgr + "m" + ss + "\x1b[0m"
+	}
+	return fmt.Sprintf("\x1b[%sm%v\x1b[0m", sgr, s)
+}
+
+// consoleString returns i formatted with the %s verb.
+func consoleString(i interface{}) string {
+	switch s := i.(type) {
+	case string:
+		return s
+	case json.Number:
+		return string(s)
+	case []byte:
+		return string(s)
+	}
+	return fmt.Sprintf("%s", i)
+}
+
+// sgr returns the SGR parameters made of codes.
+func sgr(codes ...int) string {
+	s := make([]string, len(codes))
+	for i, c := range codes {
+		s[i] = strconv.Itoa(c)
+	}
+	return strings.Join(s, ";")
+}
+
+// ConsoleColors is a color theme of ConsoleWriter. Each color is a sequence
+// of SGR parameters, without the leading escape and the final 'm': "31" for
+// red, "1;31" for bold red, "38;5;208" for orange with 256 colors or
+// "38;2;255;135;0" in truecolor. An empty color leaves the text uncolored.
+type ConsoleColors struct {
+	// Levels are the colors of the levels. A level missing from the map has
+	// the color it was registered with by RegisterConsoleLevel, or is bold.
+	Levels map[Level]string
+
+	Timestamp     string
+	Caller        string
+	CallerMarker  string // the '>' following the caller
+	Message       string
+	FieldName     string
+	ErrFieldName  string
+	ErrFieldValue string
+	Stack         string // the lines of a stack rendered under the event
+	Verbatim      string // the marker of the input which is not an event
+
+	disabled bool
+}
+
+// consoleNoColors is the theme of the writers with NoColor set.
+var consoleNoColors = &ConsoleColors{disabled: true}
+
+// consoleDefaultColors is the theme of the writers with no Colors set.
+var consoleDefaultColors = ConsoleColorsDefault()
+
+// ConsoleColorsDefault returns the default theme of ConsoleWriter, for dark
+// backgrounds, using the 8 standard colors.
+func ConsoleColorsDefault() *ConsoleColors {
+	return &ConsoleColors{
+		Levels: map[Level]string{
+			TraceLevel: sgr(colorMagenta),
+			DebugLevel: sgr(colorYellow),
+			InfoLevel:  sgr(colorGreen),
+			WarnLevel:  sgr(colorRed),
+			ErrorLevel: sgr(colorBold, colorRed),
+			FatalLevel: sgr(colorBold, colorRed),
+			PanicLevel: sgr(colorBold, colorRed),
+		},
+		Timestamp:     sgr(colorDarkGray),
+		Caller:        sgr(colorBold),
+		CallerMarker:  sgr(colorCyan),
+		FieldName:     sgr(colorCyan),
+		ErrFieldName:  sgr(colorCyan),
+		ErrFieldValue: sgr(colorRed),
+		Stack:         sgr(colorDarkGray),
+		Verbatim:      sgr(colorDarkGray),
+	}
+}
+
+// ConsoleColorsLight returns a theme for light backgrounds, using 256 colors
+// to avoid the yellows and light grays hardly readable on them.
+func ConsoleColorsLight() *ConsoleColors {
+	const (
+		orange   = "38;5;166"
+		darkGray = "38;5;242"
+	)
+	return &ConsoleColors{
+		Levels: map[Level]string{
+			TraceLevel: sgr(colorMagenta),
+			DebugLevel: sgr(colorBlue),
+			InfoLevel:  sgr(colorGreen),
+			WarnLevel:  orange,
+			ErrorLevel: sgr(colorBold, colorRed),
+			FatalLevel: sgr(colorBold, colorRed),
+			PanicLevel: sgr(colorBold, colorRed),
+		},
+		Timestamp:     darkGray,
+		Caller:        sgr(colorBold),
+		CallerMarker:  sgr(colorBlue),
+		FieldName:     sgr(colorBlue),
+		ErrFieldName:  sgr(colorBlue),
+		ErrFieldValue: sgr(colorRed),
+		Stack:         darkGray,
+		Verbatim:      darkGray,
 	}
-	return fmt.Sprintf("\x1b[%dm%v\x1b[0m", c, s)
+}
+
+// level returns the color of the level l.
+func (c *ConsoleColors) level(l Level) string {
+	if c.disabled {
+		return ""
+	}
+	if s, ok := c.Levels[l]; ok {
+		return s
+	}
+	if cl, ok := registeredConsoleLevel(l); ok {
+		return sgr(cl.color)
+	}
+	return sgr(colorBold)
+}
+
+// colors returns the theme of w.
+func (w ConsoleWriter) colors() *ConsoleColors {
+	switch {
+	case w.NoColor:
+		return consoleNoColors
+	case w.Colors != nil:
+		return w.Colors
+	}
+	return consoleDefaultColors
+}
+
+type consoleLevel struct {
+	short string
+	color int
+}
+
+var (
+	consoleLevelsMu sync.RWMutex
+	consoleLevels   = map[Level]consoleLevel{}
+)
+
+// RegisterConsoleLevel sets how the default ConsoleWriter level formatter
+// renders the level l registered with RegisterLevel: as short, colorized with
+// the ANSI SGR code color (e.g. 36 for cyan).
+func RegisterConsoleLevel(l Level, short string, color int) {
+	consoleLevelsMu.Lock()
+	defer consoleLevelsMu.Unlock()
+	consoleLevels[l] = consoleLevel{short: short, color: color}
+}
+
+func registeredConsoleLevel(l Level) (consoleLevel, bool) {
+	consoleLevelsMu.RLock()
+	defer consoleLevelsMu.RUnlock()
+	cl, ok := consoleLevels[l]
+	return cl, ok
+}
+
+// displayWidth returns the number of terminal columns needed to display s.
+// Wide runes (CJK, emoji) count as two columns, combining marks, variation
+// selectors and zero width joiners as none.
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		switch {
+		case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		case isWideRune(r):
+			n += 2
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// isWideRune reports whether r is displayed on two terminal columns.
+func isWideRune(r rune) bool {
+	return r >= 0x1100 && (r <= 0x115f || // Hangul Jamo
+		(r >= 0x2e80 && r <= 0xa4cf && r != 0x303f) || // CJK ... Yi
+		(r >= 0xac00 && r <= 0xd7a3) || // Hangul Syllables
+		(r >= 0xf900 && r <= 0xfaff) || // CJK Compatibility Ideographs
+		(r >= 0xfe30 && r <= 0xfe4f) || // CJK Compatibility Forms
+		(r >= 0xff00 && r <= 0xff60) || // Fullwidth Forms
+		(r >= 0xffe0 && r <= 0xffe6) ||
+		(r >= 0x1f300 && r <= 0x1f64f) || // Pictographs and Emoticons
+		(r >= 0x1f900 && r <= 0x1f9ff) || // Supplemental Symbols and Pictographs
+		(r >= 0x20000 && r <= 0x3fffd))
 }
 
 // ----- DEFAULT FORMATTERS ---------------------------------------------------
 
+// consoleDefaultParts and consoleMinimalParts are the parts orders of the
+// writers with no PartsOrder set. They must not be modified.
+var (
+	consoleDefaultParts = consoleDefaultPartsOrder()
+	consoleMinimalParts = consoleMinimalPartsOrder()
+)
+
 func consoleDefaultPartsOrder() []string {
 	return []string{
 		TimestampFieldName,
@@ -335,10 +1053,61 @@ func consoleDefaultPartsOrder() []string {
 	}
 }
 
-func consoleDefaultFormatTimestamp(timeFormat string, noColor bool) Formatter {
+func consoleMinimalPartsOrder() []string {
+	return []string{
+		LevelFieldName,
+		CallerFieldName,
+		MessageFieldName,
+	}
+}
+
+var consoleDefaultLevelMarkers = map[Level]string{
+	TraceLevel: "·",
+	DebugLevel: "◦",
+	InfoLevel:  "•",
+	WarnLevel:  "⚠",
+	ErrorLevel: "✗",
+	FatalLevel: "☠",
+	PanicLevel: "‼",
+}
+
+// consoleMarkerPadding returns the padding of each marker of markers to the
+// display width of the widest one.
+func consoleMarkerPadding(markers map[Level]string) map[Level]string {
+	width := 0
+	for _, m := range markers {
+		if n := displayWidth(m); n > width {
+			width = n
+		}
+	}
+	pads := make(map[Level]string, len(markers))
+	for l, m := range markers {
+		pads[l] = strings.Repeat(" ", width-displayWidth(m))
+	}
+	return pads
+}
+
+// timeFormatter returns the function formatting the parsed timestamps.
+func (w ConsoleWriter) timeFormatter() func(t time.Time) string {
+	loc := w.TimeLocation
+	if loc == nil {
+		loc = time.Local
+	}
+	if w.FormatTime != nil {
+		return func(t time.Time) string {
+			return w.FormatTime(t.In(loc))
+		}
+	}
+	timeFormat := w.TimeFormat
 	if timeFormat == "" {
 		timeFormat = consoleDefaultTimeFormat
 	}
+	return func(t time.Time) string {
+		return consoleFormatTime(t.In(loc), timeFormat)
+	}
+}
+
+func consoleDefaultFormatTimestamp(format func(t time.Time) string, colors *ConsoleColors) Formatter {
 	return func(i interface{}) string {
 		t := "<nil>"
 		switch tt := i.(type) {
@@ -347,7 +1116,7 @@ func consoleDefaultFormatTimestamp(timeFormat string, noColor bool) Formatter {
 			if err != nil {
 				t = tt
 			} else {
-				t = ts.Local().Format(timeFormat)
+				t = format(ts)
 			}
 		case json.Number:
 			i, err := tt.Int64()
@@ -367,39 +1136,56 @@ func consoleDefaultFormatTimestamp(timeFormat string, noColor bool) Formatter {
 					sec, nsec = i, 0
 				}
 
-				ts := time.Unix(sec, nsec)
-				t = ts.Format(timeFormat)
+				t = format(time.Unix(sec, nsec))
 			}
 		}
-		return colorize(t, colorDarkGray, noColor)
+		return colorize(t, colors.Timestamp)
+	}
+}
+
+// consoleFormatTime formats t with timeFormat, or as the duration elapsed
+// since ReferenceTime for ConsoleTimeFormatElapsed.
+func consoleFormatTime(t time.Time, timeFormat string) string {
+	if timeFormat != ConsoleTimeFormatElapsed {
+		return t.Format(timeFormat)
+	}
+	elapsed := t.Sub(ReferenceTime())
+	if elapsed >= 0 {
+		return "+" + elapsed.String()
 	}
+	return elapsed.String()
 }
 
-func consoleDefaultFormatLevel(noColor bool) Formatter {
+func consoleDefaultFormatLevel(colors *ConsoleColors) Formatter {
 	return func(i interface{}) string {
 		var l string
 		if ll, ok := i.(string); ok {
 			switch ll {
 			case LevelTraceValue:
-				l = colorize("TRC", colorMagenta, noColor)
+				l = colorize("TRC", colors.level(TraceLevel))
 			case LevelDebugValue:
-				l = colorize("DBG", colorYellow, noColor)
+				l = colorize("DBG", colors.level(DebugLevel))
 			case LevelInfoValue:
-				l = colorize("INF", colorGreen, noColor)
+				l = colorize("INF", colors.level(InfoLevel))
 			case LevelWarnValue:
-				l = colorize("WRN", colorRed, noColor)
+				l = colorize("WRN", colors.level(WarnLevel))
 			case LevelErrorValue:
-				l = colorize(colorize("ERR", colorRed, noColor), colorBold, noColor)
+				l = colorize("ERR", colors.level(ErrorLevel))
 			case LevelFatalValue:
-				l = colorize(colorize("FTL", colorRed, noColor), colorBold, noColor)
+				l = colorize("FTL", colors.level(FatalLevel))
 			case LevelPanicValue:
-				l = colorize(colorize("PNC", colorRed, noColor), colorBold, noColor)
+				l = colorize("PNC", colors.level(PanicLevel))
 			default:
-				l = colorize(ll, colorBold, noColor)
+				l = colorize(ll, colors.level(NoLevel))
+				if lvl, ok := registeredLevelByName(ll); ok {
+					if cl, ok := registeredConsoleLevel(lvl); ok {
+						l = colorize(cl.short, colors.level(lvl))
+					}
+				}
 			}
 		} else {
 			if i == nil {
-				l = colorize("???", colorBold, noColor)
+				l = colorize("???", colors.level(NoLevel))
 			} else {
 				l = strings.ToUpper(fmt.Sprintf("%s", i))[0:3]
 			}
@@ -408,49 +1194,230 @@ func consoleDefaultFormatLevel(noColor bool) Formatter {
 	}
 }
 
-func consoleDefaultFormatCaller(noColor bool) Formatter {
+func consoleMarkerFormatLevel(markers map[Level]string, colors *ConsoleColors) Formatter {
+	pads := consoleMarkerPadding(markers)
+	fallback := consoleDefaultFormatLevel(colors)
+	return func(i interface{}) string {
+		ll, ok := i.(string)
+		if !ok {
+			return fallback(i)
+		}
+		lvl, err := ParseLevel(ll)
+		if err != nil {
+			return fallback(i)
+		}
+		m, ok := markers[lvl]
+		if !ok {
+			return fallback(i)
+		}
+		return colorize(m, colors.level(lvl)) + pads[lvl]
+	}
+}
+
+func consoleMinimalFormatCaller(colors *ConsoleColors) Formatter {
+	return func(i interface{}) string {
+		var c string
+		if cc, ok := i.(string); ok {
+			c = cc
+		}
+		if len(c) > 0 {
+			c = colorize(filepath.Base(c), colors.Caller) + colorize(" >", colors.CallerMarker)
+		}
+		return c
+	}
+}
+
+func consoleDefaultFormatCaller(colors *ConsoleColors, trimPrefix string) Formatter {
 	return func(i interface{}) string {
 		var c string
 		if cc, ok := i.(string); ok {
 			c = cc
 		}
 		if len(c) > 0 {
-			if cwd, err := os.Getwd(); err == nil {
+			if rel, ok := trimCallerPrefix(c, trimPrefix); ok {
+				c = rel
+			} else if cwd, err := os.Getwd(); err == nil {
 				if rel, err := filepath.Rel(cwd, c); err == nil {
 					c = rel
 				}
 			}
-			c = colorize(c, colorBold, noColor) + colorize(" >", colorCyan, noColor)
+			c = colorize(c, colors.Caller) + colorize(" >", colors.CallerMarker)
 		}
 		return c
 	}
 }
 
-func consoleDefaultFormatMessage(i interface{}) string {
-	if i == nil {
+// trimCallerPrefix returns caller without prefix if prefix is one of its
+// parent directories, comparing the slashes and the backslashes as the same
+// separator.
+func trimCallerPrefix(caller, prefix string) (string, bool) {
+	if prefix == "" || len(caller) <= len(prefix) {
+		return caller, false
+	}
+	for i := 0; i < len(prefix); i++ {
+		a, b := caller[i], prefix[i]
+		if a != b && !(isPathSeparator(a) && isPathSeparator(b)) {
+			return caller, false
+		}
+	}
+	rest := caller[len(prefix):]
+	if !isPathSeparator(prefix[len(prefix)-1]) {
+		if !isPathSeparator(rest[0]) {
+			return caller, false
+		}
+		rest = rest[1:]
+	}
+	return rest, rest != ""
+}
+
+func isPathSeparator(c byte) bool {
+	return c == '/' || c == '\\'
+}
+
+// ConsoleTrimModuleCaller is an option of NewConsoleWriter setting
+// CallerTrimPrefix to the root directory of the main module, so that the
+// callers are rendered relative to it:
+//
+//	w := zerolog.NewConsoleWriter(zerolog.ConsoleTrimModuleCaller)
+//
+// The directory is detected with the build information of the binary and
+// the source file of the main package, so the option must be applied from a
+// call stack of the main package, like from main or an init function. With
+// -trimpath, the callers start with the module path, which is used instead.
+// CallerTrimPrefix is left as is if the module is unknown.
+func ConsoleTrimModuleCaller(w *ConsoleWriter) {
+	if root := consoleModuleRoot(); root != "" {
+		w.CallerTrimPrefix = root
+	}
+}
+
+// consoleModuleRoot returns the root directory of the main module as it
+// appears in the callers, or "" if it is unknown.
+func consoleModuleRoot() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok || info.Main.Path == "" || !strings.HasPrefix(info.Path, info.Main.Path) {
 		return ""
 	}
-	return fmt.Sprintf("%s", i)
+	// The directory of the main package, relative to the module root.
+	pkgDir := info.Path[len(info.Main.Path):]
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "main.") {
+			dir := path.Dir(filepath.ToSlash(frame.File))
+			if strings.HasSuffix(dir, pkgDir) {
+				return filepath.FromSlash(dir[:len(dir)-len(pkgDir)])
+			}
+			break
+		}
+		if !more {
+			break
+		}
+	}
+	return info.Main.Path
+}
+
+func consoleDefaultFormatMessage(colors *ConsoleColors) Formatter {
+	return func(i interface{}) string {
+		if i == nil {
+			return ""
+		}
+		return colorize(i, colors.Message)
+	}
 }
 
-func consoleDefaultFormatFieldName(noColor bool) Formatter {
+func consoleDefaultFormatFieldName(colors *ConsoleColors) Formatter {
 	return func(i interface{}) string {
-		return colorize(fmt.Sprintf("%s=", i), colorCyan, noColor)
+		return colorizeFieldName(i, colors.FieldName)
 	}
 }
 
+// writeColorized writes s followed by suffix to buf, wrapped in the SGR
+// parameters sgr unless sgr is empty, like colorize(s+suffix, sgr).
+func writeColorized(buf *bytes.Buffer, s, suffix, sgr string) {
+	if sgr != "" {
+		buf.WriteString("\x1b[")
+		buf.WriteString(sgr)
+		buf.WriteByte('m')
+	}
+	buf.WriteString(s)
+	buf.WriteString(suffix)
+	if sgr != "" {
+		buf.WriteString("\x1b[0m")
+	}
+}
+
+// colorizeFieldName returns the field name i followed by '=', wrapped in the
+// SGR parameters sgr unless sgr is empty.
+func colorizeFieldName(i interface{}, sgr string) string {
+	if sgr == "" {
+		return consoleString(i) + "="
+	}
+	return "\x1b[" + sgr + "m" + consoleString(i) + "=\x1b[0m"
+}
+
 func consoleDefaultFormatFieldValue(i interface{}) string {
-	return fmt.Sprintf("%s", i)
+	return consoleString(i)
+}
+
+// ConsoleFormatDuration is a Formatter rendering the durations logged by Dur,
+// in DurationFieldUnit, rounded to two significant digits, like "1.2s" or
+// "430ms". Other values are rendered as is.
+func ConsoleFormatDuration(i interface{}) string {
+	n, ok := i.(json.Number)
+	if !ok {
+		return consoleDefaultFormatFieldValue(i)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return consoleDefaultFormatFieldValue(i)
+	}
+	d := time.Duration(math.Round(f * float64(DurationFieldUnit)))
+	abs := d
+	if abs < 0 {
+		abs = -abs
+	}
+	precision := time.Duration(1)
+	for abs >= 100*precision {
+		precision *= 10
+	}
+	return d.Round(precision).String()
+}
+
+// ConsoleFormatBytes is a Formatter rendering sizes in bytes with binary
+// prefixes and one decimal, like "512B", "1.4MiB" or "2GiB". Other values
+// are rendered as is.
+func ConsoleFormatBytes(i interface{}) string {
+	n, ok := i.(json.Number)
+	if !ok {
+		return consoleDefaultFormatFieldValue(i)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return consoleDefaultFormatFieldValue(i)
+	}
+	const units = "KMGTPE"
+	unit := -1
+	for (f >= 1024 || f <= -1024) && unit < len(units)-1 {
+		f /= 1024
+		unit++
+	}
+	if unit < 0 {
+		return strconv.FormatFloat(f, 'f', -1, 64) + "B"
+	}
+	s := strings.TrimSuffix(strconv.FormatFloat(f, 'f', 1, 64), ".0")
+	return s + units[unit:unit+1] + "iB"
 }
 
-func consoleDefaultFormatErrFieldName(noColor bool) Formatter {
+func consoleDefaultFormatErrFieldName(colors *ConsoleColors) Formatter {
 	return func(i interface{}) string {
-		return colorize(fmt.Sprintf("%s=", i), colorCyan, noColor)
+		return colorizeFieldName(i, colors.ErrFieldName)
 	}
 }
 
-func consoleDefaultFormatErrFieldValue(noColor bool) Formatter {
+func consoleDefaultFormatErrFieldValue(colors *ConsoleColors) Formatter {
 	return func(i interface{}) string {
-		return colorize(fmt.Sprintf("%s", i), colorRed, noColor)
+		return colorize(consoleString(i), colors.ErrFieldValue)
 	}
 }
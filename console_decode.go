@@ -0,0 +1,225 @@
+package zerolog
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+)
+
+// consoleEventPool pools the maps ConsoleWriter decodes the events into.
+var consoleEventPool = sync.Pool{
+	New: func() interface{} {
+		return make(map[string]interface{}, 16)
+	},
+}
+
+// consoleDecoder decodes an event like a json.Decoder using numbers would,
+// in a single pass. The strings without escapes share the memory of a
+// single copy of the input.
+type consoleDecoder struct {
+	s     string
+	i     int
+	depth int
+}
+
+// consoleDecodeEvent decodes the JSON object at the start of p into evt.
+// The data following the object is ignored.
+func consoleDecodeEvent(p []byte, evt map[string]interface{}) error {
+	d := consoleDecoder{s: string(p)}
+	d.skipSpace()
+	if !d.consume('{') {
+		return d.syntaxError("looking for beginning of object")
+	}
+	return d.object(evt)
+}
+
+func (d *consoleDecoder) skipSpace() {
+	for d.i < len(d.s) {
+		switch d.s[d.i] {
+		case ' ', '\t', '\n', '\r':
+			d.i++
+		default:
+			return
+		}
+	}
+}
+
+// consume skips the spaces and c if it is the next byte, returning true,
+// or returns false.
+func (d *consoleDecoder) consume(c byte) bool {
+	d.skipSpace()
+	if d.i < len(d.s) && d.s[d.i] == c {
+		d.i++
+		return true
+	}
+	return false
+}
+
+func (d *consoleDecoder) syntaxError(context string) error {
+	if d.i >= len(d.s) {
+		return fmt.Errorf("unexpected end of JSON input %s", context)
+	}
+	return fmt.Errorf("invalid character %q %s at offset %d", d.s[d.i], context, d.i)
+}
+
+// object decodes the fields of an object, following its '{', into m.
+func (d *consoleDecoder) object(m map[string]interface{}) error {
+	if d.consume('}') {
+		return nil
+	}
+	for {
+		d.skipSpace()
+		if d.i >= len(d.s) || d.s[d.i] != '"' {
+			return d.syntaxError("looking for beginning of object key string")
+		}
+		key, err := d.string()
+		if err != nil {
+			return err
+		}
+		if !d.consume(':') {
+			return d.syntaxError("after object key")
+		}
+		v, err := d.value()
+		if err != nil {
+			return err
+		}
+		m[key] = v
+		if d.consume('}') {
+			return nil
+		}
+		if !d.consume(',') {
+			return d.syntaxError("after object key:value pair")
+		}
+	}
+}
+
+// array decodes the elements of an array, following its '['.
+func (d *consoleDecoder) array() ([]interface{}, error) {
+	a := []interface{}{}
+	if d.consume(']') {
+		return a, nil
+	}
+	for {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+		if d.consume(']') {
+			return a, nil
+		}
+		if !d.consume(',') {
+			return nil, d.syntaxError("after array element")
+		}
+	}
+}
+
+func (d *consoleDecoder) value() (interface{}, error) {
+	d.skipSpace()
+	if d.i >= len(d.s) {
+		return nil, d.syntaxError("looking for beginning of value")
+	}
+	switch c := d.s[d.i]; {
+	case c == '"':
+		return d.string()
+	case c == '{' || c == '[':
+		if d.depth++; d.depth > 10000 {
+			return nil, fmt.Errorf("exceeded max depth at offset %d", d.i)
+		}
+		defer func() { d.depth-- }()
+		d.i++
+		if c == '[' {
+			return d.array()
+		}
+		m := map[string]interface{}{}
+		return m, d.object(m)
+	case c == '-' || c >= '0' && c <= '9':
+		return d.number()
+	}
+	for _, lit := range [...]struct {
+		s string
+		v interface{}
+	}{{"true", true}, {"false", false}, {"null", nil}} {
+		if len(d.s)-d.i >= len(lit.s) && d.s[d.i:d.i+len(lit.s)] == lit.s {
+			d.i += len(lit.s)
+			return lit.v, nil
+		}
+	}
+	return nil, d.syntaxError("looking for beginning of value")
+}
+
+// string decodes the string starting at the current '"'.
+func (d *consoleDecoder) string() (string, error) {
+	start := d.i
+	simple := true
+	ascii := true
+	for d.i++; d.i < len(d.s); d.i++ {
+		switch c := d.s[d.i]; {
+		case c == '"':
+			d.i++
+			s := d.s[start+1 : d.i-1]
+			if simple && (ascii || utf8.ValidString(s)) {
+				return s, nil
+			}
+			return consoleUnquote(d.s[start:d.i])
+		case c == '\\':
+			simple = false
+			d.i++
+		case c < 0x20:
+			return "", d.syntaxError("in string literal")
+		case c >= utf8.RuneSelf:
+			ascii = false
+		}
+	}
+	return "", d.syntaxError("in string literal")
+}
+
+// consoleUnquote decodes the string literal s, leaving the escapes and the
+// invalid UTF-8 sequences, replaced by U+FFFD, to the standard JSON decoder.
+func consoleUnquote(s string) (string, error) {
+	var res string
+	err := stdjson.Unmarshal([]byte(s), &res)
+	return res, err
+}
+
+// number decodes the number starting at the current byte.
+func (d *consoleDecoder) number() (json.Number, error) {
+	start := d.i
+	if d.s[d.i] == '-' {
+		d.i++
+	}
+	if d.i < len(d.s) && d.s[d.i] == '0' {
+		d.i++
+	} else if !d.digits() {
+		return "", d.syntaxError("in numeric literal")
+	}
+	if d.i < len(d.s) && d.s[d.i] == '.' {
+		d.i++
+		if !d.digits() {
+			return "", d.syntaxError("after decimal point in numeric literal")
+		}
+	}
+	if d.i < len(d.s) && (d.s[d.i] == 'e' || d.s[d.i] == 'E') {
+		d.i++
+		if d.i < len(d.s) && (d.s[d.i] == '+' || d.s[d.i] == '-') {
+			d.i++
+		}
+		if !d.digits() {
+			return "", d.syntaxError("in exponent of numeric literal")
+		}
+	}
+	return json.Number(d.s[start:d.i]), nil
+}
+
+// digits skips the digits at the current position, returning false if
+// there is none.
+func (d *consoleDecoder) digits() bool {
+	start := d.i
+	for d.i < len(d.s) && d.s[d.i] >= '0' && d.s[d.i] <= '9' {
+		d.i++
+	}
+	return d.i > start
+}
@@ -0,0 +1,75 @@
+package zerolog
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestConsoleDecodeEvent(t *testing.T) {
+	for _, in := range []string{
+		`{}`,
+		` { "level" : "info" , "message" : "hello" } `,
+		`{"message":"café \"quoted\" \\ \n tab\t","emoji":"😀","raw":"é"}`,
+		`{"n":0,"neg":-12,"float":1.5,"exp":-2.5e-3,"big":12345678901234567890,"E":1E+2}`,
+		`{"t":true,"f":false,"null":null}`,
+		`{"dict":{"a":[1,"b",{"c":null}],"empty":{}},"arr":[],"nested":[[[]]]}`,
+		`{"dup":1,"dup":2}`,
+		`{"a":1}` + "\n" + `{"b":2}`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			var want map[string]interface{}
+			d := json.NewDecoder(bytes.NewReader([]byte(in)))
+			d.UseNumber()
+			if err := d.Decode(&want); err != nil {
+				t.Fatalf("json.Decode() error = %v", err)
+			}
+			got := map[string]interface{}{}
+			if err := consoleDecodeEvent([]byte(in), got); err != nil {
+				t.Fatalf("consoleDecodeEvent() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("consoleDecodeEvent() = %#v, want %#v", got, want)
+			}
+		})
+	}
+}
+
+func TestConsoleDecodeEventInvalidUTF8(t *testing.T) {
+	got := map[string]interface{}{}
+	if err := consoleDecodeEvent([]byte("{\"invalid\":\"a\xffb\"}"), got); err != nil {
+		t.Fatalf("consoleDecodeEvent() error = %v", err)
+	}
+	if want := "a\ufffdb"; got["invalid"] != want {
+		t.Errorf("consoleDecodeEvent() = %q, want %q", got["invalid"], want)
+	}
+}
+
+func TestConsoleDecodeEventErrors(t *testing.T) {
+	for _, in := range []string{
+		``,
+		`[]`,
+		`{`,
+		`{"a"}`,
+		`{"a":}`,
+		`{"a":1,}`,
+		`{"a":1 "b":2}`,
+		`{"a":"unterminated}`,
+		`{"a":01}`,
+		`{"a":1.}`,
+		`{"a":-}`,
+		`{"a":1e}`,
+		`{"a":tru}`,
+		`{"a":[1,]}`,
+		`{a:1}`,
+		`{"control":"a` + "\x01" + `b"}`,
+	} {
+		t.Run(in, func(t *testing.T) {
+			if err := consoleDecodeEvent([]byte(in), map[string]interface{}{}); err == nil {
+				t.Errorf("consoleDecodeEvent(%q) error = nil", in)
+			}
+		})
+	}
+}
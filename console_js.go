@@ -0,0 +1,15 @@
+//go:build js
+
+package zerolog
+
+import "io"
+
+// consoleForceNoColor disables the colors of all the console writers: the
+// outputs of js programs, like the browser console, don't render ANSI
+// escapes.
+const consoleForceNoColor = true
+
+// consoleIsTerminal always returns false as there are no terminals in js.
+func consoleIsTerminal(out io.Writer) bool {
+	return false
+}
@@ -1,11 +1,17 @@
+//go:build !js
+
 package zerolog_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -57,6 +63,20 @@ func ExampleNewConsoleWriter_customFormatters() {
 	// Output: <nil> [INFO ] Hello World foo=bar
 }
 
+func ExampleConsoleWriter_formatExtra() {
+	out := zerolog.ConsoleWriter{Out: os.Stdout, NoColor: true, PartsOrder: []string{"level", "message"}}
+	out.FormatExtra = func(evt map[string]interface{}, buf *bytes.Buffer) error {
+		if id, ok := evt["trace_id"].(string); ok {
+			buf.WriteString(" ➜ https://traces/" + id)
+		}
+		return nil
+	}
+	log := zerolog.New(out)
+
+	log.Info().Str("trace_id", "4bf92f35").Msg("Hello World")
+	// Output: INF Hello World trace_id=4bf92f35 ➜ https://traces/4bf92f35
+}
+
 func TestConsoleLogger(t *testing.T) {
 	t.Run("Numbers", func(t *testing.T) {
 		buf := &bytes.Buffer{}
@@ -377,6 +397,54 @@ func TestConsoleWriterConfiguration(t *testing.T) {
 		}
 	})
 
+	t.Run("Sets FieldsExclude on parts", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsExclude: []string{"time"}, FieldsExclude: []string{"caller", "foo"}}
+
+		evt := `{"level": "info", "caller": "file.go:42", "message": "Foobar", "foo":"bar", "baz":"quux"}`
+		_, err := w.Write([]byte(evt))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "INF Foobar baz=quux\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Sets FieldsOrder", func(t *testing.T) {
+		for _, tt := range []struct {
+			name    string
+			order   []string
+			exclude []string
+			want    string
+		}{
+			{"none", nil, nil, "INF Foobar error=boom a=1 b=2 c=3\n"},
+			{"order", []string{"c", "a"}, nil, "INF Foobar c=3 a=1 error=boom b=2\n"},
+			{"order error", []string{"b", "error"}, nil, "INF Foobar b=2 error=boom a=1 c=3\n"},
+			{"order missing", []string{"d", "b", "b"}, nil, "INF Foobar b=2 error=boom a=1 c=3\n"},
+			{"order excluded", []string{"c", "a"}, []string{"c"}, "INF Foobar a=1 error=boom b=2\n"},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				buf := &bytes.Buffer{}
+				w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsExclude: []string{"time"}, FieldsOrder: tt.order, FieldsExclude: tt.exclude}
+
+				evt := `{"level": "info", "message": "Foobar", "b": 2, "c": 3, "a": 1, "error": "boom"}`
+				_, err := w.Write([]byte(evt))
+				if err != nil {
+					t.Errorf("Unexpected error when writing output: %s", err)
+				}
+
+				actualOutput := buf.String()
+				if actualOutput != tt.want {
+					t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+				}
+			})
+		}
+	})
+
 	t.Run("Sets FormatExtra", func(t *testing.T) {
 		buf := &bytes.Buffer{}
 		w := zerolog.ConsoleWriter{
@@ -400,6 +468,25 @@ func TestConsoleWriterConfiguration(t *testing.T) {
 		}
 	})
 
+	t.Run("Returns FormatExtra error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		extraErr := fmt.Errorf("extra error")
+		w := zerolog.ConsoleWriter{
+			Out: buf, NoColor: true,
+			FormatExtra: func(evt map[string]interface{}, buf *bytes.Buffer) error {
+				return extraErr
+			},
+		}
+
+		_, err := w.Write([]byte(`{"level": "info", "message": "Foobar"}`))
+		if err != extraErr {
+			t.Errorf("Unexpected error %v, want: %v", err, extraErr)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Unexpected output %q, want: %q", buf.String(), "")
+		}
+	})
+
 	t.Run("Uses local time for console writer without time zone", func(t *testing.T) {
 		// Regression test for issue #483 (check there for more details)
 
@@ -427,6 +514,482 @@ func TestConsoleWriterConfiguration(t *testing.T) {
 	})
 }
 
+func TestConsoleWriterNoColorDetection(t *testing.T) {
+	t.Run("Pipe", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		r, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+		os.Stdout = pw
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.PartsExclude = []string{"time"}
+		})
+		if !w.NoColor {
+			t.Fatal("NoColor is false for a pipe")
+		}
+
+		_, err = w.Write([]byte(`{"level": "info", "message": "Foobar", "foo": "bar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+		pw.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := "INF Foobar foo=bar\n"
+		actualOutput := string(out)
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("NO_COLOR", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		buf := &bytes.Buffer{}
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.Out = buf
+		})
+		if !w.NoColor {
+			t.Error("NoColor is false with NO_COLOR set")
+		}
+	})
+
+	t.Run("Explicit", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "1")
+		buf := &bytes.Buffer{}
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.Out = buf
+			w.NoColor = false
+			w.PartsExclude = []string{"time"}
+		})
+		if w.NoColor {
+			t.Error("NoColor set by an option is overridden")
+		}
+
+		_, err := w.Write([]byte(`{"level": "info", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "\x1b[32mINF\x1b[0m Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Explicit file", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		f, err := os.CreateTemp(t.TempDir(), "console")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		defer func(stdout *os.File) { os.Stdout = stdout }(os.Stdout)
+		os.Stdout = f
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.NoColor = false
+			w.PartsExclude = []string{"time"}
+		})
+		if w.NoColor {
+			t.Error("NoColor set by an option is overridden for a file")
+		}
+
+		_, err = w.Write([]byte(`{"level": "info", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+		out, err := os.ReadFile(f.Name())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := "\x1b[32mINF\x1b[0m Foobar\n"
+		actualOutput := string(out)
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Explicit pipe", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		r, pw, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer r.Close()
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.Out = pw
+			w.PartsExclude = []string{"time"}
+		})
+		w.NoColor = false
+
+		_, err = w.Write([]byte(`{"level": "info", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+		pw.Close()
+		out, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expectedOutput := "\x1b[32mINF\x1b[0m Foobar\n"
+		actualOutput := string(out)
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Buffer", func(t *testing.T) {
+		t.Setenv("NO_COLOR", "")
+		// The colors are detected on os.Stdout, before the options.
+		want := zerolog.NewConsoleWriter().NoColor
+		w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+			w.Out = &bytes.Buffer{}
+		})
+		if w.NoColor != want {
+			t.Errorf("NoColor = %v for a buffer, want %v", w.NoColor, want)
+		}
+	})
+}
+
+func TestConsoleWriterMessageKey(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		evt  string
+		want string
+	}{
+		{
+			name: "Rendered",
+			evt:  `{"level": "info", "message_key": "login.failed", "message_params": {"user": "bob"}, "message": "Login of bob failed"}`,
+			want: `INF Login of bob failed message_params={"user":"bob"}` + "\n",
+		},
+		{
+			name: "NotRendered",
+			evt:  `{"level": "info", "message_key": "login.failed", "message_params": {"user": "bob"}}`,
+			want: `INF login.failed message_params={"user":"bob"}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsOrder: []string{"level", "message"}}
+			if _, err := w.Write([]byte(tt.evt)); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterStack(t *testing.T) {
+	frames := `{"level": "error", "message": "Foobar", "error": "boom", "stack": [{"func": "main.foo", "line": "42", "source": "main.go"}, {"func": "main.main", "line": "7", "source": "main.go"}]}`
+	lines := `{"level": "error", "message": "Foobar", "stack": "goroutine 1 [running]:\nmain.foo()\n\tmain.go:42\n", "foo": "bar"}`
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		evt  string
+		want string
+	}{
+		{
+			name: "Frames",
+			w:    zerolog.ConsoleWriter{NoColor: true},
+			evt:  frames,
+			want: "ERR Foobar error=boom\n  main.foo main.go:42\n  main.main main.go:7\n",
+		},
+		{
+			name: "Lines",
+			w:    zerolog.ConsoleWriter{NoColor: true},
+			evt:  lines,
+			want: "ERR Foobar foo=bar\n  goroutine 1 [running]:\n  main.foo()\n  \tmain.go:42\n",
+		},
+		{
+			name: "Colors",
+			w:    zerolog.ConsoleWriter{PartsOrder: []string{"message"}},
+			evt:  frames,
+			want: "Foobar \x1b[36merror=\x1b[0m\x1b[31mboom\x1b[0m\n  \x1b[90mmain.foo main.go:42\x1b[0m\n  \x1b[90mmain.main main.go:7\x1b[0m\n",
+		},
+		{
+			name: "SingleLineStack",
+			w:    zerolog.ConsoleWriter{NoColor: true, SingleLineStack: true},
+			evt:  frames,
+			want: `ERR Foobar error=boom stack=[{"func":"main.foo","line":"42","source":"main.go"},{"func":"main.main","line":"7","source":"main.go"}]` + "\n",
+		},
+		{
+			name: "Excluded",
+			w:    zerolog.ConsoleWriter{NoColor: true, FieldsExclude: []string{"stack"}},
+			evt:  frames,
+			want: "ERR Foobar error=boom\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			if w.PartsOrder == nil {
+				w.PartsOrder = []string{"level", "message"}
+			}
+
+			_, err := w.Write([]byte(tt.evt))
+			if err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterMessageColumn(t *testing.T) {
+	events := []string{
+		`{"level": "info", "message": "start", "foo": "bar"}`,
+		`{"level": "debug", "caller": "main.go:12", "message": "dbg"}`,
+		`{"level": "warn", "caller": "internal/verylongpath/file.go:123", "message": "long"}`,
+	}
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		want string
+	}{
+		{
+			name: "Without caller",
+			w:    zerolog.ConsoleWriter{NoColor: true, MessageColumn: 8, PartsExclude: []string{"time", "caller"}},
+			want: "INF     start foo=bar\n" +
+				"DBG     dbg\n" +
+				"WRN     long\n",
+		},
+		{
+			name: "With caller",
+			w:    zerolog.ConsoleWriter{NoColor: true, MessageColumn: 20, PartsExclude: []string{"time"}},
+			want: "INF                 start foo=bar\n" +
+				"DBG main.go:12 >    dbg\n" +
+				"WRN internal/verylongpath/file.go:123 > long\n",
+		},
+		{
+			name: "Colors",
+			w:    zerolog.ConsoleWriter{MessageColumn: 8, PartsExclude: []string{"time", "caller"}},
+			want: "\x1b[32mINF\x1b[0m     start \x1b[36mfoo=\x1b[0mbar\n" +
+				"\x1b[33mDBG\x1b[0m     dbg\n" +
+				"\x1b[31mWRN\x1b[0m     long\n",
+		},
+		{
+			name: "Disabled",
+			w:    zerolog.ConsoleWriter{NoColor: true, PartsExclude: []string{"time"}},
+			want: "INF start foo=bar\n" +
+				"DBG main.go:12 > dbg\n" +
+				"WRN internal/verylongpath/file.go:123 > long\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			for _, evt := range events {
+				if _, err := w.Write([]byte(evt)); err != nil {
+					t.Errorf("Unexpected error when writing output: %s", err)
+				}
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterMultiline(t *testing.T) {
+	evt := `{"time": "2001-02-03T04:05:06Z", "level": "info", "message": "Foobar", "foo": "bar", "n": 42, "error": "boom", "secret": "x"}`
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		want string
+	}{
+		{
+			name: "NoColor",
+			w:    zerolog.ConsoleWriter{NoColor: true, PartsExclude: []string{"time"}, FieldsExclude: []string{"secret"}},
+			want: "INF Foobar\n  error=boom\n  foo=bar\n  n=42\n",
+		},
+		{
+			name: "Colors",
+			w:    zerolog.ConsoleWriter{PartsOrder: []string{"level"}, FieldsExclude: []string{"secret", "error"}},
+			want: "\x1b[32mINF\x1b[0m\n  \x1b[36mfoo=\x1b[0mbar\n  \x1b[36mn=\x1b[0m42\n",
+		},
+		{
+			name: "No parts",
+			w:    zerolog.ConsoleWriter{NoColor: true, PartsOrder: []string{}, FieldsExclude: []string{"secret", "error"}},
+			want: "  foo=bar\n  n=42\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			w.Multiline = true
+
+			if _, err := w.Write([]byte(evt)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterElapsed(t *testing.T) {
+	defer zerolog.SetReferenceTime(time.Time{})
+	ref := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+
+	t.Run("Explicit", func(t *testing.T) {
+		zerolog.SetReferenceTime(ref)
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, TimeFormat: zerolog.ConsoleTimeFormatElapsed}
+		for _, ts := range []time.Time{ref.Add(90 * time.Second), ref.Add(-time.Second)} {
+			evt := `{"time": "` + ts.Format(time.RFC3339) + `", "level": "info", "message": "Foobar"}`
+			if _, err := w.Write([]byte(evt)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+		}
+
+		expectedOutput := "+1m30s INF Foobar\n-1s INF Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Unset", func(t *testing.T) {
+		zerolog.SetReferenceTime(time.Time{})
+		start := zerolog.ReferenceTime()
+		if start.IsZero() || start.After(time.Now()) {
+			t.Fatalf("ReferenceTime() = %v, want the start of the process", start)
+		}
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, TimeFormat: zerolog.ConsoleTimeFormatElapsed}
+		ts := start.Add(time.Hour).Truncate(time.Second)
+		evt := `{"time": "` + ts.Format(time.RFC3339) + `", "level": "info", "message": "Foobar"}`
+		if _, err := w.Write([]byte(evt)); err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "+" + ts.Sub(start).String() + " INF Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+}
+
+func TestConsoleWriterRegisteredLevel(t *testing.T) {
+	notice, err := zerolog.RegisterLevelAbove(10, "notice", zerolog.InfoLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zerolog.RegisterConsoleLevel(notice, "NTC", 36)
+
+	buf := &bytes.Buffer{}
+	log := zerolog.New(zerolog.ConsoleWriter{Out: buf, NoColor: false, PartsOrder: []string{"level", "message"}})
+	log.WithLevel(notice).Msg("Foobar")
+
+	expectedOutput := "\x1b[36mNTC\x1b[0m Foobar\n"
+	actualOutput := buf.String()
+	if actualOutput != expectedOutput {
+		t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+	}
+}
+
+func TestConsoleWriterLevelMarkers(t *testing.T) {
+	t.Run("Minimal preset", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, Minimal: true}
+
+		d := time.Unix(0, 0).UTC().Format(time.RFC3339)
+		evt := `{"time": "` + d + `", "level": "error", "message": "Foobar", "caller": "/a/b/foo.go:12", "foo": "bar"}`
+		_, err := w.Write([]byte(evt))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "✗ foo.go:12 > Foobar foo=bar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Minimal preset colorized", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: false, Minimal: true}
+
+		_, err := w.Write([]byte(`{"level": "info", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "\x1b[32m•\x1b[0m Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Custom markers are padded to the same width", func(t *testing.T) {
+		markers := map[zerolog.Level]string{
+			zerolog.InfoLevel:  "i",
+			zerolog.ErrorLevel: "🔥",
+		}
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsExclude: []string{"time"}, LevelMarkers: markers}
+
+		for _, evt := range []string{
+			`{"level": "info", "message": "Foobar"}`,
+			`{"level": "error", "message": "Foobar"}`,
+			`{"level": "warn", "message": "Foobar"}`,
+		} {
+			_, err := w.Write([]byte(evt))
+			if err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+		}
+
+		expectedOutput := "i  Foobar\n🔥 Foobar\nWRN Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+
+	t.Run("Custom markers colorized", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: false, PartsOrder: []string{"level", "message"},
+			LevelMarkers: map[zerolog.Level]string{zerolog.WarnLevel: "!"}}
+
+		_, err := w.Write([]byte(`{"level": "warn", "message": "Foobar"}`))
+		if err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+
+		expectedOutput := "\x1b[31m!\x1b[0m Foobar\n"
+		actualOutput := buf.String()
+		if actualOutput != expectedOutput {
+			t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+		}
+	})
+}
+
 func BenchmarkConsoleWriter(b *testing.B) {
 	b.ResetTimer()
 	b.ReportAllocs()
@@ -440,3 +1003,463 @@ func BenchmarkConsoleWriter(b *testing.B) {
 		utils.HandleErr(err, "Failed writing")
 	}
 }
+
+func BenchmarkConsoleWriterFields(b *testing.B) {
+	msg := []byte(`{"level":"error","time":"2006-01-02T15:04:05Z","caller":"main.go:42","error":"boom",` +
+		`"path":"/api/users","status":500,"took":12.5,"ok":false,"user":{"id":7,"name":"bob"},"message":"request failed"}`)
+	w := zerolog.ConsoleWriter{Out: io.Discard}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := w.Write(msg)
+		utils.HandleErr(err, "Failed writing")
+	}
+}
+
+func TestConsoleWriterFieldsIndent(t *testing.T) {
+	evt := `{"level": "info", "message": "Foobar", "user": {"name": "bob", "address": {"city": "Paris", "zip": 75001}}, "tags": ["a", "b"], "empty": {}, "n": 1}`
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		want string
+	}{
+		{
+			name: "Inline",
+			w:    zerolog.ConsoleWriter{NoColor: true},
+			want: `INF Foobar empty={} n=1 tags=["a","b"] user={"address":{"city":"Paris","zip":75001},"name":"bob"}` + "\n",
+		},
+		{
+			name: "Indented",
+			w:    zerolog.ConsoleWriter{NoColor: true, FieldsIndent: 2},
+			want: "INF Foobar empty={} n=1 tags=[\n" +
+				"  \"a\",\n" +
+				"  \"b\"\n" +
+				"] user={\n" +
+				"  \"address\": {\n" +
+				"    \"city\": \"Paris\",\n" +
+				"    \"zip\": 75001\n" +
+				"  },\n" +
+				"  \"name\": \"bob\"\n" +
+				"}\n",
+		},
+		{
+			name: "Multiline",
+			w:    zerolog.ConsoleWriter{NoColor: true, FieldsIndent: 4, Multiline: true, FieldsExclude: []string{"user", "empty"}},
+			want: "INF Foobar\n" +
+				"  n=1\n" +
+				"  tags=[\n" +
+				"      \"a\",\n" +
+				"      \"b\"\n" +
+				"  ]\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			w.PartsExclude = []string{"time"}
+
+			if _, err := w.Write([]byte(evt)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterVerbatim(t *testing.T) {
+	input := []string{
+		`{"level": "info", "message": "Foobar"}`,
+		"panic: boom\n\ngoroutine 1 [running]:\n",
+		`{"level": "warn", "message": "Again", "foo": "bar"}`,
+		"plain",
+	}
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		want string
+	}{
+		{
+			name: "Default",
+			w:    zerolog.ConsoleWriter{NoColor: true},
+			want: "INF Foobar\npanic: boom\n\ngoroutine 1 [running]:\nWRN Again foo=bar\nplain",
+		},
+		{
+			name: "Marker",
+			w:    zerolog.ConsoleWriter{NoColor: true, VerbatimMarker: true},
+			want: "INF Foobar\n??? panic: boom\n??? \n??? goroutine 1 [running]:\nWRN Again foo=bar\n??? plain",
+		},
+		{
+			name: "Colored marker",
+			w:    zerolog.ConsoleWriter{VerbatimMarker: true, PartsOrder: []string{"message"}},
+			want: "Foobar\n\x1b[90m???\x1b[0m panic: boom\n\x1b[90m???\x1b[0m \n\x1b[90m???\x1b[0m goroutine 1 [running]:\n" +
+				"Again \x1b[36mfoo=\x1b[0mbar\n\x1b[90m???\x1b[0m plain",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			w.PartsExclude = []string{"time"}
+
+			for _, in := range input {
+				n, err := w.Write([]byte(in))
+				if err != nil {
+					t.Errorf("Unexpected error when writing output: %s", err)
+				}
+				if n != len(in) {
+					t.Errorf("Write() = %d, want %d", n, len(in))
+				}
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+
+	t.Run("Non-ASCII", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true}
+		in := "Ошибка: файл не найден\n"
+		if _, err := w.Write([]byte(in)); err != nil {
+			t.Errorf("Unexpected error when writing output: %s", err)
+		}
+		if buf.String() != in {
+			t.Errorf("Unexpected output %q, want: %q", buf.String(), in)
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, Strict: true}
+		if _, err := w.Write([]byte("plain")); err == nil {
+			t.Error("Expected an error when writing plain text")
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Unexpected output %q, want: %q", buf.String(), "")
+		}
+	})
+}
+
+func TestConsoleWriterErrOut(t *testing.T) {
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+		w.Out = out
+		w.ErrOut = errOut
+		w.NoColor = true
+		w.PartsExclude = []string{"time"}
+	})
+	log := zerolog.New(w).Level(zerolog.TraceLevel)
+
+	log.Trace().Msg("trace")
+	log.Debug().Msg("debug")
+	log.Info().Msg("info")
+	log.Warn().Msg("warn")
+	log.Error().Msg("error")
+	log.WithLevel(zerolog.FatalLevel).Msg("fatal")
+	log.Log().Msg("nolevel")
+
+	if got, want := out.String(), "TRC trace\nDBG debug\nINF info\n??? nolevel\n"; got != want {
+		t.Errorf("Unexpected output %q, want: %q", got, want)
+	}
+	if got, want := errOut.String(), "WRN warn\nERR error\nFTL fatal\n"; got != want {
+		t.Errorf("Unexpected error output %q, want: %q", got, want)
+	}
+
+	t.Run("Write", func(t *testing.T) {
+		out.Reset()
+		errOut.Reset()
+		w.ErrLevel = zerolog.ErrorLevel
+		for _, in := range []string{
+			`{"level": "warn", "message": "warn"}`,
+			`{"level": "error", "message": "error"}`,
+			`{"message": "nolevel"}`,
+			"plain\n",
+		} {
+			if _, err := w.Write([]byte(in)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+		}
+		if got, want := out.String(), "WRN warn\n??? nolevel\nplain\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+		if got, want := errOut.String(), "ERR error\n"; got != want {
+			t.Errorf("Unexpected error output %q, want: %q", got, want)
+		}
+	})
+}
+
+func TestConsoleWriterCallerTrimPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		caller string
+		want   string
+	}{
+		{"match", "/home/ci/build/src/service", "/home/ci/build/src/service/internal/foo/bar.go:42", "internal/foo/bar.go:42"},
+		{"trailing separator", "/home/ci/build/src/service/", "/home/ci/build/src/service/internal/foo/bar.go:42", "internal/foo/bar.go:42"},
+		{"sibling directory", "/home/ci/build/src/service", "/home/ci/build/src/servicex/bar.go:42", "/home/ci/build/src/servicex/bar.go:42"},
+		{"non-match", "/home/ci/build/src/service", "other/bar.go:42", "other/bar.go:42"},
+		{"windows", `C:\build\service`, `C:\build\service\internal\bar.go:42`, `internal\bar.go:42`},
+		{"mixed separators", "C:/build/service", `C:\build\service\internal\bar.go:42`, `internal\bar.go:42`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := zerolog.ConsoleWriter{Out: buf, NoColor: true, CallerTrimPrefix: tt.prefix, PartsExclude: []string{"time", "level"}}
+			caller, _ := json.Marshal(tt.caller)
+			if _, err := w.Write([]byte(`{"message":"Foobar","caller":` + string(caller) + `}`)); err != nil {
+				t.Fatalf("Unexpected error when writing output: %s", err)
+			}
+			// A caller outside of the prefix and of the working directory
+			// is rendered relative to the latter.
+			want := tt.want
+			if filepath.IsAbs(want) {
+				cwd, _ := os.Getwd()
+				want, _ = filepath.Rel(cwd, want)
+			}
+			if got, want := buf.String(), want+" > Foobar\n"; got != want {
+				t.Errorf("Unexpected output %q, want: %q", got, want)
+			}
+		})
+	}
+
+	t.Run("custom formatter", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		w := zerolog.ConsoleWriter{Out: buf, NoColor: true, CallerTrimPrefix: "/src", PartsExclude: []string{"time", "level"},
+			FormatCaller: func(i interface{}) string { return fmt.Sprint(i) }}
+		if _, err := w.Write([]byte(`{"message":"Foobar","caller":"/src/bar.go:42"}`)); err != nil {
+			t.Fatalf("Unexpected error when writing output: %s", err)
+		}
+		if got, want := buf.String(), "/src/bar.go:42 Foobar\n"; got != want {
+			t.Errorf("Unexpected output %q, want: %q", got, want)
+		}
+	})
+}
+
+// byteWriter is an output whose Write isn't atomic: it appends p byte per
+// byte.
+type byteWriter struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (w *byteWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		w.mu.Lock()
+		w.buf.WriteByte(c)
+		w.mu.Unlock()
+		runtime.Gosched()
+	}
+	return len(p), nil
+}
+
+func TestConsoleWriterConcurrent(t *testing.T) {
+	out := &byteWriter{}
+	w := zerolog.NewConsoleWriter(func(w *zerolog.ConsoleWriter) {
+		w.Out = out
+		w.NoColor = true
+		w.PartsExclude = []string{"time"}
+	})
+	const goroutines, lines = 100, 10
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			log := zerolog.New(w)
+			for j := 0; j < lines; j++ {
+				log.Info().Int("goroutine", i).Int("line", j).Msg("Foobar")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSuffix(out.buf.String(), "\n"), "\n") {
+		var i, j int
+		if n, _ := fmt.Sscanf(line, "INF Foobar goroutine=%d line=%d", &i, &j); n != 2 || line != fmt.Sprintf("INF Foobar goroutine=%d line=%d", i, j) {
+			t.Fatalf("Unexpected output line %q", line)
+		}
+		seen[line] = true
+	}
+	if len(seen) != goroutines*lines {
+		t.Errorf("Unexpected number of distinct lines %d, want: %d", len(seen), goroutines*lines)
+	}
+}
+
+func TestConsoleFormatHumanized(t *testing.T) {
+	for _, tt := range []struct {
+		f    zerolog.Formatter
+		in   interface{}
+		want string
+	}{
+		{zerolog.ConsoleFormatDuration, json.Number("0"), "0s"},
+		{zerolog.ConsoleFormatDuration, json.Number("0.0123"), "12µs"},
+		{zerolog.ConsoleFormatDuration, json.Number("1.5"), "1.5ms"},
+		{zerolog.ConsoleFormatDuration, json.Number("430"), "430ms"},
+		{zerolog.ConsoleFormatDuration, json.Number("1234"), "1.2s"},
+		{zerolog.ConsoleFormatDuration, json.Number("83456"), "1m23s"},
+		{zerolog.ConsoleFormatDuration, json.Number("-250"), "-250ms"},
+		{zerolog.ConsoleFormatDuration, "n/a", "n/a"},
+		{zerolog.ConsoleFormatBytes, json.Number("0"), "0B"},
+		{zerolog.ConsoleFormatBytes, json.Number("512"), "512B"},
+		{zerolog.ConsoleFormatBytes, json.Number("1024"), "1KiB"},
+		{zerolog.ConsoleFormatBytes, json.Number("1536"), "1.5KiB"},
+		{zerolog.ConsoleFormatBytes, json.Number("1468006"), "1.4MiB"},
+		{zerolog.ConsoleFormatBytes, json.Number("2147483648"), "2GiB"},
+		{zerolog.ConsoleFormatBytes, json.Number("-2048"), "-2KiB"},
+		{zerolog.ConsoleFormatBytes, "n/a", "n/a"},
+	} {
+		if got := tt.f(tt.in); got != tt.want {
+			t.Errorf("Formatter(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	w := zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsExclude: []string{"time"}, FormatFieldValueByKey: map[string]zerolog.Formatter{
+		"took": zerolog.ConsoleFormatDuration,
+		"size": zerolog.ConsoleFormatBytes,
+	}}
+	evt := `{"level": "info", "message": "Foobar", "took": 1234.5, "size": 1468006, "count": 1468006}`
+	if _, err := w.Write([]byte(evt)); err != nil {
+		t.Errorf("Unexpected error when writing output: %s", err)
+	}
+	expectedOutput := "INF Foobar count=1468006 size=1.4MiB took=1.2s\n"
+	if actualOutput := buf.String(); actualOutput != expectedOutput {
+		t.Errorf("Unexpected output %q, want: %q", actualOutput, expectedOutput)
+	}
+}
+
+func TestConsoleWriterTimeLocation(t *testing.T) {
+	zone := time.FixedZone("UTC+5:30", 5*3600+30*60)
+	for _, tt := range []struct {
+		name            string
+		timeFieldFormat string
+		ts              string
+		w               zerolog.ConsoleWriter
+		want            string
+	}{
+		{
+			name:            "RFC3339",
+			timeFieldFormat: time.RFC3339,
+			ts:              `"2001-02-03T04:05:06Z"`,
+			w:               zerolog.ConsoleWriter{TimeLocation: zone, TimeFormat: time.RFC3339},
+			want:            "2001-02-03T09:35:06+05:30 INF Foobar\n",
+		},
+		{
+			name:            "Unix",
+			timeFieldFormat: zerolog.TimeFormatUnix,
+			ts:              "981173106",
+			w:               zerolog.ConsoleWriter{TimeLocation: zone, TimeFormat: "15:04:05"},
+			want:            "09:35:06 INF Foobar\n",
+		},
+		{
+			name:            "UnixMs FormatTime",
+			timeFieldFormat: zerolog.TimeFormatUnixMs,
+			ts:              "981173106007",
+			w: zerolog.ConsoleWriter{TimeLocation: zone, FormatTime: func(t time.Time) string {
+				return t.Format("15:04:05.000 MST")
+			}},
+			want: "09:35:06.007 UTC+5:30 INF Foobar\n",
+		},
+		{
+			name:            "FormatTimestamp precedence",
+			timeFieldFormat: time.RFC3339,
+			ts:              `"2001-02-03T04:05:06Z"`,
+			w: zerolog.ConsoleWriter{TimeLocation: zone, FormatTime: func(t time.Time) string { return "ignored" },
+				FormatTimestamp: func(i interface{}) string { return fmt.Sprintf("[%s]", i) }},
+			want: "[2001-02-03T04:05:06Z] INF Foobar\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(f string) { zerolog.TimeFieldFormat = f }(zerolog.TimeFieldFormat)
+			zerolog.TimeFieldFormat = tt.timeFieldFormat
+
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+			w.NoColor = true
+
+			evt := `{"time": ` + tt.ts + `, "level": "info", "message": "Foobar"}`
+			if _, err := w.Write([]byte(evt)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
+
+func TestConsoleWriterColors(t *testing.T) {
+	evt := `{"level": "error", "caller": "main.go:12", "message": "Foobar", "foo": "bar", "error": "boom"}`
+	theme := &zerolog.ConsoleColors{
+		Levels:        map[zerolog.Level]string{zerolog.ErrorLevel: "38;2;255;0;0"},
+		Timestamp:     "2",
+		Caller:        "1",
+		CallerMarker:  "38;5;33",
+		Message:       "97",
+		FieldName:     "38;5;45",
+		ErrFieldName:  "38;5;196",
+		ErrFieldValue: "4",
+	}
+	for _, tt := range []struct {
+		name string
+		w    zerolog.ConsoleWriter
+		want string
+	}{
+		{
+			name: "Custom",
+			w:    zerolog.ConsoleWriter{Colors: theme},
+			want: "\x1b[2m<nil>\x1b[0m \x1b[38;2;255;0;0mERR\x1b[0m \x1b[1mmain.go:12\x1b[0m\x1b[38;5;33m >\x1b[0m \x1b[97mFoobar\x1b[0m " +
+				"\x1b[38;5;196merror=\x1b[0m\x1b[4mboom\x1b[0m \x1b[38;5;45mfoo=\x1b[0mbar\n",
+		},
+		{
+			name: "Default",
+			w:    zerolog.ConsoleWriter{Colors: zerolog.ConsoleColorsDefault(), PartsExclude: []string{"time", "caller"}},
+			want: "\x1b[1;31mERR\x1b[0m Foobar \x1b[36merror=\x1b[0m\x1b[31mboom\x1b[0m \x1b[36mfoo=\x1b[0mbar\n",
+		},
+		{
+			name: "Light",
+			w:    zerolog.ConsoleWriter{Colors: zerolog.ConsoleColorsLight(), PartsExclude: []string{"time", "caller"}},
+			want: "\x1b[1;31mERR\x1b[0m Foobar \x1b[34merror=\x1b[0m\x1b[31mboom\x1b[0m \x1b[34mfoo=\x1b[0mbar\n",
+		},
+		{
+			name: "FormatLevel",
+			w: zerolog.ConsoleWriter{Colors: theme, PartsExclude: []string{"time", "caller"}, FormatLevel: func(i interface{}) string {
+				return strings.ToUpper(fmt.Sprintf("[%s]", i))
+			}},
+			want: "[ERROR] \x1b[97mFoobar\x1b[0m \x1b[38;5;196merror=\x1b[0m\x1b[4mboom\x1b[0m \x1b[38;5;45mfoo=\x1b[0mbar\n",
+		},
+		{
+			name: "NoColor",
+			w:    zerolog.ConsoleWriter{Colors: theme, NoColor: true, PartsExclude: []string{"time", "caller"}},
+			want: "ERR Foobar error=boom foo=bar\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			w := tt.w
+			w.Out = buf
+
+			if _, err := w.Write([]byte(evt)); err != nil {
+				t.Errorf("Unexpected error when writing output: %s", err)
+			}
+
+			actualOutput := buf.String()
+			if actualOutput != tt.want {
+				t.Errorf("Unexpected output %q, want: %q", actualOutput, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !js
+
+package zerolog
+
+import (
+	"io"
+	"os"
+
+	"github.com/mattn/go-isatty"
+)
+
+// consoleForceNoColor disables the colors of all the console writers.
+const consoleForceNoColor = false
+
+// consoleIsTerminal returns false if out is a file which is not a terminal.
+func consoleIsTerminal(out io.Writer) bool {
+	if f, ok := out.(*os.File); ok {
+		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package zerolog
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// containerInfo is the identity of the container running the process.
+type containerInfo struct {
+	cgroup       string
+	id           string
+	podName      string
+	podNamespace string
+	nodeName     string
+}
+
+var (
+	containerOnce sync.Once
+	container     containerInfo
+)
+
+// ContainerInfo adds the identity of the container running the process, read
+// once on Linux: its cgroup and its ID, with the CgroupFieldName and
+// ContainerIDFieldName keys, for the containers of Docker, containerd and
+// CRI-O, as well as the pod and node names set by the Kubernetes downward API
+// in the POD_NAME, POD_NAMESPACE and NODE_NAME environment variables, with
+// the PodNameFieldName, PodNamespaceFieldName and NodeNameFieldName keys.
+//
+// The information which is not available, like all of it outside of a
+// container, is omitted.
+func (c Context) ContainerInfo() Context {
+	containerOnce.Do(func() {
+		container = readContainerInfo(containerCgroupFile, os.Getenv)
+	})
+	for _, f := range []struct{ key, val string }{
+		{CgroupFieldName, container.cgroup},
+		{ContainerIDFieldName, container.id},
+		{PodNameFieldName, container.podName},
+		{PodNamespaceFieldName, container.podNamespace},
+		{NodeNameFieldName, container.nodeName},
+	} {
+		if f.val != "" {
+			c.l.context = enc.AppendString(enc.AppendKey(c.l.context, f.key), f.val)
+		}
+	}
+	return c
+}
+
+// readContainerInfo reads the identity of the container from the cgroup file
+// and the environment.
+func readContainerInfo(cgroupFile string, getenv func(string) string) containerInfo {
+	info := containerInfo{
+		podName:      getenv("POD_NAME"),
+		podNamespace: getenv("POD_NAMESPACE"),
+		nodeName:     getenv("NODE_NAME"),
+	}
+	if cgroupFile == "" {
+		return info
+	}
+	f, err := os.Open(cgroupFile)
+	if err != nil {
+		return info
+	}
+	defer f.Close()
+	info.cgroup, info.id = parseCgroup(f)
+	return info
+}
+
+// parseCgroup returns the cgroup of a container and its ID, from the content
+// of /proc/self/cgroup in cgroup v1 or v2 layouts. Both are empty if the
+// process is not in a container.
+func parseCgroup(r io.Reader) (cgroup, id string) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		parts := strings.SplitN(s.Text(), ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if id := cgroupContainerID(parts[2]); id != "" {
+			return parts[2], id
+		}
+	}
+	return "", ""
+}
+
+// cgroupContainerID returns the container ID in the cgroup path, like
+// /docker/<id>, /kubepods/besteffort/pod<uid>/<id> or
+// /kubepods.slice/.../cri-containerd-<id>.scope.
+func cgroupContainerID(path string) string {
+	elems := strings.Split(path, "/")
+	for i := len(elems) - 1; i >= 0; i-- {
+		e := strings.TrimSuffix(elems[i], ".scope")
+		if j := strings.LastIndexAny(e, "-:"); j >= 0 {
+			e = e[j+1:]
+		}
+		if isContainerID(e) {
+			return e
+		}
+	}
+	return ""
+}
+
+// isContainerID returns true if s is made of 64 hexadecimal digits.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,4 @@
+package zerolog
+
+// containerCgroupFile lists the cgroups of the process.
+const containerCgroupFile = "/proc/self/cgroup"
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package zerolog
+
+// containerCgroupFile lists the cgroups of the process, there is none outside
+// of Linux.
+const containerCgroupFile = ""
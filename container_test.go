@@ -0,0 +1,101 @@
+package zerolog
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testContainerID = "3f4e8a9b0c1d2e3f4a5b6c7d8e9f0a1b2c3d4e5f6a7b8c9d0e1f2a3b4c5d6e7f"
+
+func TestReadContainerInfo(t *testing.T) {
+	env := map[string]string{"POD_NAME": "web-0", "POD_NAMESPACE": "prod", "NODE_NAME": "node-1"}
+	tests := []struct {
+		name       string
+		cgroup     string
+		wantCgroup string
+		wantID     string
+	}{
+		{
+			name: "docker v1",
+			cgroup: "12:memory:/docker/" + testContainerID + "\n" +
+				"11:cpu,cpuacct:/docker/" + testContainerID + "\n" +
+				"1:name=systemd:/docker/" + testContainerID + "\n",
+			wantCgroup: "/docker/" + testContainerID,
+			wantID:     testContainerID,
+		},
+		{
+			name:       "docker v2",
+			cgroup:     "0::/system.slice/docker-" + testContainerID + ".scope\n",
+			wantCgroup: "/system.slice/docker-" + testContainerID + ".scope",
+			wantID:     testContainerID,
+		},
+		{
+			name: "containerd v1",
+			cgroup: "12:pids:/kubepods/besteffort/pod0b8c2a6e-1f3d-4a5b-9c7d-2e4f6a8b0c1d/" + testContainerID + "\n" +
+				"0::/\n",
+			wantCgroup: "/kubepods/besteffort/pod0b8c2a6e-1f3d-4a5b-9c7d-2e4f6a8b0c1d/" + testContainerID,
+			wantID:     testContainerID,
+		},
+		{
+			name:       "containerd v2",
+			cgroup:     "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b8c2a6e_1f3d.slice/cri-containerd-" + testContainerID + ".scope\n",
+			wantCgroup: "/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0b8c2a6e_1f3d.slice/cri-containerd-" + testContainerID + ".scope",
+			wantID:     testContainerID,
+		},
+		{
+			name:       "cri-o",
+			cgroup:     "0::/kubepods.slice/kubepods-pod0b8c2a6e_1f3d.slice/crio-" + testContainerID + ".scope\n",
+			wantCgroup: "/kubepods.slice/kubepods-pod0b8c2a6e_1f3d.slice/crio-" + testContainerID + ".scope",
+			wantID:     testContainerID,
+		},
+		{
+			name:   "host",
+			cgroup: "0::/user.slice/user-1000.slice/session-2.scope\n",
+		},
+		{
+			name:   "cgroup namespace",
+			cgroup: "0::/\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "cgroup")
+			if err := os.WriteFile(file, []byte(tt.cgroup), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got := readContainerInfo(file, func(k string) string { return env[k] })
+			want := containerInfo{
+				cgroup:       tt.wantCgroup,
+				id:           tt.wantID,
+				podName:      "web-0",
+				podNamespace: "prod",
+				nodeName:     "node-1",
+			}
+			if got != want {
+				t.Errorf("readContainerInfo() = %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		got := readContainerInfo(filepath.Join(t.TempDir(), "cgroup"), func(string) string { return "" })
+		if got != (containerInfo{}) {
+			t.Errorf("readContainerInfo() = %+v, want %+v", got, containerInfo{})
+		}
+	})
+}
+
+func TestContextContainerInfo(t *testing.T) {
+	containerOnce.Do(func() {})
+	defer func(c containerInfo) { container = c }(container)
+	container = containerInfo{cgroup: "/docker/" + testContainerID, id: testContainerID, podName: "web-0"}
+
+	out := &bytes.Buffer{}
+	New(out).With().ContainerInfo().Logger().Log().Msg("")
+	want := `{"cgroup":"/docker/` + testContainerID + `","container_id":"` + testContainerID + `","pod_name":"web-0"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -1,6 +1,7 @@
 package zerolog
 
 import (
+	"encoding/base64"
 	"fmt"
 	"io"
 	"math"
@@ -22,7 +23,7 @@ func (c Context) Logger() *Logger {
 // Only map[string]interface{} and []interface{} are accepted. []interface{} must
 // alternate string keys and arbitrary values, and extraneous ones are ignored.
 func (c Context) Fields(fields interface{}) Context {
-	c.l.context = appendFields(c.l.context, fields)
+	c.l.context = appendFields(c.l.context, fields, c.l.timeFormat())
 	return c
 }
 
@@ -58,6 +59,9 @@ func (c Context) Array(key string, arr LogArrayMarshaler) Context {
 func (c Context) Object(key string, obj LogObjectMarshaler) Context {
 	e := newEvent(levelWriterAdapter{io.Discard}, 0)
 	e.Object(key, obj)
+	if e.truncated {
+		e.buf = enc.AppendBool(enc.AppendKey(e.buf, NestingTruncatedFieldName), true)
+	}
 	c.l.context = enc.AppendObjectData(c.l.context, e.buf)
 	putEvent(e)
 	return c
@@ -67,6 +71,9 @@ func (c Context) Object(key string, obj LogObjectMarshaler) Context {
 func (c Context) EmbedObject(obj LogObjectMarshaler) Context {
 	e := newEvent(levelWriterAdapter{io.Discard}, 0)
 	e.EmbedObject(obj)
+	if e.truncated {
+		e.buf = enc.AppendBool(enc.AppendKey(e.buf, NestingTruncatedFieldName), true)
+	}
 	c.l.context = enc.AppendObjectData(c.l.context, e.buf)
 	putEvent(e)
 	return c
@@ -95,6 +102,13 @@ func (c Context) Stringer(key string, val fmt.Stringer) Context {
 	return c
 }
 
+// Stringers adds the field key with vals where each individual val is used
+// as val.String() (or null if val is nil) to the logger context.
+func (c Context) Stringers(key string, vals []fmt.Stringer) Context {
+	c.l.context = enc.AppendStringers(enc.AppendKey(c.l.context, key), vals)
+	return c
+}
+
 // Bytes adds the field key with val as a []byte to the logger context.
 func (c Context) Bytes(key string, val []byte) Context {
 	c.l.context = enc.AppendBytes(enc.AppendKey(c.l.context, key), val)
@@ -107,6 +121,16 @@ func (c Context) Hex(key string, val []byte) Context {
 	return c
 }
 
+// Base64Bytes adds the field key with val as a base64 string encoded with
+// b64, or base64.StdEncoding if nil, to the logger context.
+func (c Context) Base64Bytes(key string, val []byte, b64 *base64.Encoding) Context {
+	if b64 == nil {
+		b64 = base64.StdEncoding
+	}
+	c.l.context = enc.AppendBase64(enc.AppendKey(c.l.context, key), val, b64)
+	return c
+}
+
 // RawJSON adds already encoded JSON to context.
 //
 // No sanity check is performed on b; it must not contain carriage returns and
@@ -339,15 +363,56 @@ func (c Context) Timestamp() Context {
 	return c
 }
 
-// Time adds the field key with t formated as string using zerolog.TimeFieldFormat.
+// TimestampFieldFormat overrides zerolog.TimeFieldFormat for the logger's
+// timestamp and for the time.Time values its events add with Time, Times and
+// Fields.
+//
+// Context fields are formatted when they are added, so only the Time, Times
+// and Fields calls following TimestampFieldFormat in the chain use the
+// format. Values added with Dict and Arr, which are built independently of
+// any logger, keep using zerolog.TimeFieldFormat.
+//
+// The binary (CBOR) encoder ignores the format and always uses the native
+// timestamp tag.
+func (c Context) TimestampFieldFormat(format string) Context {
+	c.l.timeFieldFormat = &format
+	return c
+}
+
+// LevelField overrides zerolog.LevelFieldName and, if marshal is not nil,
+// zerolog.LevelFieldMarshalFunc for the level field of the logger's events.
+// An empty name omits the level field.
+func (c Context) LevelField(name string, marshal func(l Level) string) Context {
+	c.l.levelFieldName = &name
+	c.l.levelFieldMarshalFunc = marshal
+	return c
+}
+
+// ErrorStackFieldName overrides zerolog.ErrorStackFieldName for the error
+// stacks added by the Err method of the logger's events.
+func (c Context) ErrorStackFieldName(name string) Context {
+	c.l.errorStackFieldName = &name
+	return c
+}
+
+// TimestampFunc overrides zerolog.TimestampFunc for the logger's timestamp.
+// It is the Context counterpart of Logger.WithClock.
+func (c Context) TimestampFunc(fn func() time.Time) Context {
+	c.l = c.l.WithClock(fn)
+	return c
+}
+
+// Time adds the field key with t formated as string using zerolog.TimeFieldFormat,
+// or the format set with TimestampFieldFormat.
 func (c Context) Time(key string, t time.Time) Context {
-	c.l.context = enc.AppendTime(enc.AppendKey(c.l.context, key), t, TimeFieldFormat)
+	c.l.context = enc.AppendTime(enc.AppendKey(c.l.context, key), t, c.l.timeFormat())
 	return c
 }
 
-// Times adds the field key with t formated as string using zerolog.TimeFieldFormat.
+// Times adds the field key with t formated as string using zerolog.TimeFieldFormat,
+// or the format set with TimestampFieldFormat.
 func (c Context) Times(key string, t []time.Time) Context {
-	c.l.context = enc.AppendTimes(enc.AppendKey(c.l.context, key), t, TimeFieldFormat)
+	c.l.context = enc.AppendTimes(enc.AppendKey(c.l.context, key), t, c.l.timeFormat())
 	return c
 }
 
@@ -373,6 +438,13 @@ func (c Context) Interface(key string, i interface{}) Context {
 	return c
 }
 
+// Type adds the field key with val's type using reflection to the logger
+// context.
+func (c Context) Type(key string, val interface{}) Context {
+	c.l.context = enc.AppendType(enc.AppendKey(c.l.context, key), val)
+	return c
+}
+
 type callerHook struct {
 	callerSkipFrameCount int
 }
@@ -402,15 +474,37 @@ var ch = newCallerHook(useGlobalSkipFrameCount)
 
 // Caller adds the file:line of the caller with the zerolog.CallerFieldName key.
 func (c Context) Caller() Context {
-	c.l = c.l.Hook(ch)
-	return c
+	return c.callerHook(ch)
 }
 
 // CallerWithSkipFrameCount adds the file:line of the caller with the zerolog.CallerFieldName key.
 // The specified skipFrameCount int will override the global CallerSkipFrameCount for this context's respective logger.
 // If set to -1 the global CallerSkipFrameCount will be used.
+//
+// Libraries wrapping the logger in their own logging functions can use it to
+// report the caller of these functions, e.g. with a skipFrameCount of
+// CallerSkipFrameCount+1 for one level of wrapper. Event.CallerSkipFrame skips
+// additional frames for an event.
 func (c Context) CallerWithSkipFrameCount(skipFrameCount int) Context {
-	c.l = c.l.Hook(newCallerHook(skipFrameCount))
+	if skipFrameCount == -1 {
+		return c.callerHook(ch)
+	}
+	return c.callerHook(newCallerHook(skipFrameCount))
+}
+
+// callerHook adds h to the hooks of the logger, replacing the caller hook
+// added by a previous call to Caller or CallerWithSkipFrameCount so that the
+// caller is only logged once.
+func (c Context) callerHook(h callerHook) Context {
+	for i, hook := range c.l.hooks {
+		if _, ok := hook.(callerHook); ok {
+			hooks := append([]Hook(nil), c.l.hooks...)
+			hooks[i] = h
+			c.l.hooks = hooks
+			return c
+		}
+	}
+	c.l = c.l.Hook(h)
 	return c
 }
 
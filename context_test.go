@@ -0,0 +1,56 @@
+//go:build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestContextTimestampFieldFormat(t *testing.T) {
+	ts := time.Date(2001, time.February, 3, 4, 5, 6, 7000000, time.UTC)
+	now := func() time.Time { return ts }
+
+	out := &bytes.Buffer{}
+	millis := New(out).With().TimestampFieldFormat(TimeFormatUnixMs).TimestampFunc(now).Timestamp().Logger()
+	nanos := New(out).With().TimestampFieldFormat(time.RFC3339Nano).TimestampFunc(now).Timestamp().Logger()
+
+	millis.Log().Time("t", ts).Times("ts", []time.Time{ts}).Msg("millis")
+	nanos.Log().Time("t", ts).Times("ts", []time.Time{ts}).Msg("nanos")
+	New(out).Log().Time("t", ts).Msg("global")
+
+	want := `{"t":981173106007,"ts":[981173106007],"time":981173106007,"message":"millis"}` + "\n" +
+		`{"t":"2001-02-03T04:05:06.007Z","ts":["2001-02-03T04:05:06.007Z"],"time":"2001-02-03T04:05:06.007Z","message":"nanos"}` + "\n" +
+		`{"t":"2001-02-03T04:05:06Z","message":"global"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestContextTimestampFieldFormatOutput(t *testing.T) {
+	ts := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	out := &bytes.Buffer{}
+	log := New(nil).With().TimestampFieldFormat(TimeFormatUnix).Time("t", ts).Logger().Output(out)
+	log.Log().Time("u", ts).Msg("")
+
+	if got, want := out.String(), `{"t":981173106,"u":981173106}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestContextTimestampFieldFormatFields(t *testing.T) {
+	ts := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	out := &bytes.Buffer{}
+	log := New(out).With().
+		Time("before", ts).
+		TimestampFieldFormat(TimeFormatUnix).
+		Fields(map[string]interface{}{"ctx": ts}).
+		Logger()
+	log.Log().Fields([]interface{}{"evt", ts, "evts", []time.Time{ts}}).Msg("")
+
+	want := `{"before":"2001-02-03T04:05:06Z","ctx":981173106,"evt":981173106,"evts":[981173106]}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,220 @@
+package zerolog
+
+import (
+	"bytes"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+const (
+	// DedupWindow is the default window of a DedupLevelWriter.
+	DedupWindow = time.Second
+
+	// DedupMaxEntries is the default maximum number of distinct events
+	// tracked by a DedupLevelWriter.
+	DedupMaxEntries = 1024
+)
+
+// DedupLevelWriter suppresses the repeats of an event, for instance the
+// same error logged thousands of times per second by a stuck dependency.
+// The first occurrence is written, and the identical events written within
+// Window after it are dropped. At the end of the window, the last dropped
+// one is written with the RepeatedFieldName field giving the number of
+// dropped events:
+//
+//	w := &zerolog.DedupLevelWriter{Writer: os.Stderr}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The events are identical if the values of their Keys fields are, their
+// level and message by default, whatever their other fields. Only these
+// values are scanned, in JSON or in binary format, and the events which are
+// not objects are always written. The WriteLevel method of Writer is used
+// if it implements LevelWriter.
+//
+// It is safe for concurrent use. Close writes the pending summaries.
+type DedupLevelWriter struct {
+	Writer io.Writer
+
+	// Window is the duration the repeats of an event are suppressed for,
+	// DedupWindow if 0.
+	Window time.Duration
+
+	// Keys are the fields identifying the events, LevelFieldName and
+	// MessageFieldName if empty.
+	Keys []string
+
+	// MaxEntries bounds the number of distinct events tracked at once,
+	// DedupMaxEntries if 0. The events are written without being tracked
+	// when the limit is reached.
+	MaxEntries int
+
+	mu      sync.Mutex
+	entries map[uint64]*dedupEntry
+	timer   *time.Timer
+}
+
+// dedupEntry is an event tracked by a DedupLevelWriter.
+type dedupEntry struct {
+	deadline time.Time
+	repeated int
+	level    Level
+	last     []byte // last suppressed event
+}
+
+// Write implements the io.Writer interface.
+func (w *DedupLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *DedupLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	h, ok := w.hash(p)
+	if !ok {
+		return w.write(l, p)
+	}
+	now := time.Now()
+	if e, found := w.entries[h]; found && now.Before(e.deadline) {
+		e.repeated++
+		e.level = l
+		e.last = append(e.last[:0], p...)
+		return len(p), nil
+	}
+	if err := w.expire(now); err != nil {
+		return 0, err
+	}
+	if w.entries == nil {
+		w.entries = map[uint64]*dedupEntry{}
+	}
+	max := w.MaxEntries
+	if max <= 0 {
+		max = DedupMaxEntries
+	}
+	if len(w.entries) < max {
+		window := w.Window
+		if window <= 0 {
+			window = DedupWindow
+		}
+		w.entries[h] = &dedupEntry{deadline: now.Add(window)}
+		w.schedule(now)
+	}
+	return w.write(l, p)
+}
+
+func (w *DedupLevelWriter) write(l Level, p []byte) (n int, err error) {
+	if lw, ok := w.Writer.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Writer.Write(p)
+}
+
+// hash returns the hash of the values of the keys of the event p, or false
+// if p is not an object.
+func (w *DedupLevelWriter) hash(p []byte) (uint64, bool) {
+	p = bytes.TrimLeft(p, " \t\r\n")
+	if len(p) == 0 || (p[0] != '{' && p[0] <= 0x7f) {
+		return 0, false
+	}
+	keys := w.Keys
+	if len(keys) == 0 {
+		keys = []string{LevelFieldName, MessageFieldName}
+	}
+	h := fnv.New64a()
+	for _, key := range keys {
+		var start, end int
+		var ok bool
+		if p[0] > 0x7f {
+			start, end, ok = cbor.MapValue(p, key)
+		} else {
+			start, end, ok = jsonFieldValue(p, key)
+		}
+		if ok {
+			_, _ = h.Write(p[start:end])
+		}
+		// Separate the values so that moving bytes between them changes
+		// the hash.
+		_, _ = h.Write([]byte{0})
+	}
+	return h.Sum64(), true
+}
+
+// expire writes the summaries of the entries whose window ended at now, or
+// of all of them if now is zero, in the order of their windows, and stops
+// tracking them.
+func (w *DedupLevelWriter) expire(now time.Time) (err error) {
+	var expired []*dedupEntry
+	for h, e := range w.entries {
+		if now.IsZero() || !now.Before(e.deadline) {
+			delete(w.entries, h)
+			expired = append(expired, e)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return expired[i].deadline.Before(expired[j].deadline)
+	})
+	for _, e := range expired {
+		if werr := w.summarize(e); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// summarize writes the last suppressed event of e with the number of
+// repeats, if any.
+func (w *DedupLevelWriter) summarize(e *dedupEntry) error {
+	if e.repeated == 0 {
+		return nil
+	}
+	// Replace the end marker of the event with the field.
+	p := bytes.TrimRight(e.last, "\n")
+	p = enc.AppendInt(enc.AppendKey(p[:len(p)-1], RepeatedFieldName), e.repeated)
+	p = enc.AppendLineBreak(enc.AppendEndMarker(p))
+	_, err := w.write(e.level, p)
+	return err
+}
+
+// schedule arms the timer writing the summaries at the end of the earliest
+// window, if it isn't.
+func (w *DedupLevelWriter) schedule(now time.Time) {
+	if w.timer != nil || len(w.entries) == 0 {
+		return
+	}
+	var next time.Time
+	for _, e := range w.entries {
+		if next.IsZero() || e.deadline.Before(next) {
+			next = e.deadline
+		}
+	}
+	w.timer = time.AfterFunc(next.Sub(now), w.tick)
+}
+
+func (w *DedupLevelWriter) tick() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.timer = nil
+	now := time.Now()
+	if err := w.expire(now); err != nil {
+		handleWriteError(err)
+	}
+	w.schedule(now)
+}
+
+// Close writes the summaries of the events suppressed so far, and stops
+// tracking them. It doesn't close Writer.
+func (w *DedupLevelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+	return w.expire(time.Time{})
+}
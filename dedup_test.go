@@ -0,0 +1,137 @@
+package zerolog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent use.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return decodeIfBinaryToString(b.buf.Bytes())
+}
+
+func TestDedupLevelWriter(t *testing.T) {
+	out := &lockedBuffer{}
+	w := &DedupLevelWriter{Writer: out, Window: time.Hour}
+	log := New(w)
+	for i := 0; i < 100; i++ {
+		log.Error().Int("i", i).Msg("boom")
+		if i == 50 {
+			log.Info().Msg("other")
+		}
+	}
+	log.Warn().Msg("boom")
+	want := `{"level":"error","i":0,"message":"boom"}` + "\n" +
+		`{"level":"info","message":"other"}` + "\n" +
+		`{"level":"warn","message":"boom"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want += `{"level":"error","i":99,"message":"boom","repeated":99}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	// The events are tracked again after Close.
+	log.Error().Msg("boom")
+	log.Error().Msg("boom")
+	_ = w.Close()
+	want += `{"level":"error","message":"boom"}` + "\n" +
+		`{"level":"error","message":"boom","repeated":1}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDedupLevelWriterWindow(t *testing.T) {
+	out := &lockedBuffer{}
+	w := &DedupLevelWriter{Writer: out, Window: 20 * time.Millisecond}
+	defer w.Close()
+	log := New(w)
+	for i := 0; i < 3; i++ {
+		log.Error().Msg("boom")
+	}
+	want := `{"level":"error","message":"boom"}` + "\n" +
+		`{"level":"error","message":"boom","repeated":2}` + "\n"
+	deadline := time.Now().Add(5 * time.Second)
+	for out.String() != want && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := out.String(); got != want {
+		t.Fatalf("summary not written at the end of the window:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	log.Error().Msg("boom")
+	want += `{"level":"error","message":"boom"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDedupLevelWriterKeys(t *testing.T) {
+	out := &lockedBuffer{}
+	w := &DedupLevelWriter{Writer: out, Window: time.Hour, Keys: []string{"code"}}
+	log := New(w)
+	log.Error().Int("code", 1).Msg("one")
+	log.Warn().Int("code", 1).Msg("two")
+	log.Error().Int("code", 2).Msg("three")
+	log.Error().Msg("no code")
+	log.Info().Msg("no code either")
+	_ = w.Close()
+	want := `{"level":"error","code":1,"message":"one"}` + "\n" +
+		`{"level":"error","code":2,"message":"three"}` + "\n" +
+		`{"level":"error","message":"no code"}` + "\n" +
+		`{"level":"warn","code":1,"message":"two","repeated":1}` + "\n" +
+		`{"level":"info","message":"no code either","repeated":1}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDedupLevelWriterMaxEntries(t *testing.T) {
+	out := &lockedBuffer{}
+	w := &DedupLevelWriter{Writer: out, Window: time.Hour, MaxEntries: 1}
+	log := New(w)
+	for i := 0; i < 2; i++ {
+		log.Error().Msg("tracked")
+		log.Error().Msg("untracked")
+	}
+	_ = w.Close()
+	want := `{"level":"error","message":"tracked"}` + "\n" +
+		`{"level":"error","message":"untracked"}` + "\n" +
+		`{"level":"error","message":"untracked"}` + "\n" +
+		`{"level":"error","message":"tracked","repeated":1}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestDedupLevelWriterNotEvent(t *testing.T) {
+	var out bytes.Buffer
+	w := &DedupLevelWriter{Writer: &out}
+	_, _ = w.Write([]byte("not an event\n"))
+	_, _ = w.Write([]byte("not an event\n"))
+	_ = w.Close()
+	if got, want := out.String(), "not an event\nnot an event\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/x0f5c3/zerolog/diode/internal/diodes"
@@ -14,11 +15,44 @@ import (
 
 var bufPool = &sync.Pool{
 	New: func() interface{} {
-		return make([]byte, 0, 500)
+		p := make([]byte, 0, 500)
+		return &p
 	},
 }
 
-type Alerter func(missed int)
+// statsInterval is the interval Alerter.Stats is called at when the writer
+// uses a waiter.
+const statsInterval = time.Second
+
+// Alerter receives the metrics of a Writer.
+type Alerter interface {
+	// Missed is called with the number of messages dropped since the
+	// previous call when the reader catches up with dropped messages.
+	Missed(count int)
+
+	// Stats is called on the poll interval, and when the writer is closed,
+	// with the cumulative numbers of messages written to the writer and
+	// dropped by it.
+	Stats(enqueued, dropped uint64)
+}
+
+// AlertFunc is an adapter to allow the use of ordinary functions as
+// Alerter. It ignores the stats.
+type AlertFunc func(missed int)
+
+// Missed calls f(count).
+func (f AlertFunc) Missed(count int) {
+	f(count)
+}
+
+// Stats does nothing.
+func (f AlertFunc) Stats(enqueued, dropped uint64) {}
+
+// writerStats holds the counters of a Writer.
+type writerStats struct {
+	enqueued uint64
+	dropped  uint64
+}
 
 type diodeFetcher interface {
 	diodes.Diode
@@ -28,10 +62,13 @@ type diodeFetcher interface {
 // Writer is a io.Writer wrapper that uses a diode to make Write lock-free,
 // non-blocking and thread safe.
 type Writer struct {
-	w    io.Writer
-	d    diodeFetcher
-	c    context.CancelFunc
-	done chan struct{}
+	w         io.Writer
+	d         diodeFetcher
+	c         context.CancelFunc
+	done      chan struct{}
+	a         Alerter
+	stats     *writerStats
+	statsDone chan struct{} // nil if a doesn't report stats
 }
 
 // NewWriter creates a writer wrapping w with a many-to-one diode in order to
@@ -49,17 +86,37 @@ type Writer struct {
 // used.
 //
 // See code.cloudfoundry.org/go-diodes for more info on diode.
-func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Writer {
+func NewWriter(w io.Writer, size int, pollInterval time.Duration, f AlertFunc) Writer {
+	if f == nil {
+		return NewWriterWithAlerter(w, size, pollInterval, nil)
+	}
+	return NewWriterWithAlerter(w, size, pollInterval, f)
+}
+
+// NewWriterWithAlerter creates a writer like NewWriter, reporting its
+// metrics to a, for instance to export them to Prometheus:
+//
+//	wr := diode.NewWriterWithAlerter(w, 1000, 10*time.Millisecond, alerter)
+//
+// The stats are reported every pollInterval, or every second if
+// pollInterval is 0.
+func NewWriterWithAlerter(w io.Writer, size int, pollInterval time.Duration, a Alerter) Writer {
 	ctx, cancel := context.WithCancel(context.Background())
-	dw := Writer{
-		w:    w,
-		c:    cancel,
-		done: make(chan struct{}),
+	if a == nil {
+		a = AlertFunc(func(int) {})
 	}
-	if f == nil {
-		f = func(int) {}
+	stats := &writerStats{}
+	dw := Writer{
+		w:     w,
+		c:     cancel,
+		done:  make(chan struct{}),
+		a:     a,
+		stats: stats,
 	}
-	d := diodes.NewManyToOne(size, diodes.AlertFunc(f))
+	d := diodes.NewManyToOne(size, diodes.AlertFunc(func(missed int) {
+		atomic.AddUint64(&stats.dropped, uint64(missed))
+		a.Missed(missed)
+	}))
 	if pollInterval > 0 {
 		dw.d = diodes.NewPoller(d,
 			diodes.WithPollingInterval(pollInterval),
@@ -68,6 +125,14 @@ func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Wri
 		dw.d = diodes.NewWaiter(d,
 			diodes.WithWaiterContext(ctx))
 	}
+	if _, ok := a.(AlertFunc); !ok {
+		interval := pollInterval
+		if interval <= 0 {
+			interval = statsInterval
+		}
+		dw.statsDone = make(chan struct{})
+		go dw.report(ctx, interval)
+	}
 	go dw.poll()
 	return dw
 }
@@ -75,8 +140,9 @@ func NewWriter(w io.Writer, size int, pollInterval time.Duration, f Alerter) Wri
 func (dw Writer) Write(p []byte) (n int, err error) {
 	// p is pooled in zerolog so we can't hold it passed this call, hence the
 	// copy.
-	p = append(bufPool.Get().([]byte), p...)
+	p = append(*bufPool.Get().(*[]byte), p...)
 	dw.d.Set(diodes.GenericDataType(&p))
+	atomic.AddUint64(&dw.stats.enqueued, 1)
 	return len(p), nil
 }
 
@@ -85,6 +151,10 @@ func (dw Writer) Write(p []byte) (n int, err error) {
 func (dw Writer) Close() error {
 	dw.c()
 	<-dw.done
+	if dw.statsDone != nil {
+		<-dw.statsDone
+		dw.reportStats()
+	}
 	if w, ok := dw.w.(io.Closer); ok {
 		return w.Close()
 	}
@@ -115,3 +185,22 @@ func (dw Writer) poll() {
 		}
 	}
 }
+
+// report reports the stats of dw every interval until ctx is done.
+func (dw Writer) report(ctx context.Context, interval time.Duration) {
+	defer close(dw.statsDone)
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			dw.reportStats()
+		}
+	}
+}
+
+func (dw Writer) reportStats() {
+	dw.a.Stats(atomic.LoadUint64(&dw.stats.enqueued), atomic.LoadUint64(&dw.stats.dropped))
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -66,3 +67,48 @@ func Benchmark(b *testing.B) {
 		})
 	}
 }
+
+type statsAlerter struct {
+	mu     sync.Mutex
+	missed int
+	stats  [][2]uint64
+}
+
+func (a *statsAlerter) Missed(count int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.missed += count
+}
+
+func (a *statsAlerter) Stats(enqueued, dropped uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stats = append(a.stats, [2]uint64{enqueued, dropped})
+}
+
+func TestWriterStats(t *testing.T) {
+	a := &statsAlerter{}
+	w := diode.NewWriterWithAlerter(io.Discard, 1000, time.Millisecond, a)
+	l := zerolog.New(w)
+	for i := 0; i < 10; i++ {
+		l.Print("test")
+		time.Sleep(2 * time.Millisecond)
+	}
+	handleErr(w.Close(), l, "Failed to close the diode writer")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.stats) < 2 {
+		t.Fatalf("Stats called %d times, want at least 2", len(a.stats))
+	}
+	var prev [2]uint64
+	for _, s := range a.stats {
+		if s[0] < prev[0] || s[1] < prev[1] {
+			t.Errorf("Stats counters decreased from %v to %v", prev, s)
+		}
+		prev = s
+	}
+	if want := [2]uint64{10, uint64(a.missed)}; prev != want {
+		t.Errorf("Stats on close = %v, want %v", prev, want)
+	}
+}
@@ -1,6 +1,7 @@
 package zerolog
 
 import (
+	"encoding/base64"
 	"net"
 	"time"
 )
@@ -9,6 +10,7 @@ type encoder interface {
 	AppendArrayDelim(dst []byte) []byte
 	AppendArrayEnd(dst []byte) []byte
 	AppendArrayStart(dst []byte) []byte
+	AppendBase64(dst, s []byte, enc *base64.Encoding) []byte
 	AppendBeginMarker(dst []byte) []byte
 	AppendBool(dst []byte, val bool) []byte
 	AppendBools(dst []byte, vals []bool) []byte
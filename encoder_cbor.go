@@ -6,6 +6,9 @@ package zerolog
 // This file contains bindings to do binary encoding.
 
 import (
+	"bytes"
+	"encoding/binary"
+
 	"github.com/x0f5c3/zerolog/internal/cbor"
 )
 
@@ -41,3 +44,46 @@ func decodeObjectToStr(in []byte) string {
 func decodeIfBinaryToBytes(in []byte) []byte {
 	return cbor.DecodeIfBinaryToBytes(in)
 }
+
+// decodeBinaryEvent returns the JSON rendering of in and true if in is a
+// binary event: it starts like one and decodes as CBOR.
+func decodeBinaryEvent(in []byte) ([]byte, bool) {
+	if len(in) == 0 || in[0] <= 0x7f {
+		return nil, false
+	}
+	var b bytes.Buffer
+	if err := cbor.ManyObjCBOR2JSON(bytes.NewReader(in), &b); err != nil {
+		return nil, false
+	}
+	return b.Bytes(), true
+}
+
+// cborSlotSize is the size of a slot: an integer with a 64 bits argument.
+const cborSlotSize = 9
+
+// appendSlot reserves a slot for a number in dst, see Event.Slot. Slots
+// always hold 64 bits integers so width is ignored.
+func appendSlot(dst []byte, width int) []byte {
+	return append(dst, make([]byte, cborSlotSize)...)
+}
+
+// putSlotInt64 writes val in slot. It always returns true.
+func putSlotInt64(slot []byte, val int64) bool {
+	if val < 0 {
+		putSlot(slot, 0x3b, uint64(-val-1)) // major type 1, 64 bits argument
+	} else {
+		putSlot(slot, 0x1b, uint64(val)) // major type 0, 64 bits argument
+	}
+	return true
+}
+
+// putSlotUint64 writes val in slot. It always returns true.
+func putSlotUint64(slot []byte, val uint64) bool {
+	putSlot(slot, 0x1b, val)
+	return true
+}
+
+func putSlot(slot []byte, head byte, val uint64) {
+	slot[0] = head
+	binary.BigEndian.PutUint64(slot[1:], val)
+}
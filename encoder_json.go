@@ -6,6 +6,8 @@ package zerolog
 // JSON encoded byte stream.
 
 import (
+	"strconv"
+
 	"github.com/x0f5c3/zerolog/internal/json"
 )
 
@@ -20,8 +22,22 @@ func init() {
 	json.MarshalFunc = func(v interface{}) ([]byte, error) {
 		return InterfaceMarshalFunc(v)
 	}
+	json.FloatPrecision = func() int {
+		if !FloatCompact {
+			return 0
+		}
+		if FloatingPointPrecision > 0 {
+			return FloatingPointPrecision
+		}
+		return floatCompactPrecision
+	}
 }
 
+// floatCompactPrecision is the number of significant digits of the floats
+// when FloatCompact is set without FloatingPointPrecision. It is the largest
+// number of digits a float64 always round-trips with.
+const floatCompactPrecision = 15
+
 func appendJSON(dst []byte, j []byte) []byte {
 	return append(dst, j...)
 }
@@ -37,3 +53,44 @@ func decodeObjectToStr(in []byte) string {
 func decodeIfBinaryToBytes(in []byte) []byte {
 	return in
 }
+
+// decodeBinaryEvent returns the JSON rendering of in and true if in is a
+// binary event. There is no binary event in JSON mode.
+func decodeBinaryEvent(in []byte) ([]byte, bool) {
+	return nil, false
+}
+
+// appendSlot reserves a slot of width bytes for a number in dst, see
+// Event.Slot.
+func appendSlot(dst []byte, width int) []byte {
+	for i := 0; i < width; i++ {
+		dst = append(dst, ' ')
+	}
+	return dst
+}
+
+// putSlotInt64 writes val right-aligned in slot, padded with spaces. It
+// returns false if val doesn't fit.
+func putSlotInt64(slot []byte, val int64) bool {
+	var b [20]byte
+	return putSlot(slot, strconv.AppendInt(b[:0], val, 10))
+}
+
+// putSlotUint64 writes val right-aligned in slot, padded with spaces. It
+// returns false if val doesn't fit.
+func putSlotUint64(slot []byte, val uint64) bool {
+	var b [20]byte
+	return putSlot(slot, strconv.AppendUint(b[:0], val, 10))
+}
+
+func putSlot(slot, num []byte) bool {
+	pad := len(slot) - len(num)
+	if pad < 0 {
+		return false
+	}
+	for i := 0; i < pad; i++ {
+		slot[i] = ' '
+	}
+	copy(slot[pad:], num)
+	return true
+}
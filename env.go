@@ -0,0 +1,134 @@
+package zerolog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Environment variables read by ConfigFromEnv.
+const (
+	// EnvLevel is the minimum level, as accepted by ParseLevel.
+	EnvLevel = "LOG_LEVEL"
+	// EnvFormat is the output format, either "json" (the default) or
+	// "console".
+	EnvFormat = "LOG_FORMAT"
+	// EnvTimeFormat is the timestamp format, as a time layout. In json
+	// format only, it can also be "unix", "unixms", "unixmicro" or
+	// "unixnano".
+	EnvTimeFormat = "LOG_TIME_FORMAT"
+	// EnvCaller adds the caller to the events when true.
+	EnvCaller = "LOG_CALLER"
+	// EnvColor enables or disables the colors in console format. When it
+	// is not set, colors are enabled unless disabled by NO_COLOR or an
+	// output which is not a terminal, see ConsoleWriter.NoColor.
+	EnvColor = "LOG_COLOR"
+)
+
+// EnvConfig is the configuration of the logger created by ConfigFromEnv.
+type EnvConfig struct {
+	// Out is the output of the logger, os.Stderr by default.
+	Out io.Writer
+
+	Level      Level
+	Console    bool
+	TimeFormat string
+	Caller     bool
+
+	// Color enables the colors in console format. Unless EnvColor is set,
+	// they are still disabled by NO_COLOR or an output which is not a
+	// terminal.
+	Color bool
+}
+
+// ConfigFromEnv creates a logger configured from the EnvLevel, EnvFormat,
+// EnvTimeFormat, EnvCaller and EnvColor environment variables. The options
+// are applied after the environment has been read, so they take precedence
+// over it. Events have a timestamp, and the level defaults to TraceLevel.
+//
+// An error is returned if any of the environment variables is invalid.
+func ConfigFromEnv(options ...func(c *EnvConfig)) (*Logger, error) {
+	c := EnvConfig{
+		Out:   os.Stderr,
+		Level: TraceLevel,
+		Color: true,
+	}
+	if v, ok := os.LookupEnv(EnvLevel); ok {
+		l, err := ParseLevel(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", EnvLevel, err)
+		}
+		c.Level = l
+	}
+	switch v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvFormat))); v {
+	case "", "json":
+	case "console":
+		c.Console = true
+	default:
+		return nil, fmt.Errorf("invalid %s: unknown format '%s'", EnvFormat, v)
+	}
+	c.TimeFormat = os.Getenv(EnvTimeFormat)
+	_, colorSet := os.LookupEnv(EnvColor)
+	for _, b := range []struct {
+		name string
+		v    *bool
+	}{
+		{EnvCaller, &c.Caller},
+		{EnvColor, &c.Color},
+	} {
+		if v, ok := os.LookupEnv(b.name); ok {
+			var err error
+			if *b.v, err = strconv.ParseBool(strings.TrimSpace(v)); err != nil {
+				return nil, fmt.Errorf("invalid %s: %v", b.name, err)
+			}
+		}
+	}
+
+	for _, opt := range options {
+		opt(&c)
+	}
+	if c.Console && envTimeFormat(c.TimeFormat) != c.TimeFormat {
+		return nil, fmt.Errorf("invalid %s: '%s' is not supported in console format", EnvTimeFormat, c.TimeFormat)
+	}
+
+	w := c.Out
+	if c.Console {
+		w = NewConsoleWriter(func(w *ConsoleWriter) {
+			w.Out = c.Out
+			if colorSet || !c.Color {
+				w.NoColor = !c.Color
+			} else {
+				w.NoColor = consoleDefaultNoColor(c.Out)
+			}
+			if c.TimeFormat != "" {
+				w.TimeFormat = c.TimeFormat
+			}
+		})
+	}
+	ctx := New(w).Level(c.Level).With().Timestamp()
+	if !c.Console && c.TimeFormat != "" {
+		ctx = ctx.TimestampFieldFormat(envTimeFormat(c.TimeFormat))
+	}
+	if c.Caller {
+		ctx = ctx.Caller()
+	}
+	return ctx.Logger(), nil
+}
+
+// envTimeFormat maps the UNIX time format names accepted in EnvTimeFormat to
+// their TimeFieldFormat value.
+func envTimeFormat(format string) string {
+	switch strings.ToLower(format) {
+	case "unix":
+		return TimeFormatUnix
+	case "unixms":
+		return TimeFormatUnixMs
+	case "unixmicro":
+		return TimeFormatUnixMicro
+	case "unixnano":
+		return TimeFormatUnixNano
+	}
+	return format
+}
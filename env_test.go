@@ -0,0 +1,118 @@
+//go:build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvLevel, " WARNING ")
+	t.Setenv(EnvTimeFormat, "unixms")
+	t.Setenv(EnvCaller, "true")
+	out := &bytes.Buffer{}
+	log, err := ConfigFromEnv(func(c *EnvConfig) {
+		c.Out = out
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log = log.WithClock(func() time.Time { return time.UnixMilli(1234) })
+	log.Info().Msg("filtered")
+	log.Warn().Msg("kept")
+	got := out.String()
+	want := `{"level":"warn","time":1234,"caller":"`
+	if !strings.HasPrefix(got, want) || !strings.Contains(got, `env_test.go:`) {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v...", got, want)
+	}
+}
+
+func TestConfigFromEnvConsole(t *testing.T) {
+	t.Setenv(EnvFormat, "console")
+	t.Setenv(EnvTimeFormat, time.DateOnly)
+	t.Setenv(EnvColor, "false")
+	out := &bytes.Buffer{}
+	log, err := ConfigFromEnv(func(c *EnvConfig) {
+		c.Out = out
+		c.Level = ErrorLevel
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log = log.WithClock(func() time.Time { return time.Date(2001, time.February, 3, 4, 5, 6, 0, time.Local) })
+	log.Warn().Msg("filtered")
+	log.Error().Msg("kept")
+	if got, want := out.String(), "2001-02-03 ERR kept\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestConfigFromEnvColor(t *testing.T) {
+	t.Setenv(EnvFormat, "console")
+	t.Setenv("NO_COLOR", "")
+	for _, tt := range []struct {
+		color string
+		want  string
+	}{
+		{"", " ERR kept\n"},
+		{"true", " \x1b[1;31mERR\x1b[0m kept\n"},
+	} {
+		t.Run(tt.color, func(t *testing.T) {
+			if tt.color != "" {
+				t.Setenv(EnvColor, tt.color)
+			}
+			f, err := os.CreateTemp(t.TempDir(), "log")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			log, err := ConfigFromEnv(func(c *EnvConfig) {
+				c.Out = f
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			log.Error().Msg("kept")
+			b, err := os.ReadFile(f.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(b); !strings.HasSuffix(got, tt.want) {
+				t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, "... "+tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnvConsoleUnixTime(t *testing.T) {
+	t.Setenv(EnvFormat, "console")
+	t.Setenv(EnvTimeFormat, "unixms")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("ConfigFromEnv() with a unix time format in console format should have failed")
+	}
+}
+
+func TestConfigFromEnvInvalidOrder(t *testing.T) {
+	t.Setenv(EnvCaller, "invalid")
+	t.Setenv(EnvColor, "invalid")
+	for i := 0; i < 10; i++ {
+		if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), EnvCaller) {
+			t.Fatalf("ConfigFromEnv() error = %v, want %s error", err, EnvCaller)
+		}
+	}
+}
+
+func TestConfigFromEnvInvalid(t *testing.T) {
+	for _, env := range []string{EnvLevel, EnvFormat, EnvCaller, EnvColor} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, "invalid")
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("ConfigFromEnv() with %s=invalid should have failed", env)
+			}
+		})
+	}
+}
@@ -1,10 +1,17 @@
 package zerolog
 
 import (
+	"context"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -25,8 +32,24 @@ type Event struct {
 	level     Level
 	done      func(msg string)
 	stack     bool   // enable error stack trace
+	stackKey  string // overrides ErrorStackFieldName if not empty
 	ch        []Hook // hooks from context
 	skipFrame int    // The number of additional frames to skip when printing the caller.
+
+	timeFormat    string              // format of time fields, see TimeFieldFormat
+	timestampFunc func() time.Time    // overrides TimestampFunc if not nil
+	tpl           *Template           // template built by the event, see Precompile
+	redactKeys    map[string]struct{} // keys whose values are redacted, see RedactHook
+	redactAudit   bool                // the redacted keys are listed, see RedactAuditHook
+	protected     []string            // keys redacted so far if redactAudit is true
+	ctx           context.Context     // context set with Ctx
+	pprofLabels   []string            // keys of the pprof labels of ctx to add, see Logger.WithPprofLabels
+	principal     string              // field of the principal of ctx, see Logger.RequirePrincipal
+	trace         *encoderTrace       // fields recorded if enabled, see TraceEncoder
+
+	depth     int  // number of containers around the fields being added
+	truncated bool // containers deeper than MaxNestingDepth were dropped
+	finished  bool // the event was sent, only set by VerifyMode
 }
 
 func putEvent(e *Event) {
@@ -37,6 +60,11 @@ func putEvent(e *Event) {
 	//
 	// See https://golang.org/issue/23199
 	const maxSize = 1 << 16 // 64KiB
+	if verifying() {
+		// Keep the event out of the pool to detect its reuse.
+		e.finished = true
+		return
+	}
 	if cap(e.buf) > maxSize {
 		return
 	}
@@ -64,6 +92,26 @@ func newEvent(w LevelWriter, level Level) *Event {
 	e.level = level
 	e.stack = false
 	e.skipFrame = 0
+	e.timeFormat = TimeFieldFormat
+	e.timestampFunc = nil
+	e.stackKey = ""
+	e.tpl = nil
+	e.redactKeys = nil
+	e.redactAudit = false
+	e.protected = e.protected[:0]
+	e.ctx = nil
+	e.pprofLabels = nil
+	e.principal = ""
+	e.trace = nil
+	if encoderTraceSink.Load() != nil {
+		e.trace = &encoderTrace{}
+	}
+	e.depth = 0
+	e.truncated = false
+	e.finished = false
+	if verifying() {
+		verifyTrack(e)
+	}
 	return e
 }
 
@@ -72,11 +120,17 @@ func (e *Event) write() (err error) {
 		return nil
 	}
 	if e.level != Disabled {
+		if e.trace != nil {
+			e.trace.close(0, len(e.buf))
+		}
 		e.buf = enc.AppendEndMarker(e.buf)
 		e.buf = enc.AppendLineBreak(e.buf)
 		if e.w != nil {
 			_, err = e.w.WriteLevel(e.level, e.buf)
 		}
+		if e.trace != nil {
+			e.trace.flush(e.buf)
+		}
 	}
 	putEvent(e)
 	return
@@ -97,10 +151,31 @@ func (e *Event) Discard() *Event {
 	return nil
 }
 
+// Ctx sets the context of the event, from which the pprof labels selected
+// with Logger.WithPprofLabels and the principal required with
+// Logger.RequirePrincipal are read.
+func (e *Event) Ctx(ctx context.Context) *Event {
+	if e == nil {
+		return e
+	}
+	e.ctx = ctx
+	return e
+}
+
+// GetCtx returns the context set with Ctx, or nil. While the hooks of the
+// event run, it returns a context marking them as running, to be set on the
+// events they log to detect their recursion, see HookRecursionWarnInterval.
+func (e *Event) GetCtx() context.Context {
+	if e == nil {
+		return nil
+	}
+	return e.ctx
+}
+
 // Msg sends the *Event with msg added as the message field if not empty.
 //
 // NOTICE: once this method is called, the *Event should be disposed.
-// Calling Msg twice can have unexpected result.
+// Calling Msg twice can have unexpected result, see VerifyMode to detect it.
 func (e *Event) Msg(msg string) {
 	if e == nil {
 		return
@@ -136,23 +211,125 @@ func (e *Event) MsgFunc(createMsg func() string) {
 	e.msg(createMsg())
 }
 
+// MsgKey sends the event with the message key and its params, as the
+// MessageKeyFieldName and MessageParamsFieldName fields, for the messages
+// identified by a stable key rather than by their text, like the translated
+// ones. The message field is rendered by MessageRenderer if set, and is
+// omitted otherwise.
+//
+// NOTICE: once this method is called, the *Event should be disposed.
+func (e *Event) MsgKey(key string, params map[string]string) {
+	if e == nil {
+		return
+	}
+	e.Str(MessageKeyFieldName, key)
+	if len(params) > 0 {
+		names := make([]string, 0, len(params))
+		for name := range params {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		dict := Dict()
+		for _, name := range names {
+			dict.Str(name, params[name])
+		}
+		e.Dict(MessageParamsFieldName, dict)
+	}
+	var msg string
+	if render := MessageRenderer; render != nil {
+		msg = render(key, params)
+	}
+	e.msg(msg)
+}
+
 func (e *Event) msg(msg string) {
-	for _, hook := range e.ch {
-		hook.Run(e, e.level, msg)
+	if e.finished && verifying() {
+		reportIssue(IssueMsgTwice, "", verifyCaller())
+		return
+	}
+	if e.ctx != nil {
+		for _, key := range e.pprofLabels {
+			if v, ok := pprof.Label(e.ctx, key); ok {
+				e.Str(key, v)
+			}
+		}
+		if e.principal != "" {
+			e.addPrincipal()
+		}
+	}
+	if len(e.ch) > 0 && hookRecursion(e.ctx, e.ch) {
+		e.Discard()
+	} else if len(e.ch) > 0 {
+		// The context seen by the hooks marks them as running, see
+		// hookRecursion.
+		ctx := e.ctx
+		e.ctx = withRunningHooks(ctx, e.ch)
+		for _, hook := range e.ch {
+			if f, ok := hook.(FilterHook); ok {
+				if !f.Filter(e, e.level, msg) {
+					e.Discard()
+					break
+				}
+				continue
+			}
+			hook.Run(e, e.level, msg)
+		}
+		e.ctx = ctx
+	}
+	if len(e.protected) > 0 {
+		if e.trace != nil {
+			e.traceSpan("", ProtectedFieldName)
+		}
+		e.buf = enc.AppendStrings(enc.AppendKey(e.buf, ProtectedFieldName), e.protected)
+	}
+	if e.truncated {
+		if e.trace != nil {
+			e.traceSpan("", NestingTruncatedFieldName)
+		}
+		e.buf = enc.AppendBool(enc.AppendKey(e.buf, NestingTruncatedFieldName), true)
 	}
 	if msg != "" {
+		if e.trace != nil {
+			e.traceSpan("", MessageFieldName)
+		}
 		e.buf = enc.AppendString(enc.AppendKey(e.buf, MessageFieldName), msg)
 	}
 	if e.done != nil {
 		defer e.done(msg)
 	}
 	if err := e.write(); err != nil {
-		if ErrorHandler != nil {
-			ErrorHandler(err)
-		} else {
-			fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
+		handleWriteError(err)
+	}
+}
+
+// addPrincipal adds the principal of the context of e, or handles its
+// absence according to PrincipalMissingHandling.
+func (e *Event) addPrincipal() {
+	if extract := PrincipalExtractor; extract != nil {
+		if p, ok := extract(e.ctx); ok {
+			e.Str(e.principal, p)
+			return
 		}
 	}
+	if levelLess(e.level, PrincipalMissingLevel) {
+		return
+	}
+	switch PrincipalMissingHandling {
+	case PrincipalMissingDrop:
+		e.Discard()
+	default:
+		e.Bool(PrincipalMissingFieldName, true)
+	}
+}
+
+// handleWriteError reports err, returned by the writer of an event, to
+// ErrorHandler or to stderr.
+func handleWriteError(err error) {
+	if ErrorHandler != nil {
+		ErrorHandler(err)
+	} else {
+		fmt.Fprintf(os.Stderr, "zerolog: could not write event: %v\n", err)
+	}
 }
 
 // Fields is a helper function to use a map or slice to set fields using type assertion.
@@ -162,7 +339,13 @@ func (e *Event) Fields(fields interface{}) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = appendFields(e.buf, fields)
+	if e.finished && verifying() {
+		reportIssue(IssueFieldAfterSend, "", verifyCaller())
+	}
+	if e.redactKeys != nil {
+		fields = e.redactFields(fields)
+	}
+	e.buf = appendFields(e.buf, fields, e.timeFormat)
 	return e
 }
 
@@ -172,8 +355,20 @@ func (e *Event) Dict(key string, dict *Event) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		putEvent(dict)
+		return e
+	}
+	e.buf = enc.AppendKey(e.buf, key)
+	if e.trace != nil && dict.trace != nil {
+		e.trace.merge(dict, len(e.buf), e.depth+1)
+	}
 	dict.buf = enc.AppendEndMarker(dict.buf)
-	e.buf = append(enc.AppendKey(e.buf, key), dict.buf...)
+	e.buf = append(e.buf, dict.buf...)
+	e.truncated = e.truncated || dict.truncated
+	for _, k := range dict.protected {
+		e.protect(k)
+	}
 	putEvent(dict)
 	return e
 }
@@ -185,6 +380,41 @@ func Dict() *Event {
 	return newEvent(nil, 0)
 }
 
+// DictFn adds the field key with a dict built by fn to the event context.
+// Unlike Dict, the dict is only allocated, and fn only called, if the event
+// is enabled. If fn panics, the partial dict is discarded: the field is an
+// empty dict followed by the MarshalPanicFieldName field with the recovered
+// value, and the event remains valid.
+func (e *Event) DictFn(key string, fn func(d *Event)) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) || !e.nest() {
+		return e
+	}
+	dict := newEvent(nil, 0)
+	dict.timeFormat = e.timeFormat
+	dict.redactKeys = e.redactKeys
+	dict.redactAudit = e.redactAudit
+	dict.depth = e.depth + 1
+	if p := runDictFn(fn, dict); p != nil {
+		putEvent(dict)
+		e.buf = enc.AppendEndMarker(enc.AppendBeginMarker(enc.AppendKey(e.buf, key)))
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, MarshalPanicFieldName), fmt.Sprint(p))
+		return e
+	}
+	return e.Dict(key, dict)
+}
+
+// runDictFn calls fn with d and returns the value it panicked with, if any.
+func runDictFn(fn func(d *Event), d *Event) (p interface{}) {
+	defer func() {
+		p = recover()
+	}()
+	fn(d)
+	return nil
+}
+
 // Array adds the field key with an array to the event context.
 // Use zerolog.Arr() to create the array or pass a type that
 // implement the LogArrayMarshaler interface.
@@ -192,21 +422,138 @@ func (e *Event) Array(key string, arr LogArrayMarshaler) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendKey(e.buf, key)
+	if e.redacted(key) {
+		if a, ok := arr.(*Array); ok {
+			putArray(a)
+		}
+		return e
+	}
 	var a *Array
 	if aa, ok := arr.(*Array); ok {
 		a = aa
 	} else {
+		if !e.nest() {
+			return e
+		}
 		a = Arr()
+		a.depth = e.depth + 1
 		arr.MarshalZerologArray(a)
 	}
-	e.buf = a.write(e.buf)
+	e.truncated = e.truncated || a.truncated
+	e.buf = a.write(enc.AppendKey(e.buf, key))
+	return e
+}
+
+// ArrayFn adds the field key with an array built by fn to the event context.
+// Unlike Array, the array is only allocated, and fn only called, if the
+// event is enabled. If fn panics, the partial array is discarded: the field
+// is an empty array followed by the MarshalPanicFieldName field with the
+// recovered value, and the event remains valid.
+func (e *Event) ArrayFn(key string, fn func(a *Array)) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) || !e.nest() {
+		return e
+	}
+	a := Arr()
+	a.depth = e.depth + 1
+	if p := runArrayFn(fn, a); p != nil {
+		putArray(a)
+		e.buf = enc.AppendArrayEnd(enc.AppendArrayStart(enc.AppendKey(e.buf, key)))
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, MarshalPanicFieldName), fmt.Sprint(p))
+		return e
+	}
+	e.truncated = e.truncated || a.truncated
+	e.buf = a.write(enc.AppendKey(e.buf, key))
 	return e
 }
 
+// runArrayFn calls fn with a and returns the value it panicked with, if any.
+func runArrayFn(fn func(a *Array), a *Array) (p interface{}) {
+	defer func() {
+		p = recover()
+	}()
+	fn(a)
+	return nil
+}
+
+// truncatedArray adds the field key with an array of the first max of n
+// elements, each appended by add, followed by an element giving the number
+// of the dropped ones if n is above max. It is shared by StrsN, IntsN and
+// InterfacesN.
+func (e *Event) truncatedArray(key string, n, max int, add func(a *Array, i int)) *Event {
+	if e.redacted(key) {
+		return e
+	}
+	if max < 0 {
+		max = 0
+	}
+	a := Arr()
+	a.depth = e.depth + 1
+	for i := 0; i < n && i < max; i++ {
+		add(a, i)
+	}
+	if n > max {
+		a.Str("...(+" + strconv.Itoa(n-max) + " more)")
+	}
+	e.truncated = e.truncated || a.truncated
+	e.buf = a.write(enc.AppendKey(e.buf, key))
+	return e
+}
+
+// redacted appends the field key with the redacted placeholder and returns
+// true if key is redacted by a RedactHook of the event. As all the field
+// methods call it, it also reports the fields added after Send to
+// VerifyMode.
+func (e *Event) redacted(key string) bool {
+	if e.finished && verifying() {
+		reportIssue(IssueFieldAfterSend, key, verifyCaller())
+	}
+	if e.trace != nil {
+		e.traceSpan("", key)
+	}
+	if e.redactKeys == nil {
+		return false
+	}
+	if _, ok := e.redactKeys[key]; !ok {
+		return false
+	}
+	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), redactedValue)
+	e.protect(key)
+	return true
+}
+
+// protect adds key to the redacted keys listed by the event if it lists
+// them.
+func (e *Event) protect(key string) {
+	if !e.redactAudit || len(e.protected) >= maxProtectedKeys {
+		return
+	}
+	for _, k := range e.protected {
+		if k == key {
+			return
+		}
+	}
+	e.protected = append(e.protected, key)
+}
+
+// nest returns true if a container can be added at the depth of the event,
+// or marks the event as truncated.
+func (e *Event) nest() bool {
+	if e.depth >= MaxNestingDepth {
+		e.truncated = true
+		return false
+	}
+	return true
+}
+
 func (e *Event) appendObject(obj LogObjectMarshaler) {
 	e.buf = enc.AppendBeginMarker(e.buf)
 	obj.MarshalZerologObject(e)
+	if e.trace != nil {
+		e.trace.close(e.depth, len(e.buf))
+	}
 	e.buf = enc.AppendEndMarker(e.buf)
 }
 
@@ -215,14 +562,54 @@ func (e *Event) Object(key string, obj LogObjectMarshaler) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendKey(e.buf, key)
+	if e.redacted(key) {
+		return e
+	}
 	if obj == nil {
-		e.buf = enc.AppendNil(e.buf)
-
+		e.buf = enc.AppendNil(enc.AppendKey(e.buf, key))
+		return e
+	}
+	if !e.nest() {
 		return e
 	}
 
+	e.buf = enc.AppendKey(e.buf, key)
+	e.depth++
 	e.appendObject(obj)
+	e.depth--
+	return e
+}
+
+// Objects adds the field key with an array of objs, each marshaled like
+// Object. A nil slice is logged as an empty array and nil elements as null.
+func (e *Event) Objects(key string, objs []LogObjectMarshaler) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) || !e.nest() {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	e.depth++
+	n := 0
+	for _, obj := range objs {
+		if obj != nil && !isNilValue(obj) && !e.nest() {
+			continue
+		}
+		if n > 0 {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		n++
+		if obj == nil || isNilValue(obj) {
+			e.buf = enc.AppendNil(e.buf)
+			continue
+		}
+		e.depth++
+		e.appendObject(obj)
+		e.depth--
+	}
+	e.depth--
+	e.buf = enc.AppendArrayEnd(e.buf)
 	return e
 }
 
@@ -251,6 +638,9 @@ func (e *Event) Str(key, val string) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendString(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -260,16 +650,35 @@ func (e *Event) Strs(key string, vals []string) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendStrings(enc.AppendKey(e.buf, key), vals)
 	return e
 }
 
+// StrsN adds the field key with at most max of vals to the *Event context.
+// If vals has more elements, a last element like "...(+3 more)" gives the
+// number of the dropped ones. It bounds the size of the events logging
+// slices of unknown length, like user supplied tags.
+func (e *Event) StrsN(key string, vals []string, max int) *Event {
+	if e == nil || len(vals) <= max {
+		return e.Strs(key, vals)
+	}
+	return e.truncatedArray(key, len(vals), max, func(a *Array, i int) {
+		a.Str(vals[i])
+	})
+}
+
 // Stringer adds the field key with val.String() (or null if val is nil)
 // to the *Event context.
 func (e *Event) Stringer(key string, val fmt.Stringer) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendStringer(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -281,6 +690,9 @@ func (e *Event) Stringers(key string, vals []fmt.Stringer) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendStringers(enc.AppendKey(e.buf, key), vals)
 	return e
 }
@@ -293,6 +705,9 @@ func (e *Event) Bytes(key string, val []byte) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendBytes(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -302,10 +717,31 @@ func (e *Event) Hex(key string, val []byte) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendHex(enc.AppendKey(e.buf, key), val)
 	return e
 }
 
+// Base64Bytes adds the field key with val as a base64 string encoded with
+// b64, base64.StdEncoding if nil, to the *Event context. The binary format
+// stores the raw bytes, rendered as base64url for base64.URLEncoding and
+// base64.RawURLEncoding, and as padded base64 otherwise.
+func (e *Event) Base64Bytes(key string, val []byte, b64 *base64.Encoding) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	if b64 == nil {
+		b64 = base64.StdEncoding
+	}
+	e.buf = enc.AppendBase64(enc.AppendKey(e.buf, key), val, b64)
+	return e
+}
+
 // RawJSON adds already encoded JSON to the log line under key.
 //
 // No sanity check is performed on b; it must not contain carriage returns and
@@ -314,6 +750,9 @@ func (e *Event) RawJSON(key string, b []byte) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = appendJSON(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -324,6 +763,9 @@ func (e *Event) AnErr(key string, err error) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	switch m := ErrorMarshalFunc(err).(type) {
 	case nil:
 		return e
@@ -343,11 +785,15 @@ func (e *Event) AnErr(key string, err error) *Event {
 }
 
 // Errs adds the field key with errs as an array of serialized errors to the
-// *Event context.
+// *Event context. Like with Err, the errors implementing LogObjectMarshaler
+// are added as objects.
 func (e *Event) Errs(key string, errs []error) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	arr := Arr()
 	for _, err := range errs {
 		switch m := ErrorMarshalFunc(err).(type) {
@@ -366,30 +812,37 @@ func (e *Event) Errs(key string, errs []error) *Event {
 }
 
 // Err adds the field "error" with serialized err to the *Event context.
-// If err is nil, no field is added.
+// If err is nil, no field is added. With the default ErrorMarshalFunc, err
+// is added as an object if it implements LogObjectMarshaler, to log the
+// fields of rich errors, like a code, and as its message otherwise.
 //
 // To customize the key name, change zerolog.ErrorFieldName.
 //
 // If Stack() has been called before and zerolog.ErrorStackMarshaler is defined,
 // the err is passed to ErrorStackMarshaler and the result is appended to the
-// zerolog.ErrorStackFieldName.
+// zerolog.ErrorStackFieldName, or to the field name set with
+// Context.ErrorStackFieldName.
 func (e *Event) Err(err error) *Event {
 	if e == nil {
 		return e
 	}
 	if e.stack && ErrorStackMarshaler != nil {
+		key := e.stackKey
+		if key == "" {
+			key = ErrorStackFieldName
+		}
 		switch m := ErrorStackMarshaler(err).(type) {
 		case nil:
 		case LogObjectMarshaler:
-			e.Object(ErrorStackFieldName, m)
+			e.Object(key, m)
 		case error:
 			if m != nil && !isNilValue(m) {
-				e.Str(ErrorStackFieldName, m.Error())
+				e.Str(key, m.Error())
 			}
 		case string:
-			e.Str(ErrorStackFieldName, m)
+			e.Str(key, m)
 		default:
-			e.Interface(ErrorStackFieldName, m)
+			e.Interface(key, m)
 		}
 	}
 	return e.AnErr(ErrorFieldName, err)
@@ -410,6 +863,9 @@ func (e *Event) Bool(key string, b bool) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendBool(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -419,6 +875,9 @@ func (e *Event) Bools(key string, b []bool) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendBools(enc.AppendKey(e.buf, key), b)
 	return e
 }
@@ -428,6 +887,9 @@ func (e *Event) Int(key string, i int) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInt(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -437,15 +899,32 @@ func (e *Event) Ints(key string, i []int) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInts(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
+// IntsN adds the field key with at most max of i to the *Event context,
+// like StrsN.
+func (e *Event) IntsN(key string, i []int, max int) *Event {
+	if e == nil || len(i) <= max {
+		return e.Ints(key, i)
+	}
+	return e.truncatedArray(key, len(i), max, func(a *Array, n int) {
+		a.Int(i[n])
+	})
+}
+
 // Int8 adds the field key with i as a int8 to the *Event context.
 func (e *Event) Int8(key string, i int8) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInt8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -455,6 +934,9 @@ func (e *Event) Ints8(key string, i []int8) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInts8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -464,6 +946,9 @@ func (e *Event) Int16(key string, i int16) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInt16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -473,6 +958,9 @@ func (e *Event) Ints16(key string, i []int16) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInts16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -482,6 +970,9 @@ func (e *Event) Int32(key string, i int32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInt32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -491,6 +982,9 @@ func (e *Event) Ints32(key string, i []int32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInts32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -500,6 +994,9 @@ func (e *Event) Int64(key string, i int64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -509,6 +1006,9 @@ func (e *Event) Ints64(key string, i []int64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendInts64(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -518,6 +1018,9 @@ func (e *Event) Uint(key string, i uint) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUint(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -527,6 +1030,9 @@ func (e *Event) Uints(key string, i []uint) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUints(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -536,6 +1042,9 @@ func (e *Event) Uint8(key string, i uint8) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUint8(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -545,15 +1054,27 @@ func (e *Event) Uints8(key string, i []uint8) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUints8(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
+// Byte adds the field key with b as a number to the *Event context, like
+// Uint8, byte being an alias of uint8.
+func (e *Event) Byte(key string, b byte) *Event {
+	return e.Uint8(key, b)
+}
+
 // Uint16 adds the field key with i as a uint16 to the *Event context.
 func (e *Event) Uint16(key string, i uint16) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUint16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -563,6 +1084,9 @@ func (e *Event) Uints16(key string, i []uint16) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUints16(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -572,6 +1096,9 @@ func (e *Event) Uint32(key string, i uint32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUint32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -581,6 +1108,9 @@ func (e *Event) Uints32(key string, i []uint32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUints32(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -590,6 +1120,9 @@ func (e *Event) Uint64(key string, i uint64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUint64(enc.AppendKey(e.buf, key), i)
 	return e
 }
@@ -599,15 +1132,34 @@ func (e *Event) Uints64(key string, i []uint64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendUints64(enc.AppendKey(e.buf, key), i)
 	return e
 }
 
+// Uintptr adds the field key with p as a number to the *Event context, like
+// a handle or an address.
+func (e *Event) Uintptr(key string, p uintptr) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendUint64(enc.AppendKey(e.buf, key), uint64(p))
+	return e
+}
+
 // Float32 adds the field key with f as a float32 to the *Event context.
 func (e *Event) Float32(key string, f float32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendFloat32(enc.AppendKey(e.buf, key), f)
 	return e
 }
@@ -617,6 +1169,9 @@ func (e *Event) Floats32(key string, f []float32) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendFloats32(enc.AppendKey(e.buf, key), f)
 	return e
 }
@@ -626,6 +1181,9 @@ func (e *Event) Float64(key string, f float64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendFloat64(enc.AppendKey(e.buf, key), f)
 	return e
 }
@@ -635,6 +1193,9 @@ func (e *Event) Floats64(key string, f []float64) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendFloats64(enc.AppendKey(e.buf, key), f)
 	return e
 }
@@ -648,25 +1209,64 @@ func (e *Event) Timestamp() *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, TimestampFieldName), TimestampFunc(), TimeFieldFormat)
+	e.buf = enc.AppendTime(enc.AppendKey(e.buf, TimestampFieldName), e.now(), e.timeFormat)
 	return e
 }
 
-// Time adds the field key with t formatted as string using zerolog.TimeFieldFormat.
+// now returns the current time according to the timestamp function of the
+// event.
+func (e *Event) now() time.Time {
+	if e.timestampFunc != nil {
+		return e.timestampFunc()
+	}
+	return TimestampFunc()
+}
+
+// Time adds the field key with t formatted as string using zerolog.TimeFieldFormat,
+// or the format set with Context.TimestampFieldFormat on the logger.
 func (e *Event) Time(key string, t time.Time) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTime(enc.AppendKey(e.buf, key), t, TimeFieldFormat)
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendTime(enc.AppendKey(e.buf, key), t, e.timeFormat)
 	return e
 }
 
-// Times adds the field key with t formatted as string using zerolog.TimeFieldFormat.
+// Times adds the field key with t formatted as string using zerolog.TimeFieldFormat,
+// or the format set with Context.TimestampFieldFormat on the logger.
 func (e *Event) Times(key string, t []time.Time) *Event {
 	if e == nil {
 		return e
 	}
-	e.buf = enc.AppendTimes(enc.AppendKey(e.buf, key), t, TimeFieldFormat)
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendTimes(enc.AppendKey(e.buf, key), t, e.timeFormat)
+	return e
+}
+
+// TimesUnix adds the field key with t as an array of Unix timestamps in
+// seconds, whatever zerolog.TimeFieldFormat. They are integers in JSON, and
+// integer timestamps with the standard time tag in the binary format. Nil
+// and empty slices are added as empty arrays.
+func (e *Event) TimesUnix(key string, t []time.Time) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	for i, ti := range t {
+		if i > 0 {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		e.buf = enc.AppendTime(e.buf, ti.Truncate(time.Second), TimeFormatUnix)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
 	return e
 }
 
@@ -679,6 +1279,9 @@ func (e *Event) Dur(key string, d time.Duration) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
 	return e
 }
@@ -692,6 +1295,9 @@ func (e *Event) Durs(key string, d []time.Duration) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendDurations(enc.AppendKey(e.buf, key), d, DurationFieldUnit, DurationFieldInteger)
 	return e
 }
@@ -699,12 +1305,18 @@ func (e *Event) Durs(key string, d []time.Duration) *Event {
 // TimeDiff adds the field key with positive duration between time t and start.
 // If time t is not greater than start, duration will be 0.
 // Duration format follows the same principle as Dur().
+// As with time.Since, the duration is measured with the monotonic clock when
+// both t and start have a monotonic reading, so it is not affected by the
+// steps of the wall clock. Times which were serialized lost their reading.
 //
 //goland:noinspection GoBoolExpressions
 func (e *Event) TimeDiff(key string, t time.Time, start time.Time) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	var d time.Duration
 	if t.After(start) {
 		d = t.Sub(start)
@@ -713,8 +1325,190 @@ func (e *Event) TimeDiff(key string, t time.Time, start time.Time) *Event {
 	return e
 }
 
-// Any is a wrapper around Event.Interface.
+// Since adds the field key with the positive duration elapsed since start,
+// like TimeDiff with the current time of the event's clock, see
+// Logger.WithClock, for instance to log the latency at the end of a handler.
+func (e *Event) Since(key string, start time.Time) *Event {
+	if e == nil {
+		return e
+	}
+	return e.TimeDiff(key, e.now(), start)
+}
+
+// DurMs adds the field key_ms with duration d in milliseconds, see
+// UnitFieldSuffix. If zerolog.DurationFieldInteger is true, the duration is
+// rendered as integer instead of float.
+func (e *Event) DurMs(key string, d time.Duration) *Event {
+	return e.durUnit(key, "ms", d, time.Millisecond)
+}
+
+// DurUs adds the field key_us with duration d in microseconds, see
+// UnitFieldSuffix. If zerolog.DurationFieldInteger is true, the duration is
+// rendered as integer instead of float.
+func (e *Event) DurUs(key string, d time.Duration) *Event {
+	return e.durUnit(key, "us", d, time.Microsecond)
+}
+
+// DurS adds the field key_s with duration d in seconds, see UnitFieldSuffix.
+// If zerolog.DurationFieldInteger is true, the duration is rendered as
+// integer instead of float.
+func (e *Event) DurS(key string, d time.Duration) *Event {
+	return e.durUnit(key, "s", d, time.Second)
+}
+
+func (e *Event) durUnit(key, suffix string, d, unit time.Duration) *Event {
+	if e == nil {
+		return e
+	}
+	key = unitKey(key, suffix)
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendDuration(enc.AppendKey(e.buf, key), d, unit, DurationFieldInteger)
+	return e
+}
+
+// ByteSizeBytes adds the field key_bytes with size n in bytes, see
+// UnitFieldSuffix.
+func (e *Event) ByteSizeBytes(key string, n int64) *Event {
+	if e == nil {
+		return e
+	}
+	key = unitKey(key, "bytes")
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendInt64(enc.AppendKey(e.buf, key), n)
+	return e
+}
+
+// ByteSizeMiB adds the field key_mib with size n, in bytes, converted to
+// mebibytes as float, see UnitFieldSuffix.
+func (e *Event) ByteSizeMiB(key string, n int64) *Event {
+	if e == nil {
+		return e
+	}
+	key = unitKey(key, "mib")
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendFloat64(enc.AppendKey(e.buf, key), float64(n)/(1<<20))
+	return e
+}
+
+// unitKey returns key suffixed with unit if UnitFieldSuffix is true.
+func unitKey(key, unit string) string {
+	if !UnitFieldSuffix {
+		return key
+	}
+	return key + UnitFieldSeparator + unit
+}
+
+// Any adds the field key with i, using the dedicated field method of its
+// concrete type when there is one and falling back to Interface otherwise.
+// Errors are added like AnErr, json.Marshaler values like Interface, and
+// encoding.TextMarshaler and fmt.Stringer values as strings. Nil values,
+// including typed nil pointers, are added as null.
+//
+// Any is the recommended way to add a value of unknown type as it avoids the
+// reflection based marshaling of Interface for all the common types.
 func (e *Event) Any(key string, i interface{}) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	switch v := i.(type) {
+	case nil:
+		e.buf = enc.AppendNil(enc.AppendKey(e.buf, key))
+		return e
+	case string:
+		return e.Str(key, v)
+	case []byte:
+		return e.Bytes(key, v)
+	case bool:
+		return e.Bool(key, v)
+	case int:
+		return e.Int(key, v)
+	case int8:
+		return e.Int8(key, v)
+	case int16:
+		return e.Int16(key, v)
+	case int32:
+		return e.Int32(key, v)
+	case int64:
+		return e.Int64(key, v)
+	case uint:
+		return e.Uint(key, v)
+	case uint8:
+		return e.Uint8(key, v)
+	case uint16:
+		return e.Uint16(key, v)
+	case uint32:
+		return e.Uint32(key, v)
+	case uint64:
+		return e.Uint64(key, v)
+	case float32:
+		return e.Float32(key, v)
+	case float64:
+		return e.Float64(key, v)
+	case time.Time:
+		return e.Time(key, v)
+	case time.Duration:
+		return e.Dur(key, v)
+	case []string:
+		return e.Strs(key, v)
+	case []bool:
+		return e.Bools(key, v)
+	case []int:
+		return e.Ints(key, v)
+	case []int64:
+		return e.Ints64(key, v)
+	case []uint64:
+		return e.Uints64(key, v)
+	case []float64:
+		return e.Floats64(key, v)
+	case []time.Time:
+		return e.Times(key, v)
+	case []time.Duration:
+		return e.Durs(key, v)
+	case []error:
+		return e.Errs(key, v)
+	case net.IP:
+		return e.IPAddr(key, v)
+	case net.IPNet:
+		return e.IPPrefix(key, v)
+	case net.HardwareAddr:
+		return e.MACAddr(key, v)
+	case LogObjectMarshaler:
+		return e.Object(key, v)
+	case LogArrayMarshaler:
+		return e.Array(key, v)
+	case error:
+		if isNilValue(v) {
+			e.buf = enc.AppendNil(enc.AppendKey(e.buf, key))
+			return e
+		}
+		return e.AnErr(key, v)
+	case json.Marshaler:
+		return e.Interface(key, v)
+	case encoding.TextMarshaler:
+		if isNilValue(v) {
+			break
+		}
+		b, err := v.MarshalText()
+		if err != nil {
+			break
+		}
+		e.buf = enc.AppendString(enc.AppendKey(e.buf, key), string(b))
+		return e
+	case fmt.Stringer:
+		if isNilValue(v) {
+			break
+		}
+		return e.Stringer(key, v)
+	}
 	return e.Interface(key, i)
 }
 
@@ -723,6 +1517,9 @@ func (e *Event) Interface(key string, i interface{}) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	if obj, ok := i.(LogObjectMarshaler); ok {
 		return e.Object(key, obj)
 	}
@@ -730,11 +1527,25 @@ func (e *Event) Interface(key string, i interface{}) *Event {
 	return e
 }
 
+// InterfacesN adds the field key with at most max of vals, each marshaled
+// like with Interface, to the *Event context, like StrsN.
+func (e *Event) InterfacesN(key string, vals []interface{}, max int) *Event {
+	if e == nil {
+		return e
+	}
+	return e.truncatedArray(key, len(vals), max, func(a *Array, i int) {
+		a.Interface(vals[i])
+	})
+}
+
 // Type adds the field key with val's type using reflection.
 func (e *Event) Type(key string, val interface{}) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendType(enc.AppendKey(e.buf, key), val)
 	return e
 }
@@ -777,6 +1588,9 @@ func (e *Event) IPAddr(key string, ip net.IP) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendIPAddr(enc.AppendKey(e.buf, key), ip)
 	return e
 }
@@ -786,6 +1600,9 @@ func (e *Event) IPPrefix(key string, pfx net.IPNet) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendIPPrefix(enc.AppendKey(e.buf, key), pfx)
 	return e
 }
@@ -795,6 +1612,9 @@ func (e *Event) MACAddr(key string, ha net.HardwareAddr) *Event {
 	if e == nil {
 		return e
 	}
+	if e.redacted(key) {
+		return e
+	}
 	e.buf = enc.AppendMACAddr(enc.AppendKey(e.buf, key), ha)
 	return e
 }
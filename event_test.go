@@ -5,8 +5,11 @@ package zerolog
 import (
 	"bytes"
 	"errors"
+	"math/big"
+	"net"
 	"strings"
 	"testing"
+	"time"
 )
 
 type nilError struct{}
@@ -51,6 +54,29 @@ func TestEvent_ObjectWithNil(t *testing.T) {
 	}
 }
 
+type nilObjectMarshaler struct{}
+
+func (*nilObjectMarshaler) MarshalZerologObject(e *Event) {
+	panic("MarshalZerologObject called on nil")
+}
+
+func TestEvent_Objects(t *testing.T) {
+	var buf bytes.Buffer
+	e := newEvent(levelWriterAdapter{&buf}, DebugLevel)
+	var nilObj *nilObjectMarshaler
+	_ = e.Objects("objs", []LogObjectMarshaler{obj{"a", "b", 1}, obj{"c", "d", 2}}).
+		Objects("nils", []LogObjectMarshaler{nil, nilObj, obj{"e", "f", 3}}).
+		Objects("empty", nil)
+	_ = e.write()
+
+	want := `{"objs":[{"Pub":"a","Tag":"b","priv":1},{"Pub":"c","Tag":"d","priv":2}],` +
+		`"nils":[null,null,{"Pub":"e","Tag":"f","priv":3}],"empty":[]}`
+	got := strings.TrimSpace(buf.String())
+	if got != want {
+		t.Errorf("Event.Objects() = %q, want %q", got, want)
+	}
+}
+
 func TestEvent_EmbedObjectWithNil(t *testing.T) {
 	var buf bytes.Buffer
 	e := newEvent(levelWriterAdapter{&buf}, DebugLevel)
@@ -63,3 +89,139 @@ func TestEvent_EmbedObjectWithNil(t *testing.T) {
 		t.Errorf("Event.EmbedObject() = %q, want %q", got, want)
 	}
 }
+
+type textMarshaler struct{}
+
+func (textMarshaler) MarshalText() ([]byte, error) {
+	return []byte("text"), nil
+}
+
+type stringer struct{}
+
+func (stringer) String() string {
+	return "string"
+}
+
+type jsonStringer struct{}
+
+func (jsonStringer) MarshalJSON() ([]byte, error) {
+	return []byte(`{"json":true}`), nil
+}
+
+func (jsonStringer) String() string {
+	return "string"
+}
+
+type plain struct {
+	A int
+}
+
+func TestEvent_Any(t *testing.T) {
+	tests := []struct {
+		name string
+		val  interface{}
+		want string
+	}{
+		{"nil", nil, `{"any":null}`},
+		{"string", "foo", `{"any":"foo"}`},
+		{"bytes", []byte("foo"), `{"any":"foo"}`},
+		{"bool", true, `{"any":true}`},
+		{"int", 42, `{"any":42}`},
+		{"uint8", uint8(42), `{"any":42}`},
+		{"float64", 4.2, `{"any":4.2}`},
+		{"time", time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC), `{"any":"2001-02-03T04:05:06Z"}`},
+		{"duration", 2 * time.Second, `{"any":2000}`},
+		{"strings", []string{"a", "b"}, `{"any":["a","b"]}`},
+		{"ip", net.IP{127, 0, 0, 1}, `{"any":"127.0.0.1"}`},
+		{"error", errors.New("test"), `{"any":"test"}`},
+		{"nil error", func() *nilError { return nil }(), `{"any":null}`},
+		{"json marshaler", big.NewInt(123), `{"any":123}`},
+		{"json marshaler and stringer", jsonStringer{}, `{"any":{"json":true}}`},
+		{"object", obj{"a", "b", 1}, `{"any":{"Pub":"a","Tag":"b","priv":1}}`},
+		{"text marshaler", textMarshaler{}, `{"any":"text"}`},
+		{"stringer", stringer{}, `{"any":"string"}`},
+		{"nil stringer", (*stringer)(nil), `{"any":null}`},
+		{"struct", plain{1}, `{"any":{"A":1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			e := newEvent(levelWriterAdapter{&buf}, DebugLevel)
+			e.Any("any", tt.val)
+			_ = e.write()
+			if got, want := strings.TrimSpace(buf.String()), tt.want; got != want {
+				t.Errorf("Event.Any() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvent_DictFn(t *testing.T) {
+	var buf bytes.Buffer
+	log := New(&buf)
+	log.Log().
+		DictFn("ok", func(d *Event) { d.Str("a", "b").Int("n", 1) }).
+		DictFn("panic", func(d *Event) { d.Str("partial", "x"); panic("boom") }).
+		ArrayFn("arr", func(a *Array) { a.Str("a").Int(1) }).
+		ArrayFn("arr_panic", func(a *Array) { a.Str("partial"); panic(errors.New("bang")) }).
+		Str("after", "ok").
+		Msg("")
+
+	want := `{"ok":{"a":"b","n":1},"panic":{},"marshal_panic":"boom","arr":["a",1],"arr_panic":[],"marshal_panic":"bang","after":"ok"}`
+	if got := strings.TrimSpace(buf.String()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestEvent_DictFnDisabled(t *testing.T) {
+	log := New(nil).Level(InfoLevel)
+	var called bool
+	dictFn := func(d *Event) { called = true }
+	arrayFn := func(a *Array) { called = true }
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Debug().DictFn("d", dictFn).ArrayFn("a", arrayFn).Msg("")
+	})
+	if allocs != 0 {
+		t.Errorf("DictFn and ArrayFn on a disabled event allocated %v times, want 0", allocs)
+	}
+	if called {
+		t.Error("builder called on a disabled event")
+	}
+}
+
+func TestFloatCompact(t *testing.T) {
+	defer func(c bool, p int) { FloatCompact, FloatingPointPrecision = c, p }(FloatCompact, FloatingPointPrecision)
+
+	a, b := 0.1, 0.2
+	tests := []struct {
+		name    string
+		compact bool
+		prec    int
+		val     float64
+		want    string
+	}{
+		{"raw 0.1+0.2", false, 0, a + b, `0.30000000000000004`},
+		{"0.1+0.2", true, 0, a + b, `0.3`},
+		{"integral", true, 0, 12, `12`},
+		{"precision", true, 3, 3.14159, `3.14`},
+		{"precision integral", true, 3, 123456, `123000`},
+		{"very small", true, 0, 1.0000000000000002e-20, `0.00000000000000000001`},
+		{"very small precision", true, 2, 1.2345e-20, `0.000000000000000000012`},
+		{"very large", true, 0, 1.0000000000000002e21, `1000000000000000000000`},
+		{"negative", true, 0, -a - b, `-0.3`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			FloatCompact, FloatingPointPrecision = tt.compact, tt.prec
+			var buf bytes.Buffer
+			e := newEvent(levelWriterAdapter{&buf}, DebugLevel)
+			_ = e.Float64("f", tt.val).Floats64("fs", []float64{tt.val, tt.val}).Int("i", 123456789)
+			_ = e.write()
+
+			want := `{"f":` + tt.want + `,"fs":[` + tt.want + `,` + tt.want + `],"i":123456789}`
+			if got := strings.TrimSpace(buf.String()); got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
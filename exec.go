@@ -0,0 +1,115 @@
+package zerolog
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Cmd adds the field key with a dict describing the command c: its path,
+// its arguments, its working directory and the number of variables in its
+// environment. The values of the flags ending with one of CmdSecretArgs are
+// redacted.
+// A nil c is logged as null.
+func (e *Event) Cmd(key string, c *exec.Cmd) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	if c == nil {
+		e.buf = enc.AppendNil(enc.AppendKey(e.buf, key))
+		return e
+	}
+	d := Dict().
+		Str("path", c.Path).
+		Strs("args", cmdArgs(c.Args))
+	if c.Dir != "" {
+		d.Str("dir", c.Dir)
+	}
+	d.Int("env_count", len(c.Env))
+	return e.Dict(key, d)
+}
+
+// CmdResult adds the field key with a dict describing the completion of a
+// command: its exit code, whether it succeeded, its user and system CPU
+// times and its outputs. The wall-clock duration is not known to state, add
+// it with Dur if needed. The outputs are truncated to limit bytes, unless
+// limit is lower than 1, and are omitted when empty. A nil state only logs
+// the outputs.
+func (e *Event) CmdResult(key string, state *os.ProcessState, stdout, stderr []byte, limit int) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	d := Dict()
+	if state != nil {
+		d.Int("exit_code", state.ExitCode()).
+			Bool("success", state.Success()).
+			Dur("user_time", state.UserTime()).
+			Dur("system_time", state.SystemTime())
+	}
+	if len(stdout) > 0 {
+		d.Str("stdout", cmdOutput(stdout, limit))
+	}
+	if len(stderr) > 0 {
+		d.Str("stderr", cmdOutput(stderr, limit))
+	}
+	return e.Dict(key, d)
+}
+
+// cmdArgs returns a copy of args with the values of the secret flags
+// redacted.
+func cmdArgs(args []string) []string {
+	if len(CmdSecretArgs) == 0 {
+		return args
+	}
+	res := make([]string, len(args))
+	copy(res, args)
+	for i := 1; i < len(res); i++ {
+		name, _, hasValue := strings.Cut(res[i], "=")
+		if !isCmdSecretArg(name) {
+			continue
+		}
+		if hasValue {
+			res[i] = name + "=" + redactedValue
+		} else if i+1 < len(res) {
+			i++
+			res[i] = redactedValue
+		}
+	}
+	return res
+}
+
+// isCmdSecretArg returns true if arg is a flag ending with one of
+// CmdSecretArgs.
+func isCmdSecretArg(arg string) bool {
+	if !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	arg = strings.ToLower(arg)
+	for _, s := range CmdSecretArgs {
+		if strings.HasSuffix(arg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmdOutput returns out as a string, truncated to limit bytes without
+// splitting a UTF-8 sequence.
+func cmdOutput(out []byte, limit int) string {
+	if limit < 1 || len(out) <= limit {
+		return string(out)
+	}
+	n := limit
+	for n > 0 && !utf8.RuneStart(out[n]) {
+		n--
+	}
+	return string(out[:n]) + "... (" + strconv.Itoa(len(out)-n) + " more bytes)"
+}
@@ -0,0 +1,61 @@
+//go:build !binary_log && !windows
+
+package zerolog
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestEvent_Cmd(t *testing.T) {
+	c := exec.Command("/bin/echo", "--password=hunter2", "-token", "abc", "--name", "foo")
+	c.Dir = "/tmp"
+	c.Env = []string{"A=1", "B=2"}
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().Cmd("cmd", c).Cmd("nil", nil).Msg("")
+	want := `{"cmd":{"path":"/bin/echo","args":["/bin/echo","--password=[REDACTED]","-token","[REDACTED]","--name","foo"],"dir":"/tmp","env_count":2},"nil":null}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := c.Args[1]; got != "--password=hunter2" {
+		t.Errorf("Cmd() modified the command arguments: %v", got)
+	}
+}
+
+func TestEvent_CmdResult(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{"true", `{"res":{"exit_code":0,"success":true,`},
+		{"false", `{"res":{"exit_code":1,"success":false,`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := exec.LookPath(tt.name)
+			if err != nil {
+				t.Skip(err)
+			}
+			c := exec.Command(path)
+			_ = c.Run()
+
+			out := &bytes.Buffer{}
+			log := New(out)
+			log.Log().CmdResult("res", c.ProcessState, []byte("héllo world"), nil, 2).Msg("")
+			got := out.String()
+			if !strings.HasPrefix(got, tt.want) || !strings.HasSuffix(got, `"stdout":"h... (11 more bytes)"}}`+"\n") {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v...", got, tt.want)
+			}
+		})
+	}
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().CmdResult("res", nil, nil, []byte("error"), 0).Msg("")
+	if got, want := out.String(), `{"res":{"stderr":"error"}}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,132 @@
+package zerolog
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// FailoverMinBackoff is the default duration a FailoverLevelWriter
+	// writes to its secondary writer after a failure of its primary one.
+	FailoverMinBackoff = time.Second
+
+	// FailoverMaxBackoff is the default maximum duration a
+	// FailoverLevelWriter writes to its secondary writer before probing its
+	// primary one again.
+	FailoverMaxBackoff = time.Minute
+)
+
+// FailoverLevelWriter is a LevelWriter writing to a primary writer, like a
+// log shipper over the network, and falling back to a secondary one, like a
+// local file, when it fails. It is safe for concurrent use.
+type FailoverLevelWriter struct {
+	// failovers and recovered are accessed atomically. They come first to
+	// keep them 64-bit aligned on 32-bit platforms.
+	failovers uint64
+	recovered uint64
+
+	primary, secondary io.Writer
+	minBackoff         time.Duration
+	maxBackoff         time.Duration
+
+	mu      sync.Mutex
+	backoff time.Duration // duration of the current backoff, 0 if healthy
+	retryAt time.Time     // end of the current backoff
+}
+
+// FailoverWriter creates a FailoverLevelWriter writing the events to
+// primary. When a write to primary fails, the event is written to
+// secondary instead, and so are the next ones for a backoff period. The
+// next write after it probes primary, and each failed probe doubles the
+// backoff period, from FailoverMinBackoff up to FailoverMaxBackoff. The
+// WriteLevel method of the writers is used if they implement LevelWriter.
+func FailoverWriter(primary, secondary io.Writer, options ...func(w *FailoverLevelWriter)) *FailoverLevelWriter {
+	w := &FailoverLevelWriter{
+		primary:    primary,
+		secondary:  secondary,
+		minBackoff: FailoverMinBackoff,
+		maxBackoff: FailoverMaxBackoff,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// FailoverBackoff is a FailoverWriter option setting the minimum and maximum
+// backoff periods.
+func FailoverBackoff(min, max time.Duration) func(w *FailoverLevelWriter) {
+	return func(w *FailoverLevelWriter) {
+		w.minBackoff, w.maxBackoff = min, max
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *FailoverLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *FailoverLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.backoff == 0 || !time.Now().Before(w.retryAt) {
+		n, err = failoverWrite(w.primary, l, p)
+		if err == nil {
+			w.backoff = 0
+			return n, nil
+		}
+		w.fail()
+	}
+	atomic.AddUint64(&w.recovered, 1)
+	return failoverWrite(w.secondary, l, p)
+}
+
+// fail starts or extends the backoff period after a failure of primary.
+func (w *FailoverLevelWriter) fail() {
+	if w.backoff == 0 {
+		atomic.AddUint64(&w.failovers, 1)
+		w.backoff = w.minBackoff
+	} else if w.backoff *= 2; w.backoff > w.maxBackoff {
+		w.backoff = w.maxBackoff
+	}
+	if w.backoff <= 0 {
+		// Probe primary on each write.
+		w.backoff = 1
+	}
+	w.retryAt = time.Now().Add(w.backoff)
+}
+
+// failoverWrite writes p to w, reporting short writes as errors.
+func failoverWrite(w io.Writer, l Level, p []byte) (n int, err error) {
+	if lw, ok := w.(LevelWriter); ok {
+		n, err = lw.WriteLevel(l, p)
+	} else {
+		n, err = w.Write(p)
+	}
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	return n, err
+}
+
+// Failovers returns the number of times w switched to its secondary writer
+// after a failure of its primary one.
+func (w *FailoverLevelWriter) Failovers() uint64 {
+	return atomic.LoadUint64(&w.failovers)
+}
+
+// RecoveredWrites returns the number of events w wrote to its secondary
+// writer.
+func (w *FailoverLevelWriter) RecoveredWrites() uint64 {
+	return atomic.LoadUint64(&w.recovered)
+}
+
+// Healthy reports whether w writes to its primary writer.
+func (w *FailoverLevelWriter) Healthy() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.backoff == 0
+}
@@ -0,0 +1,127 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// toggleWriter is a writer which fails while down is set.
+type toggleWriter struct {
+	mu   sync.Mutex
+	down bool
+	buf  bytes.Buffer
+}
+
+func (w *toggleWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.down {
+		return 0, errors.New("down")
+	}
+	return w.buf.Write(p)
+}
+
+func (w *toggleWriter) set(down bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.down = down
+}
+
+func (w *toggleWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return decodeIfBinaryToString(w.buf.Bytes())
+}
+
+func TestFailoverWriter(t *testing.T) {
+	primary, secondary := &toggleWriter{}, &toggleWriter{}
+	w := FailoverWriter(primary, secondary, FailoverBackoff(20*time.Millisecond, time.Second))
+	log := New(w)
+	log.Info().Msg("one")
+
+	primary.set(true)
+	log.Info().Msg("two")
+	log.Info().Msg("three")
+	if w.Healthy() {
+		t.Error("primary should be unhealthy")
+	}
+
+	// The next write after the backoff probes primary.
+	primary.set(false)
+	time.Sleep(30 * time.Millisecond)
+	log.Info().Msg("four")
+	if !w.Healthy() {
+		t.Error("primary should be healthy")
+	}
+
+	if got, want := primary.String(), `{"level":"info","message":"one"}`+"\n"+`{"level":"info","message":"four"}`+"\n"; got != want {
+		t.Errorf("invalid primary output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := secondary.String(), `{"level":"info","message":"two"}`+"\n"+`{"level":"info","message":"three"}`+"\n"; got != want {
+		t.Errorf("invalid secondary output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := w.Failovers(); got != 1 {
+		t.Errorf("Failovers() = %d, want 1", got)
+	}
+	if got := w.RecoveredWrites(); got != 2 {
+		t.Errorf("RecoveredWrites() = %d, want 2", got)
+	}
+}
+
+func TestFailoverWriterBackoff(t *testing.T) {
+	primary, secondary := &toggleWriter{down: true}, &toggleWriter{}
+	w := FailoverWriter(primary, secondary, FailoverBackoff(10*time.Millisecond, 15*time.Millisecond))
+	if _, err := w.Write([]byte("one\n")); err != nil {
+		t.Fatal(err)
+	}
+	if w.backoff != 10*time.Millisecond {
+		t.Errorf("backoff = %v, want 10ms", w.backoff)
+	}
+	time.Sleep(15 * time.Millisecond)
+	// The failed probe doubles the backoff, up to the maximum.
+	if _, err := w.Write([]byte("two\n")); err != nil {
+		t.Fatal(err)
+	}
+	if w.backoff != 15*time.Millisecond {
+		t.Errorf("backoff = %v, want 15ms", w.backoff)
+	}
+	if got := w.Failovers(); got != 1 {
+		t.Errorf("Failovers() = %d, want 1", got)
+	}
+
+	secondary.set(true)
+	if _, err := w.Write([]byte("three\n")); err == nil {
+		t.Error("Write() should fail when both writers fail")
+	}
+}
+
+func TestFailoverWriterConcurrent(t *testing.T) {
+	primary, secondary := &toggleWriter{}, &toggleWriter{}
+	w := FailoverWriter(primary, secondary, FailoverBackoff(time.Millisecond, 5*time.Millisecond))
+	log := New(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				log.Info().Msg("concurrent")
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		primary.set(i%2 == 0)
+		time.Sleep(time.Millisecond)
+	}
+	wg.Wait()
+	n := bytes.Count([]byte(primary.String()+secondary.String()), []byte("concurrent"))
+	if n != 800 {
+		t.Errorf("got %d events, want 800", n)
+	}
+	if got := bytes.Count([]byte(secondary.String()), []byte("concurrent")); uint64(got) != w.RecoveredWrites() {
+		t.Errorf("RecoveredWrites() = %d, want %d", w.RecoveredWrites(), got)
+	}
+}
@@ -13,13 +13,13 @@ func isNilValue(i interface{}) bool {
 	return (*[2]uintptr)(unsafe.Pointer(&i))[1] == 0
 }
 
-func appendFields(dst []byte, fields interface{}) []byte {
+func appendFields(dst []byte, fields interface{}, timeFormat string) []byte {
 	switch fields := fields.(type) {
 	case []interface{}:
 		if n := len(fields); n&0x1 == 1 { // odd number
 			fields = fields[:n-1]
 		}
-		dst = appendFieldList(dst, fields)
+		dst = appendFieldList(dst, fields, timeFormat)
 	case map[string]interface{}:
 		keys := make([]string, 0, len(fields))
 		for key := range fields {
@@ -29,14 +29,50 @@ func appendFields(dst []byte, fields interface{}) []byte {
 		kv := make([]interface{}, 2)
 		for _, key := range keys {
 			kv[0], kv[1] = key, fields[key]
-			dst = appendFieldList(dst, kv)
+			dst = appendFieldList(dst, kv, timeFormat)
 		}
 	}
 	return dst
 }
 
+// redactFields returns a copy of fields with the values of the keys
+// redacted by e replaced by the redacted placeholder.
+func (e *Event) redactFields(fields interface{}) interface{} {
+	switch fields := fields.(type) {
+	case []interface{}:
+		res := make([]interface{}, len(fields))
+		copy(res, fields)
+		for i := 0; i+1 < len(res); i += 2 {
+			if key, ok := res[i].(string); ok {
+				if _, ok := e.redactKeys[key]; ok {
+					res[i+1] = redactedValue
+					e.protect(key)
+				}
+			}
+		}
+		return res
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(fields))
+		var protected []string
+		for key, val := range fields {
+			if _, ok := e.redactKeys[key]; ok {
+				val = redactedValue
+				protected = append(protected, key)
+			}
+			res[key] = val
+		}
+		// List the keys in the order they are added to the event.
+		sort.Strings(protected)
+		for _, key := range protected {
+			e.protect(key)
+		}
+		return res
+	}
+	return fields
+}
+
 //goland:noinspection GoBoolExpressions,GoBoolExpressions,GoBoolExpressions
-func appendFieldList(dst []byte, kvList []interface{}) []byte {
+func appendFieldList(dst []byte, kvList []interface{}, timeFormat string) []byte {
 	for i, n := 0, len(kvList); i < n; i += 2 {
 		key, val := kvList[i], kvList[i+1]
 		if key, ok := key.(string); ok {
@@ -130,7 +166,7 @@ func appendFieldList(dst []byte, kvList []interface{}) []byte {
 		case float64:
 			dst = enc.AppendFloat64(dst, val)
 		case time.Time:
-			dst = enc.AppendTime(dst, val, TimeFieldFormat)
+			dst = enc.AppendTime(dst, val, timeFormat)
 		case time.Duration:
 			dst = enc.AppendDuration(dst, val, DurationFieldUnit, DurationFieldInteger)
 		case *string:
@@ -219,7 +255,7 @@ func appendFieldList(dst []byte, kvList []interface{}) []byte {
 			}
 		case *time.Time:
 			if val != nil {
-				dst = enc.AppendTime(dst, *val, TimeFieldFormat)
+				dst = enc.AppendTime(dst, *val, timeFormat)
 			} else {
 				dst = enc.AppendNil(dst)
 			}
@@ -258,7 +294,7 @@ func appendFieldList(dst []byte, kvList []interface{}) []byte {
 		case []float64:
 			dst = enc.AppendFloats64(dst, val)
 		case []time.Time:
-			dst = enc.AppendTimes(dst, val, TimeFieldFormat)
+			dst = enc.AppendTimes(dst, val, timeFormat)
 		case []time.Duration:
 			dst = enc.AppendDurations(dst, val, DurationFieldUnit, DurationFieldInteger)
 		case nil:
@@ -0,0 +1,197 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+// FlattenLevelWriter is a LevelWriter flattening the nested objects of the
+// events into top level fields with dotted keys, for the log pipelines
+// preferring flat documents, like some Elasticsearch mappings:
+//
+//	{"http":{"method":"GET","status":200}}
+//
+// is written as:
+//
+//	{"http.method":"GET","http.status":200}
+//
+// The arrays, including the arrays of objects, and the empty objects are
+// left as is. The events are rewritten in a single pass over their JSON
+// encoding, without decoding their values. The events in binary format are
+// converted to JSON lines first. The lines which are not JSON objects are written
+// as is.
+//
+// When flattening gives several fields the same key, like a top level
+// "http.method" field and a method field in an http object, the last one is
+// kept. It is safe for concurrent use.
+type FlattenLevelWriter struct {
+	w           io.Writer
+	maxDepth    int
+	onCollision func(key string)
+
+	mu     sync.Mutex
+	buf    []byte         // rewritten event
+	keys   []byte         // dotted keys of fields
+	prefix []byte         // dotted key of the object being flattened
+	fields []flattenField // fields of the event being rewritten
+}
+
+// flattenField is a field of an event rewritten by a FlattenLevelWriter:
+// its dotted key, in FlattenLevelWriter.keys, and its value, in the event.
+type flattenField struct {
+	keyStart, keyEnd     int
+	valueStart, valueEnd int
+}
+
+// FlattenWriter creates a FlattenLevelWriter writing the flattened events
+// to w. The keys have at most maxDepth dotted parts, the deeper objects
+// being left nested, or any number of them if maxDepth is 0 or less. The
+// WriteLevel method of w is used if it implements LevelWriter.
+func FlattenWriter(w io.Writer, maxDepth int, options ...func(w *FlattenLevelWriter)) *FlattenLevelWriter {
+	fw := &FlattenLevelWriter{w: w, maxDepth: maxDepth}
+	for _, opt := range options {
+		opt(fw)
+	}
+	return fw
+}
+
+// FlattenOnCollision is a FlattenWriter option setting a function called
+// with the key of each field dropped because a later field has the same
+// key, for instance to count them. The key is JSON escaped.
+func FlattenOnCollision(fn func(key string)) func(w *FlattenLevelWriter) {
+	return func(w *FlattenLevelWriter) {
+		w.onCollision = fn
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *FlattenLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *FlattenLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := p
+	if len(p) > 0 && p[0] > 0x7f {
+		// The binary events have no line break, unlike the JSON lines.
+		out = append([]byte(cbor.DecodeObjectToStr(p)), '\n')
+	}
+	if buf, ok := w.flatten(w.buf[:0], out); ok {
+		w.buf, out = buf, buf
+	}
+	if lw, ok := w.w.(LevelWriter); ok {
+		_, err = lw.WriteLevel(l, out)
+	} else {
+		_, err = w.w.Write(out)
+	}
+	return len(p), err
+}
+
+// flatten appends the event p, flattened, to dst, or returns false if p is
+// not a JSON object.
+func (w *FlattenLevelWriter) flatten(dst, p []byte) ([]byte, bool) {
+	i := jsonSkipSpace(p, 0)
+	if i >= len(p) || p[i] != '{' {
+		return dst, false
+	}
+	w.keys, w.prefix, w.fields = w.keys[:0], w.prefix[:0], w.fields[:0]
+	end, ok := w.collect(p, i, 1)
+	if !ok {
+		return dst, false
+	}
+	dst = append(dst, '{')
+	first := true
+	for i, f := range w.fields {
+		if w.overridden(i) {
+			if w.onCollision != nil {
+				w.onCollision(string(w.keys[f.keyStart:f.keyEnd]))
+			}
+			continue
+		}
+		if !first {
+			dst = append(dst, ',')
+		}
+		first = false
+		dst = append(dst, '"')
+		dst = append(dst, w.keys[f.keyStart:f.keyEnd]...)
+		dst = append(dst, '"', ':')
+		dst = append(dst, p[f.valueStart:f.valueEnd]...)
+	}
+	dst = append(dst, '}')
+	return append(dst, p[end:]...), true
+}
+
+// collect adds the fields of the object starting at p[i], at depth, to
+// w.fields, and returns its end.
+func (w *FlattenLevelWriter) collect(p []byte, i, depth int) (end int, ok bool) {
+	for i = jsonSkipSpace(p, i+1); i < len(p) && p[i] == '"'; {
+		k := jsonValueEnd(p, i)
+		if k < 0 {
+			return 0, false
+		}
+		key := p[i+1 : k-1]
+		i = jsonSkipSpace(p, k)
+		if i >= len(p) || p[i] != ':' {
+			return 0, false
+		}
+		i = jsonSkipSpace(p, i+1)
+		if i >= len(p) {
+			return 0, false
+		}
+		if w.flattened(p, i, depth) {
+			n := len(w.prefix)
+			w.prefix = append(append(w.prefix, key...), '.')
+			i, ok = w.collect(p, i, depth+1)
+			w.prefix = w.prefix[:n]
+			if !ok {
+				return 0, false
+			}
+		} else {
+			end := jsonValueEnd(p, i)
+			if end < 0 {
+				return 0, false
+			}
+			keyStart := len(w.keys)
+			w.keys = append(append(w.keys, w.prefix...), key...)
+			w.fields = append(w.fields, flattenField{keyStart, len(w.keys), i, end})
+			i = end
+		}
+		if i = jsonSkipSpace(p, i); i >= len(p) || p[i] != ',' {
+			break
+		}
+		i = jsonSkipSpace(p, i+1)
+	}
+	if i >= len(p) || p[i] != '}' {
+		return 0, false
+	}
+	return i + 1, true
+}
+
+// flattened returns true if the value starting at p[i], at depth, is an
+// object to flatten.
+func (w *FlattenLevelWriter) flattened(p []byte, i, depth int) bool {
+	if p[i] != '{' || (w.maxDepth > 0 && depth >= w.maxDepth) {
+		return false
+	}
+	// Keep the empty objects, which have no field to flatten.
+	j := jsonSkipSpace(p, i+1)
+	return j < len(p) && p[j] != '}'
+}
+
+// overridden returns true if a field after the i-th one of w.fields has
+// the same key.
+func (w *FlattenLevelWriter) overridden(i int) bool {
+	f := w.fields[i]
+	key := w.keys[f.keyStart:f.keyEnd]
+	for _, g := range w.fields[i+1:] {
+		if bytes.Equal(key, w.keys[g.keyStart:g.keyEnd]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFlattenWriter(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		maxDepth int
+		event    func(l *Logger)
+		want     string
+	}{
+		{
+			name: "Nested",
+			event: func(l *Logger) {
+				l.Info().
+					Dict("http", Dict().Str("method", "GET").Dict("resp", Dict().Int("status", 200).Dict("empty", Dict()))).
+					Str("foo", "bar").
+					Msg("hi")
+			},
+			want: `{"level":"info","http.method":"GET","http.resp.status":200,"http.resp.empty":{},"foo":"bar","message":"hi"}` + "\n",
+		},
+		{
+			name:     "MaxDepth",
+			maxDepth: 2,
+			event: func(l *Logger) {
+				l.Log().Dict("a", Dict().Dict("b", Dict().Dict("c", Dict().Int("d", 1)))).Send()
+			},
+			want: `{"a.b":{"c":{"d":1}}}` + "\n",
+		},
+		{
+			name: "Arrays",
+			event: func(l *Logger) {
+				l.Log().Dict("req", Dict().Interface("items", []map[string]int{{"a": 1}, {"b": 2}})).Send()
+			},
+			want: `{"req.items":[{"a":1},{"b":2}]}` + "\n",
+		},
+		{
+			name: "Escaping",
+			event: func(l *Logger) {
+				l.Log().Dict(`a"b`, Dict().Str("c\\d", "e\"f")).Send()
+			},
+			want: `{"a\"b.c\\d":"e\"f"}` + "\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			tt.event(New(FlattenWriter(out, tt.maxDepth)))
+			if got := out.String(); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenWriterCollisions(t *testing.T) {
+	out := &bytes.Buffer{}
+	var collisions []string
+	log := New(FlattenWriter(out, 0, FlattenOnCollision(func(key string) {
+		collisions = append(collisions, key)
+	})))
+	log.Log().
+		Str("http.method", "POST").
+		Dict("http", Dict().Str("method", "GET")).
+		Send()
+	if got, want := out.String(), `{"http.method":"GET"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(collisions) != 1 || collisions[0] != "http.method" {
+		t.Errorf("invalid collisions: %q", collisions)
+	}
+}
+
+func TestFlattenWriterNotObject(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := FlattenWriter(out, 0)
+	for _, line := range []string{"not an event\n", `{"truncated":{"a":` + "\n"} {
+		out.Reset()
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if got := out.String(); got != line {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, line)
+		}
+	}
+}
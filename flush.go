@@ -0,0 +1,141 @@
+package zerolog
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// FlushAllTimeout is the maximum duration FlushAll waits for the writers to
+// be flushed.
+const FlushAllTimeout = 5 * time.Second
+
+// AutoRegisterFlushers makes the buffering writers created by this
+// package, like BufferedWriter and GzipWriter, register themselves to be
+// flushed by FlushAll until they are closed. Set it to false before creating
+// them to opt out.
+var AutoRegisterFlushers = true
+
+// flushers is the registry of the writers flushed by FlushAll, with their
+// flush function.
+var flushers = struct {
+	sync.Mutex
+	m map[io.Writer]func() error
+}{m: map[io.Writer]func() error{}}
+
+// RegisterFlusher registers w, which must be comparable like a pointer, to
+// be flushed by FlushAll with flush, until UnregisterFlusher is called.
+func RegisterFlusher(w io.Writer, flush func() error) {
+	flushers.Lock()
+	defer flushers.Unlock()
+	flushers.m[w] = flush
+}
+
+// UnregisterFlusher stops flushing w in FlushAll.
+func UnregisterFlusher(w io.Writer) {
+	flushers.Lock()
+	defer flushers.Unlock()
+	delete(flushers.m, w)
+}
+
+// autoRegisterFlusher registers w if AutoRegisterFlushers is set.
+func autoRegisterFlusher(w io.Writer, flush func() error) {
+	if AutoRegisterFlushers {
+		RegisterFlusher(w, flush)
+	}
+}
+
+// FlushAll flushes the registered writers, see RegisterFlusher, and returns
+// the first error. It gives up after FlushAllTimeout, for instance if a
+// writer is blocked. Deferring it at the start of main flushes them when
+// main returns or panics:
+//
+//	func main() {
+//	    defer zerolog.FlushAll()
+//	    ...
+//	}
+//
+// It is best effort: the deferred calls are not run when another goroutine
+// panics, nor on os.Exit. Wrap the goroutines with FlushOnPanic.
+func FlushAll() error {
+	flushers.Lock()
+	fns := make([]func() error, 0, len(flushers.m))
+	for _, flush := range flushers.m {
+		fns = append(fns, flush)
+	}
+	flushers.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		var err error
+		for _, flush := range fns {
+			if ferr := flush(); err == nil {
+				err = ferr
+			}
+		}
+		done <- err
+	}()
+	t := time.NewTimer(FlushAllTimeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-t.C:
+		return errors.New("zerolog: FlushAll timed out")
+	}
+}
+
+// FlushOnPanic returns a function which, deferred, flushes the writers of
+// loggers if the calling goroutine panics, before panicking again with the
+// same value. All the registered writers are flushed, as with FlushAll, if
+// no logger is given:
+//
+//	go func() {
+//	    defer zerolog.FlushOnPanic(logger)()
+//	    ...
+//	}()
+//
+// The writers are flushed if they have a Flush or Sync method, like
+// GzipLevelWriter and BufferedLevelWriter, including the ones combined with
+// MultiLevelWriter or SyncWriter.
+func FlushOnPanic(loggers ...*Logger) (restore func()) {
+	return func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if len(loggers) == 0 {
+			_ = FlushAll()
+		}
+		for _, l := range loggers {
+			if l != nil && l.w != nil {
+				_ = flushWriter(l.w)
+			}
+		}
+		panic(r)
+	}
+}
+
+// flushWriter flushes w, or the writers it combines.
+func flushWriter(w io.Writer) error {
+	switch w := w.(type) {
+	case levelWriterAdapter:
+		return flushWriter(w.Writer)
+	case *syncWriter:
+		return flushWriter(w.lw)
+	case multiLevelWriter:
+		var err error
+		for _, lw := range w.writers {
+			if ferr := flushWriter(lw); err == nil {
+				err = ferr
+			}
+		}
+		return err
+	case interface{ Flush() error }:
+		return w.Flush()
+	case interface{ Sync() error }:
+		return w.Sync()
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// flusherRegistered returns true if w is registered to be flushed by
+// FlushAll.
+func flusherRegistered(w io.Writer) bool {
+	flushers.Lock()
+	defer flushers.Unlock()
+	_, ok := flushers.m[w]
+	return ok
+}
+
+func TestFlusherRegistry(t *testing.T) {
+	b := BufferedWriter(&bytes.Buffer{}, 0, 0)
+	gz := GzipWriter(&bytes.Buffer{}, 0)
+	if !flusherRegistered(b) || !flusherRegistered(gz) {
+		t.Fatal("writers not registered")
+	}
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if flusherRegistered(b) || flusherRegistered(gz) {
+		t.Error("writers registered after Close")
+	}
+
+	AutoRegisterFlushers = false
+	defer func() { AutoRegisterFlushers = true }()
+	b = BufferedWriter(&bytes.Buffer{}, 0, 0)
+	defer b.Close()
+	if flusherRegistered(b) {
+		t.Error("writer registered with AutoRegisterFlushers unset")
+	}
+}
+
+func TestFlushAll(t *testing.T) {
+	out := &bytes.Buffer{}
+	b := BufferedWriter(out, 0, 0)
+	defer b.Close()
+	New(b).Info().Msg("buffered")
+	if out.Len() != 0 {
+		t.Fatalf("invalid log output before FlushAll: %q", out.String())
+	}
+	if err := FlushAll(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"buffered"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestFlushOnPanic(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		loggers func(l *Logger) []*Logger
+	}{
+		{"Loggers", func(l *Logger) []*Logger { return []*Logger{l} }},
+		{"All", func(l *Logger) []*Logger { return nil }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			b := BufferedWriter(out, 0, 0)
+			defer b.Close()
+			log := New(MultiLevelWriter(b))
+
+			var recovered interface{}
+			func() {
+				defer func() { recovered = recover() }()
+				defer FlushOnPanic(tt.loggers(log)...)()
+				log.Info().Msg("before panic")
+				panic("boom")
+			}()
+			if recovered != "boom" {
+				t.Errorf("invalid panic value: %v", recovered)
+			}
+			if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"before panic"}`+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+// stuckWriter blocks the writes until unblock is closed.
+type stuckWriter struct {
+	unblock chan struct{}
+	written chan []byte
+}
+
+func (w stuckWriter) Write(p []byte) (int, error) {
+	<-w.unblock
+	w.written <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func TestFatalTimeout(t *testing.T) {
+	var exitCode int
+	ExitFunc = func(code int) { exitCode = code }
+	defer func() { ExitFunc = os.Exit }()
+	stderr := &bytes.Buffer{}
+	fatalStderr = stderr
+	defer func() { fatalStderr = os.Stderr }()
+
+	t.Run("Stuck", func(t *testing.T) {
+		stderr.Reset()
+		exitCode = 0
+		w := stuckWriter{unblock: make(chan struct{}), written: make(chan []byte, 1)}
+		start := time.Now()
+		New(w).FatalTimeout(50*time.Millisecond).Str("k", "v").Msg("boom")
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("Msg returned after %v", d)
+		}
+		if exitCode != 1 {
+			t.Errorf("ExitFunc called with %d, want 1", exitCode)
+		}
+		if got, want := decodeIfBinaryToString(stderr.Bytes()), `{"level":"fatal","k":"v","message":"boom"}`+"\n"; got != want {
+			t.Errorf("invalid stderr output:\ngot:  %v\nwant: %v", got, want)
+		}
+		close(w.unblock)
+		if written := <-w.written; !bytes.Equal(written, stderr.Bytes()) {
+			t.Errorf("stderr output %q differs from the event %q", stderr.Bytes(), written)
+		}
+	})
+
+	t.Run("Healthy", func(t *testing.T) {
+		stderr.Reset()
+		exitCode = 0
+		out := &bytes.Buffer{}
+		b := BufferedWriter(out, 0, 0)
+		defer b.Close()
+		New(b).FatalTimeout(time.Second).Msg("boom")
+		if exitCode != 1 {
+			t.Errorf("ExitFunc called with %d, want 1", exitCode)
+		}
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"fatal","message":"boom"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+		if stderr.Len() != 0 {
+			t.Errorf("unexpected stderr output %q", stderr.Bytes())
+		}
+	})
+}
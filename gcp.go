@@ -0,0 +1,58 @@
+package zerolog
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	// GCPSeverityFieldName is the field name of the level of the events of
+	// the loggers created with NewGCPLogger.
+	GCPSeverityFieldName = "severity"
+	// GCPStackTraceFieldName is the field name of the error stacks of the
+	// events of the loggers created with NewGCPLogger.
+	GCPStackTraceFieldName = "stack_trace"
+)
+
+// GCPSeverity returns the Google Cloud Logging severity of l, to be used as
+// a level field marshal function, see Context.LevelField. The levels without
+// equivalent, like NoLevel and the custom levels, are mapped to DEFAULT.
+func GCPSeverity(l Level) string {
+	switch l {
+	case DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel:
+		return "CRITICAL"
+	case PanicLevel:
+		return "ALERT"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// NewGCPLogger creates a logger writing to w the events in the format
+// parsed by the Google Cloud Logging agents, like the one of GKE reading
+// the standard output of the containers:
+//
+//	{"severity":"WARNING","time":"2006-01-02T15:04:05.999999999Z","message":"disk almost full"}
+//
+// The level is written as a GCPSeverity under GCPSeverityFieldName, the
+// error stacks under GCPStackTraceFieldName, and the timestamp in RFC 3339
+// format. The timestamp and the message are written under
+// zerolog.TimestampFieldName and zerolog.MessageFieldName, which are the
+// names expected by Cloud Logging by default. The other loggers and the
+// field name globals are not modified.
+func NewGCPLogger(w io.Writer) *Logger {
+	return New(w).With().
+		LevelField(GCPSeverityFieldName, GCPSeverity).
+		ErrorStackFieldName(GCPStackTraceFieldName).
+		TimestampFieldFormat(time.RFC3339Nano).
+		Timestamp().
+		Logger()
+}
@@ -0,0 +1,53 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestGCPLogger(t *testing.T) {
+	for _, tt := range []struct {
+		level Level
+		want  string
+	}{
+		{TraceLevel, `{"severity":"DEFAULT","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{DebugLevel, `{"severity":"DEBUG","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{InfoLevel, `{"severity":"INFO","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{WarnLevel, `{"severity":"WARNING","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{ErrorLevel, `{"severity":"ERROR","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{FatalLevel, `{"severity":"CRITICAL","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{PanicLevel, `{"severity":"ALERT","time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+		{NoLevel, `{"time":"2001-02-03T04:05:06.5Z","message":"hello"}`},
+	} {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			log := NewGCPLogger(out).WithClock(func() time.Time {
+				return time.Date(2001, 2, 3, 4, 5, 6, 500000000, time.UTC)
+			})
+			log.WithLevel(tt.level).Msg("hello")
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestGCPLoggerStackTrace(t *testing.T) {
+	ErrorStackMarshaler = func(err error) interface{} { return "stack of " + err.Error() }
+	defer func() { ErrorStackMarshaler = nil }()
+	out := &bytes.Buffer{}
+	NewGCPLogger(out).WithClock(func() time.Time { return time.Time{} }).
+		Error().Stack().Err(errors.New("boom")).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"severity":"ERROR","stack_trace":"stack of boom","error":"boom","time":"0001-01-01T00:00:00Z"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	// The other loggers keep the default field names.
+	out.Reset()
+	New(out).Error().Stack().Err(errors.New("boom")).Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"error","stack":"stack of boom","error":"boom"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,373 @@
+// Package gelf provides a zerolog writer sending the events to Graylog, or
+// any GELF input, in the GELF 1.1 format over UDP or TCP:
+//
+//	w, err := gelf.NewUDPWriter("graylog:12201")
+//	if err != nil {
+//	    ...
+//	}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The message field of the events is sent as short_message, their time
+// field as the GELF timestamp, their level as a syslog severity, and their
+// other fields as additional fields, prefixed with "_".
+package gelf
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/x0f5c3/zerolog"
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+const (
+	// DefaultChunkSize is the maximum size of the UDP datagrams, chunk
+	// headers included, fitting in the usual MTU of 1500 bytes.
+	DefaultChunkSize = 1420
+
+	// DefaultMinBackoff and DefaultMaxBackoff are the initial and maximum
+	// durations the writers wait before connecting again after a failure.
+	DefaultMinBackoff = 100 * time.Millisecond
+	DefaultMaxBackoff = 30 * time.Second
+
+	// maxChunks is the maximum number of chunks of a GELF message.
+	maxChunks = 128
+
+	// chunkHeaderSize is the size of the header of the chunks: the magic
+	// bytes, the message ID, the sequence number and the sequence count.
+	chunkHeaderSize = 12
+)
+
+// Writer is a zerolog.LevelWriter sending the events as GELF messages. The
+// connection is dialed on the first write, and again after a failure, with
+// an exponential backoff: the events written while waiting are dropped,
+// with an error. It is safe for concurrent use.
+type Writer struct {
+	// msgID is accessed atomically. It comes first to keep it 64-bit
+	// aligned on 32-bit platforms.
+	msgID uint64
+
+	network    string
+	addr       string
+	host       string
+	chunkSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu       sync.Mutex
+	conn     net.Conn
+	backoff  time.Duration // delay before the next dial after a failure
+	nextDial time.Time     // time before which no dial is attempted
+	buf      []byte
+}
+
+// Option configures a Writer.
+type Option func(w *Writer)
+
+// WithHost sets the host field of the messages, the host name reported by
+// the kernel by default.
+func WithHost(host string) Option {
+	return func(w *Writer) {
+		w.host = host
+	}
+}
+
+// WithChunkSize sets the maximum size of the UDP datagrams, DefaultChunkSize
+// by default. The larger messages are sent in chunks, up to 128 of them.
+// It is ignored by the TCP writers.
+func WithChunkSize(n int) Option {
+	return func(w *Writer) {
+		w.chunkSize = n
+	}
+}
+
+// WithBackoff sets the initial and maximum durations the writer waits
+// before connecting again after a failure, DefaultMinBackoff and
+// DefaultMaxBackoff by default.
+func WithBackoff(min, max time.Duration) Option {
+	return func(w *Writer) {
+		w.minBackoff, w.maxBackoff = min, max
+	}
+}
+
+// NewUDPWriter creates a Writer sending the events to the GELF UDP input at
+// addr, in chunks if they are larger than the chunk size.
+func NewUDPWriter(addr string, options ...Option) (*Writer, error) {
+	return newWriter("udp", addr, options)
+}
+
+// NewTCPWriter creates a Writer sending the events to the GELF TCP input at
+// addr, each followed by a null byte.
+func NewTCPWriter(addr string, options ...Option) (*Writer, error) {
+	return newWriter("tcp", addr, options)
+}
+
+func newWriter(network, addr string, options []Option) (*Writer, error) {
+	w := &Writer{
+		network:    network,
+		addr:       addr,
+		chunkSize:  DefaultChunkSize,
+		minBackoff: DefaultMinBackoff,
+		maxBackoff: DefaultMaxBackoff,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	if w.host == "" {
+		w.host, _ = os.Hostname()
+	}
+	if w.chunkSize <= chunkHeaderSize {
+		return nil, fmt.Errorf("gelf: invalid chunk size %d", w.chunkSize)
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	w.msgID = binary.BigEndian.Uint64(id[:])
+	return w, nil
+}
+
+// Write implements the io.Writer interface. The level of the event is read
+// from its zerolog.LevelFieldName field.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel implements the zerolog.LevelWriter interface. The level of the
+// event is read from its zerolog.LevelFieldName field if l is NoLevel.
+func (w *Writer) WriteLevel(l zerolog.Level, p []byte) (n int, err error) {
+	msg, err := w.message(l, p)
+	if err != nil {
+		return 0, err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.connect(); err != nil {
+		return 0, err
+	}
+	if w.network == "udp" {
+		err = w.sendChunks(msg)
+	} else {
+		_, err = w.conn.Write(append(msg, 0))
+	}
+	if err != nil {
+		w.fail()
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the connection. The next write dials it again.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+// connect dials the connection if needed and allowed by the backoff.
+func (w *Writer) connect() error {
+	if w.conn != nil {
+		return nil
+	}
+	if time.Now().Before(w.nextDial) {
+		return errors.New("gelf: waiting to reconnect to " + w.addr)
+	}
+	conn, err := net.Dial(w.network, w.addr)
+	if err != nil {
+		w.fail()
+		return err
+	}
+	w.conn, w.backoff = conn, 0
+	return nil
+}
+
+// fail closes the connection and delays the next dial, doubling the backoff.
+func (w *Writer) fail() {
+	if w.conn != nil {
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	switch {
+	case w.backoff == 0:
+		w.backoff = w.minBackoff
+	case w.backoff < w.maxBackoff:
+		w.backoff *= 2
+		if w.backoff > w.maxBackoff {
+			w.backoff = w.maxBackoff
+		}
+	}
+	w.nextDial = time.Now().Add(w.backoff)
+}
+
+// sendChunks sends msg in a single datagram, or in chunks if it is larger
+// than the chunk size.
+func (w *Writer) sendChunks(msg []byte) error {
+	if len(msg) <= w.chunkSize {
+		_, err := w.conn.Write(msg)
+		return err
+	}
+	size := w.chunkSize - chunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > maxChunks {
+		return fmt.Errorf("gelf: message of %d bytes exceeds %d chunks", len(msg), maxChunks)
+	}
+	var header [chunkHeaderSize]byte
+	header[0], header[1] = 0x1e, 0x0f
+	binary.BigEndian.PutUint64(header[2:10], atomic.AddUint64(&w.msgID, 1))
+	header[11] = byte(count)
+	for i := 0; i < count; i++ {
+		header[10] = byte(i)
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		w.buf = append(append(w.buf[:0], header[:]...), msg[i*size:end]...)
+		if _, err := w.conn.Write(w.buf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// message returns the GELF message of the event p, in JSON or binary
+// format.
+func (w *Writer) message(l zerolog.Level, p []byte) ([]byte, error) {
+	var event map[string]interface{}
+	d := json.NewDecoder(bytes.NewReader(cbor.DecodeIfBinaryToBytes(p)))
+	d.UseNumber()
+	if err := d.Decode(&event); err != nil {
+		return nil, fmt.Errorf("gelf: cannot decode event: %v", err)
+	}
+	if s, ok := event[zerolog.LevelFieldName].(string); ok && l == zerolog.NoLevel {
+		l, _ = zerolog.ParseLevel(s)
+	}
+	short, _ := event[zerolog.MessageFieldName].(string)
+	if short == "" {
+		// short_message is required and can't be empty.
+		short = "-"
+	}
+	msg := map[string]interface{}{
+		"version":       "1.1",
+		"host":          w.host,
+		"short_message": short,
+		"timestamp":     timestamp(event[zerolog.TimestampFieldName]),
+		"level":         severity(l),
+	}
+	for key, value := range event {
+		switch key {
+		case zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.TimestampFieldName:
+			continue
+		}
+		msg[fieldName(key)] = fieldValue(value)
+	}
+	return json.Marshal(msg)
+}
+
+// severity returns the syslog severity of l, like the journald writer.
+func severity(l zerolog.Level) int {
+	switch l {
+	case zerolog.TraceLevel, zerolog.DebugLevel:
+		return 7
+	case zerolog.InfoLevel:
+		return 6
+	case zerolog.WarnLevel:
+		return 4
+	case zerolog.ErrorLevel:
+		return 3
+	case zerolog.FatalLevel:
+		return 2
+	case zerolog.PanicLevel:
+		return 0
+	default:
+		return 5
+	}
+}
+
+// timestamp returns the time field v of an event in seconds since the Unix
+// epoch, or the current time if it is missing or invalid.
+func timestamp(v interface{}) float64 {
+	now := seconds(time.Now())
+	switch v := v.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return now
+		}
+		switch zerolog.TimeFieldFormat {
+		case zerolog.TimeFormatUnixMs:
+			return f / 1e3
+		case zerolog.TimeFormatUnixMicro:
+			return f / 1e6
+		case zerolog.TimeFormatUnixNano:
+			return f / 1e9
+		}
+		return f
+	case string:
+		t, err := time.Parse(zerolog.TimeFieldFormat, v)
+		if err != nil {
+			// The binary format renders the times in RFC 3339 format.
+			if t, err = time.Parse(time.RFC3339Nano, v); err != nil {
+				return now
+			}
+		}
+		return seconds(t)
+	}
+	return now
+}
+
+// seconds returns t in seconds since the Unix epoch.
+func seconds(t time.Time) float64 {
+	return float64(t.Unix()) + float64(t.Nanosecond())/1e9
+}
+
+// fieldName returns the name of the additional field of key: key prefixed
+// with "_", with the characters not allowed by GELF replaced by "_". The
+// reserved "_id" field is renamed "_id_".
+func fieldName(key string) string {
+	name := []byte("_" + key)
+	for i, c := range name {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-') {
+			name[i] = '_'
+		}
+	}
+	if string(name) == "_id" {
+		return "_id_"
+	}
+	return string(name)
+}
+
+// fieldValue returns the value of an additional field: a string or a
+// number, the other values being marshaled as JSON strings.
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string, json.Number:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case nil:
+		return "null"
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprintf("[error: %v]", err)
+		}
+		return string(b)
+	}
+}
@@ -0,0 +1,197 @@
+package gelf
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+
+	"github.com/x0f5c3/zerolog"
+)
+
+// listenUDP returns a UDP listener on a random local port.
+func listenUDP(t *testing.T) net.PacketConn {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readDatagram reads a datagram from conn, failing after a second.
+func readDatagram(t *testing.T, conn net.PacketConn) []byte {
+	t.Helper()
+	buf := make([]byte, 65536)
+	if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf[:n]
+}
+
+func decodeMessage(t *testing.T, b []byte) map[string]interface{} {
+	t.Helper()
+	var msg map[string]interface{}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		t.Fatalf("invalid GELF message %q: %v", b, err)
+	}
+	return msg
+}
+
+func TestUDPWriter(t *testing.T) {
+	conn := listenUDP(t)
+	w, err := NewUDPWriter(conn.LocalAddr().String(), WithHost("test-host"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	zerolog.New(w).Warn().Str("user", "bob").Int("n", 3).Bool("ok", true).Str("id", "x").Msg("hello")
+
+	msg := decodeMessage(t, readDatagram(t, conn))
+	want := map[string]interface{}{
+		"version":       "1.1",
+		"host":          "test-host",
+		"short_message": "hello",
+		"level":         float64(4),
+		"_user":         "bob",
+		"_n":            float64(3),
+		"_ok":           "true",
+		"_id_":          "x",
+	}
+	for k, v := range want {
+		if msg[k] != v {
+			t.Errorf("%s = %v, want %v", k, msg[k], v)
+		}
+	}
+	if ts, ok := msg["timestamp"].(float64); !ok || ts < float64(time.Now().Add(-time.Minute).Unix()) {
+		t.Errorf("invalid timestamp %v", msg["timestamp"])
+	}
+}
+
+func TestUDPWriterChunks(t *testing.T) {
+	conn := listenUDP(t)
+	const chunkSize = 64
+	w, err := NewUDPWriter(conn.LocalAddr().String(), WithChunkSize(chunkSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	long := strings.Repeat("x", 500)
+	zerolog.New(w).Info().Msg(long)
+
+	var payload []byte
+	var id []byte
+	count := -1
+	for seq := 0; seq != count; seq++ {
+		chunk := readDatagram(t, conn)
+		if len(chunk) > chunkSize || len(chunk) <= chunkHeaderSize {
+			t.Fatalf("invalid chunk size %d", len(chunk))
+		}
+		if chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("invalid chunk magic bytes % x", chunk[:2])
+		}
+		if id == nil {
+			id, count = chunk[2:10], int(chunk[11])
+		} else if !bytes.Equal(chunk[2:10], id) || int(chunk[11]) != count {
+			t.Fatalf("chunk %d has ID % x and count %d, want % x and %d", seq, chunk[2:10], chunk[11], id, count)
+		}
+		if int(chunk[10]) != seq {
+			t.Fatalf("chunk has sequence number %d, want %d", chunk[10], seq)
+		}
+		payload = append(payload, chunk[chunkHeaderSize:]...)
+	}
+	if count < 2 {
+		t.Errorf("message sent in %d chunks", count)
+	}
+	if msg := decodeMessage(t, payload); msg["short_message"] != long {
+		t.Errorf("invalid short_message %v", msg["short_message"])
+	}
+
+	// The next message has another ID.
+	zerolog.New(w).Info().Msg(long)
+	if chunk := readDatagram(t, conn); binary.BigEndian.Uint64(chunk[2:10]) == binary.BigEndian.Uint64(id) {
+		t.Error("message ID reused")
+	}
+}
+
+func TestTCPWriterReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	messages := make(chan []byte, 16)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			r := bufio.NewReader(conn)
+			b, err := r.ReadBytes(0)
+			if err == nil {
+				messages <- b
+			}
+			// Drop the connection after each message.
+			conn.Close()
+		}
+	}()
+	// The writes to the dropped connections fail.
+	defer func() { zerolog.ErrorHandler = nil }()
+	zerolog.ErrorHandler = func(err error) {}
+	w, err := NewTCPWriter(ln.Addr().String(), WithBackoff(5*time.Millisecond, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	log := zerolog.New(w)
+
+	for i, want := range []string{"first", "second"} {
+		deadline := time.After(5 * time.Second)
+	send:
+		for {
+			log.Info().Int("i", i).Msg(want)
+			select {
+			case b := <-messages:
+				if b[len(b)-1] != 0 {
+					t.Fatalf("message %q not terminated by a null byte", b)
+				}
+				if msg := decodeMessage(t, b[:len(b)-1]); msg["short_message"] == want {
+					break send
+				}
+			case <-time.After(10 * time.Millisecond):
+			case <-deadline:
+				t.Fatalf("message %q not received", want)
+			}
+		}
+	}
+}
+
+func TestTimestamp(t *testing.T) {
+	defer func(format string) { zerolog.TimeFieldFormat = format }(zerolog.TimeFieldFormat)
+	for _, tt := range []struct {
+		format string
+		v      interface{}
+		want   float64
+	}{
+		{zerolog.TimeFormatUnix, json.Number("1570912626"), 1570912626},
+		{zerolog.TimeFormatUnixMs, json.Number("1570912626500"), 1570912626.5},
+		{time.RFC3339, "2019-10-12T20:37:06Z", 1570912626},
+		{time.RFC3339, "2019-10-12T20:37:06.25Z", 1570912626.25},
+	} {
+		zerolog.TimeFieldFormat = tt.format
+		if got := timestamp(tt.v); got != tt.want {
+			t.Errorf("timestamp(%v) with format %q = %v, want %v", tt.v, tt.format, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package zerolog
 
 import (
+	"context"
+	"os"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -73,6 +75,88 @@ var (
 	// ErrorStackFieldName is the field name used for error stacks.
 	ErrorStackFieldName = "stack"
 
+	// MarshalPanicFieldName is the field name used to report the panic of a
+	// builder given to Event.DictFn or Event.ArrayFn.
+	MarshalPanicFieldName = "marshal_panic"
+
+	// CgroupFieldName, ContainerIDFieldName, PodNameFieldName,
+	// PodNamespaceFieldName and NodeNameFieldName are the field names used by
+	// Context.ContainerInfo.
+	CgroupFieldName       = "cgroup"
+	ContainerIDFieldName  = "container_id"
+	PodNameFieldName      = "pod_name"
+	PodNamespaceFieldName = "pod_namespace"
+	NodeNameFieldName     = "node_name"
+
+	// ClockSkewFieldName is the field name used by ClockSkewHook to report
+	// a step of the wall clock, in milliseconds.
+	ClockSkewFieldName = "clock_skew_ms"
+
+	// ProtectedFieldName is the field name used by RedactAuditHook to list
+	// the keys of the redacted fields of an event.
+	ProtectedFieldName = "_protected"
+
+	// InvalidLevelFieldName is the field name used to report the value of
+	// an invalid level passed to Logger.WithLevel, see InvalidLevelHandling.
+	InvalidLevelFieldName = "bad_level"
+
+	// PrincipalMissingFieldName is the field name used to flag the events
+	// without principal of a Logger requiring one, see
+	// Logger.RequirePrincipal.
+	PrincipalMissingFieldName = "principal_missing"
+
+	// NestingTruncatedFieldName is the field name used to report that
+	// containers deeper than MaxNestingDepth were dropped from an event.
+	NestingTruncatedFieldName = "nesting_truncated"
+
+	// RepeatedFieldName is the field name used by DedupLevelWriter to report
+	// the number of repeats of an event it suppressed.
+	RepeatedFieldName = "repeated"
+
+	// DroppedFieldName is the field name used by RateLimitLevelWriter to
+	// report the number of events it dropped per level.
+	DroppedFieldName = "dropped"
+
+	// MessageKeyFieldName and MessageParamsFieldName are the field names used
+	// by Event.MsgKey for the message key and its parameters.
+	MessageKeyFieldName    = "message_key"
+	MessageParamsFieldName = "message_params"
+
+	// HTTPFieldsTruncatedFieldName is the field name added, set to true, to
+	// the objects of HeaderFields and QueryFields truncated to
+	// HTTPFieldsMaxSize.
+	HTTPFieldsTruncatedFieldName = "_truncated"
+
+	// MaxNestingDepth is the maximum depth of the dicts and arrays added to
+	// an event by Object, DictFn, ArrayFn, Array with a LogArrayMarshaler
+	// and Array.Object. The containers which would be deeper are dropped,
+	// and the NestingTruncatedFieldName field is added to the event. The
+	// depth of the dicts and arrays created with Dict and Arr is counted
+	// from them, as they don't know the event they will be added to.
+	MaxNestingDepth = 128
+
+	// HTTPFieldsMaxSize is the maximum total size of the keys and values
+	// added by HeaderFields and QueryFields. The keys which would exceed it
+	// are dropped, in their sorted order. There is no maximum if it is 0 or
+	// less.
+	HTTPFieldsMaxSize = 8 << 10
+
+	// FloatCompact rounds the floats logged in JSON to FloatingPointPrecision
+	// significant digits, or 15 if it is not set, removing the noise of
+	// binary arithmetic like the one of 0.1+0.2 (0.30000000000000004). Floats
+	// are always written in their shortest representation, without trailing
+	// zeros nor exponent. The binary format keeps their exact value.
+	FloatCompact = false
+
+	// FloatingPointPrecision is the number of significant digits the floats
+	// are rounded to when FloatCompact is set, if greater than 0.
+	FloatingPointPrecision = 0
+
+	// MessageRenderer renders the human message of the events logged with
+	// Event.MsgKey from their message key and parameters, for instance with
+	// a translation catalog. If not set, these events have no message field.
+	MessageRenderer func(key string, params map[string]string) string
+
 	// ErrorStackMarshaler extract the stack from err if any.
 	ErrorStackMarshaler func(err error) interface{}
 
@@ -101,19 +185,66 @@ var (
 	// set to true.
 	DurationFieldInteger = false
 
+	// InvalidLevelHandling defines how Logger.WithLevel handles the levels
+	// above Disabled which are not registered with RegisterLevel.
+	InvalidLevelHandling = InvalidLevelAsNoLevel
+
+	// PrincipalExtractor returns the authenticated principal of a context,
+	// like the user of a request, or false if it has none. It is used by the
+	// loggers requiring a principal, see Logger.RequirePrincipal.
+	PrincipalExtractor func(ctx context.Context) (string, bool)
+
+	// PrincipalMissingLevel is the minimum level of the events without
+	// principal handled according to PrincipalMissingHandling, see
+	// Logger.RequirePrincipal.
+	PrincipalMissingLevel = InfoLevel
+
+	// PrincipalMissingHandling defines how the events without principal of
+	// the loggers requiring one are handled, see Logger.RequirePrincipal.
+	PrincipalMissingHandling = PrincipalMissingFlag
+
+	// UnitFieldSuffix suffixes the keys of the fields added with the unit
+	// methods, like Event.DurMs or Event.ByteSizeMiB, with their unit, as in
+	// latency_ms. If false, the keys are left as is but the values are still
+	// converted to the unit.
+	UnitFieldSuffix = true
+
+	// UnitFieldSeparator separates the keys of the fields added with the
+	// unit methods from their unit suffix.
+	UnitFieldSeparator = "_"
+
 	// ErrorHandler is called whenever zerolog fails to write an event on its
 	// output. If not set, an error is printed on the stderr. This handler must
 	// be thread safe and non-blocking.
 	ErrorHandler func(err error)
 
+	// CmdSecretArgs lists the lower case suffixes of the flags whose value is
+	// redacted by Event.Cmd, as in --password=value or --db-token value. Set
+	// it to nil to log the arguments as is.
+	CmdSecretArgs = []string{"password", "passwd", "secret", "token", "apikey", "api-key", "api_key", "credential", "credentials"}
+
+	// PrintLevel is the level used by the Print, Printf and Println methods
+	// of loggers that have no print level set with Logger.WithPrintLevel.
+	PrintLevel = DebugLevel
+
 	// DefaultContextLogger is returned from Ctx() if there is no logger associated
 	// with the context.
 	DefaultContextLogger *Logger
+
+	// ExitFunc is called with 1 by the Msg method of the fatal events to
+	// terminate the program.
+	ExitFunc = os.Exit
+
+	// DefaultFatalTimeout is the duration Logger.FatalTimeout waits for the
+	// event to be written and flushed when called without one.
+	DefaultFatalTimeout = 5 * time.Second
 )
 
 var (
 	gLevel          = new(int32)
 	disableSampling = new(int32)
+	referenceTime   atomic.Value // time.Time
+	processStart    = time.Now()
 )
 
 // SetGlobalLevel sets the global override for log level. If this
@@ -143,3 +274,19 @@ func DisableSampling(v bool) {
 func samplingDisabled() bool {
 	return atomic.LoadInt32(disableSampling) == 1
 }
+
+// SetReferenceTime sets the reference time of the relative time renderings,
+// like the ConsoleTimeFormatElapsed console time format. A zero t restores
+// the default, the start of the process.
+func SetReferenceTime(t time.Time) {
+	referenceTime.Store(t)
+}
+
+// ReferenceTime returns the reference time set with SetReferenceTime, or the
+// start of the process if none is set.
+func ReferenceTime() time.Time {
+	if t, ok := referenceTime.Load().(time.Time); ok && !t.IsZero() {
+		return t
+	}
+	return processStart
+}
@@ -6,11 +6,9 @@ require (
 	github.com/coreos/go-systemd/v22 v22.5.0
 	github.com/goccy/go-json v0.10.1
 	github.com/mattn/go-colorable v0.1.13
+	github.com/mattn/go-isatty v0.0.17
 	github.com/pkg/errors v0.9.1
 	github.com/rs/xid v1.4.0
 )
 
-require (
-	github.com/mattn/go-isatty v0.0.17 // indirect
-	golang.org/x/sys v0.6.0 // indirect
-)
+require golang.org/x/sys v0.6.0 // indirect
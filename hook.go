@@ -1,5 +1,14 @@
 package zerolog
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
 // Hook defines an interface to a log hook.
 type Hook interface {
 	// Run runs the hook with the event.
@@ -15,6 +24,31 @@ func (h HookFunc) Run(e *Event, level Level, message string) {
 	h(e, level, message)
 }
 
+// FilterHook is a Hook able to abort the emission of an event. When a
+// logger runs a FilterHook, its Filter method is called instead of Run.
+type FilterHook interface {
+	Hook
+	// Filter runs the hook with the event and returns false to discard it,
+	// in which case the hooks following it are not run.
+	Filter(e *Event, level Level, message string) bool
+}
+
+// FilterHookFunc is an adaptor to allow the use of an ordinary function
+// as a FilterHook.
+type FilterHookFunc func(e *Event, level Level, message string) bool
+
+// Run implements the Hook interface.
+func (h FilterHookFunc) Run(e *Event, level Level, message string) {
+	if !h(e, level, message) {
+		e.Discard()
+	}
+}
+
+// Filter implements the FilterHook interface.
+func (h FilterHookFunc) Filter(e *Event, level Level, message string) bool {
+	return h(e, level, message)
+}
+
 // LevelHook applies a different hook for each level.
 type LevelHook struct {
 	NoLevelHook, TraceHook, DebugHook, InfoHook, WarnHook, ErrorHook, FatalHook, PanicHook Hook
@@ -64,3 +98,184 @@ func (h LevelHook) Run(e *Event, level Level, message string) {
 func NewLevelHook() LevelHook {
 	return LevelHook{}
 }
+
+// redactedValue replaces the values of the redacted fields.
+const redactedValue = "[REDACTED]"
+
+// maxProtectedKeys is the maximum number of keys listed in the
+// ProtectedFieldName field.
+const maxProtectedKeys = 32
+
+// redactHook is the Hook returned by RedactHook and RedactAuditHook.
+type redactHook struct {
+	keys  map[string]struct{}
+	audit bool
+}
+
+// Run implements the Hook interface. The fields are redacted as they are
+// added to the event, there is nothing left to do when it is sent.
+func (h redactHook) Run(e *Event, level Level, message string) {}
+
+// RedactHook returns a Hook logging the values of the fields with one of keys
+// as "[REDACTED]", whatever their type. Unlike a hook inspecting the event
+// when it is sent, the values are never written to the event: each field
+// added to it, including by other hooks, by Fields and by the objects added
+// with Object, is checked as it is added.
+//
+// The fields of the context of the logger and the fields of the dicts
+// created with Dict are not redacted.
+func RedactHook(keys ...string) Hook {
+	h := redactHook{keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		h.keys[key] = struct{}{}
+	}
+	return h
+}
+
+// RedactAuditHook returns a Hook redacting the fields with one of keys like
+// RedactHook, which also adds to the events with redacted fields the
+// ProtectedFieldName field, listing their keys, so that the coverage of the
+// redaction can be audited without revealing the values:
+//
+//	{"ssn":"[REDACTED]","user":{"email":"[REDACTED]"},"_protected":["ssn","email"]}
+//
+// The keys of the fields redacted in the objects and in the dicts added with
+// DictFn are listed as well. Each key is listed once, and at most 32 keys
+// are listed.
+func RedactAuditHook(keys ...string) Hook {
+	h := RedactHook(keys...).(redactHook)
+	h.audit = true
+	return h
+}
+
+// redactKeys returns the keys redacted by the RedactHooks of hooks, or nil if
+// there is none, and whether one of them is a RedactAuditHook.
+func redactKeys(hooks []Hook) (keys map[string]struct{}, audit bool) {
+	merged := false
+	for _, h := range hooks {
+		r, ok := h.(redactHook)
+		if !ok {
+			continue
+		}
+		audit = audit || r.audit
+		switch {
+		case keys == nil:
+			keys = r.keys
+		case !merged:
+			m := make(map[string]struct{}, len(keys)+len(r.keys))
+			for key := range keys {
+				m[key] = struct{}{}
+			}
+			keys, merged = m, true
+			fallthrough
+		default:
+			for key := range r.keys {
+				keys[key] = struct{}{}
+			}
+		}
+	}
+	return keys, audit
+}
+
+// clockSkewHook is the Hook returned by ClockSkewHook.
+type clockSkewHook struct {
+	threshold time.Duration
+	base      time.Time // baseline with a monotonic reading
+	skew      int64     // offset of the wall clock at the last step, in ns
+}
+
+// ClockSkewHook returns a Hook detecting the steps of the wall clock larger
+// than threshold, like the ones made by NTP, to help correlating the events
+// of several hosts. The time returned by the timestamp function of each event
+// is compared to the monotonic clock, and the first event following a step
+// gets the ClockSkewFieldName field with the size of the step, in
+// milliseconds, positive if the clock jumped forward.
+func ClockSkewHook(threshold time.Duration) Hook {
+	return &clockSkewHook{threshold: threshold, base: time.Now()}
+}
+
+// Run implements the Hook interface.
+func (h *clockSkewHook) Run(e *Event, level Level, message string) {
+	mono := time.Since(h.base)
+	// Round strips the monotonic readings, to subtract the wall clocks.
+	wall := e.now().Round(0).Sub(h.base.Round(0))
+	skew := int64(wall - mono)
+	for {
+		prev := atomic.LoadInt64(&h.skew)
+		step := time.Duration(skew - prev)
+		if step >= -h.threshold && step <= h.threshold {
+			return
+		}
+		if atomic.CompareAndSwapInt64(&h.skew, prev, skew) {
+			e.Int64(ClockSkewFieldName, step.Round(time.Millisecond).Milliseconds())
+			return
+		}
+	}
+}
+
+// HookRecursionWarnInterval is the minimum interval between the warnings
+// written to os.Stderr when events logged by hooks through their own logger
+// are dropped. The events are detected by their context, which must be the
+// one returned by Event.GetCtx in the hooks, see hookRecursion.
+const HookRecursionWarnInterval = time.Minute
+
+// hookRecursionStderr is the writer of the hook recursion warnings.
+var hookRecursionStderr io.Writer = os.Stderr
+
+// hookRecursionWarned is the time of the last hook recursion warning, in
+// nanoseconds since the Unix epoch.
+var hookRecursionWarned int64
+
+// runningHooksKey is the context key of the hooks running for an event.
+type runningHooksKey struct{}
+
+// runningHooks is a list of the hooks running for an event and for the
+// events that logged it from their hooks, identified by the address of
+// their first hook.
+type runningHooks struct {
+	id     *Hook
+	parent *runningHooks
+}
+
+// withRunningHooks returns ctx, or the background context if nil, marking
+// hooks as running.
+func withRunningHooks(ctx context.Context, hooks []Hook) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	parent, _ := ctx.Value(runningHooksKey{}).(*runningHooks)
+	return context.WithValue(ctx, runningHooksKey{}, &runningHooks{id: &hooks[0], parent: parent})
+}
+
+// hookRecursion returns true if an event with hooks is logged from one of
+// these hooks, through the same logger, with the context of the event
+// running them, which would run them again, possibly endlessly. The event
+// must then be dropped, which is reported to os.Stderr at most once per
+// HookRecursionWarnInterval.
+//
+// The context passed to the hooks, returned by Event.GetCtx, marks them as
+// running. So the hooks logging through their own logger must set it on
+// their events with Event.Ctx for the recursion to be detected:
+//
+//	func (h alertHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
+//	    if level >= zerolog.ErrorLevel {
+//	        h.log.Warn().Ctx(e.GetCtx()).Msg("alert sent")
+//	    }
+//	}
+func hookRecursion(ctx context.Context, hooks []Hook) bool {
+	if ctx == nil {
+		return false
+	}
+	r, _ := ctx.Value(runningHooksKey{}).(*runningHooks)
+	for ; r != nil; r = r.parent {
+		if r.id == &hooks[0] {
+			now := time.Now().UnixNano()
+			last := atomic.LoadInt64(&hookRecursionWarned)
+			if now-last >= int64(HookRecursionWarnInterval) && atomic.CompareAndSwapInt64(&hookRecursionWarned, last, now) {
+				fmt.Fprintf(hookRecursionStderr, "zerolog: dropped events logged by a hook through its own logger\n")
+			}
+			return true
+		}
+	}
+	return false
+}
@@ -2,8 +2,14 @@ package zerolog
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
 	"io"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/x0f5c3/zerolog/log"
 )
@@ -171,6 +177,177 @@ func TestHook(t *testing.T) {
 	}
 }
 
+func TestFilterHook(t *testing.T) {
+	dropHook := FilterHookFunc(func(e *Event, level Level, message string) bool {
+		return level != DebugLevel
+	})
+	ran := 0
+	countHook := HookFunc(func(e *Event, level Level, message string) {
+		ran++
+	})
+
+	out := &bytes.Buffer{}
+	l := New(out).Hook(levelNameHook).Hook(dropHook).Hook(countHook)
+	l.Debug().Msg("dropped")
+	if got := out.String(); got != "" {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, "")
+	}
+	if ran != 0 {
+		t.Errorf("hook following the filter ran %d times, want 0", ran)
+	}
+
+	l.Info().Msg("kept")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","level_name":"info","message":"kept"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if ran != 1 {
+		t.Errorf("hook following the filter ran %d times, want 1", ran)
+	}
+}
+
+func TestHooksAndWithoutHooks(t *testing.T) {
+	out := &bytes.Buffer{}
+	l := New(out).Level(InfoLevel).With().Str("foo", "bar").Logger().Hook(levelNameHook).Hook(simpleHook)
+
+	hooks := l.Hooks()
+	if len(hooks) != 2 {
+		t.Fatalf("Hooks() returned %d hooks, want 2", len(hooks))
+	}
+	hooks[0] = nopHook
+	if got := l.Hooks(); len(got) != 2 || got[0] == nil {
+		t.Errorf("modifying the result of Hooks() changed the logger hooks")
+	}
+
+	l2 := l.WithoutHooks()
+	if got := l2.Hooks(); len(got) != 0 {
+		t.Errorf("WithoutHooks().Hooks() returned %d hooks, want 0", len(got))
+	}
+	if got := len(l.Hooks()); got != 2 {
+		t.Errorf("WithoutHooks() modified the receiver: %d hooks, want 2", got)
+	}
+	l2.Debug().Msg("filtered")
+	l2.Info().Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","foo":"bar"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type redactObject struct{}
+
+func (redactObject) MarshalZerologObject(e *Event) {
+	e.Str("password", "secret").Str("user", "bob")
+}
+
+func TestRedactHook(t *testing.T) {
+	tokenHook := HookFunc(func(e *Event, level Level, message string) {
+		e.Str("token", "secret")
+	})
+
+	out := &bytes.Buffer{}
+	l := New(out).Hook(RedactHook("password", "pin")).Hook(tokenHook).Hook(RedactHook("token"))
+	l.Log().
+		Str("password", "secret").
+		Int("pin", 1234).
+		Str("user", "bob").
+		Object("obj", redactObject{}).
+		Fields(map[string]interface{}{"pin": 1234, "id": 1}).
+		Msg("login")
+
+	want := `{"password":"[REDACTED]","pin":"[REDACTED]","user":"bob","obj":{"password":"[REDACTED]","user":"bob"},` +
+		`"id":1,"pin":"[REDACTED]","token":"[REDACTED]","message":"login"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	l.WithoutHooks().Log().Str("password", "secret").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"password":"secret"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRedactAuditHook(t *testing.T) {
+	tokenHook := HookFunc(func(e *Event, level Level, message string) {
+		e.Str("token", "secret")
+	})
+
+	out := &bytes.Buffer{}
+	l := New(out).Hook(RedactAuditHook("password", "pin")).Hook(tokenHook).Hook(RedactHook("token", "ssn"))
+	l.Log().
+		Int("pin", 1234).
+		Str("user", "bob").
+		Object("obj", redactObject{}).
+		DictFn("dict", func(d *Event) { d.Str("ssn", "123-45-6789") }).
+		Fields(map[string]interface{}{"pin": 1234, "id": 1}).
+		Msg("login")
+
+	want := `{"pin":"[REDACTED]","user":"bob","obj":{"password":"[REDACTED]","user":"bob"},"dict":{"ssn":"[REDACTED]"},` +
+		`"id":1,"pin":"[REDACTED]","token":"[REDACTED]","_protected":["pin","password","ssn","token"],"message":"login"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	New(out).Hook(RedactAuditHook("password")).Log().Str("user", "bob").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"user":"bob"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	New(out).Hook(RedactHook("password")).Log().Str("password", "secret").Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"password":"[REDACTED]"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRedactAuditHookCap(t *testing.T) {
+	keys := make([]string, maxProtectedKeys+8)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%02d", i)
+	}
+	out := &bytes.Buffer{}
+	e := New(out).Hook(RedactAuditHook(keys...)).Log()
+	for _, key := range keys {
+		e.Str(key, "secret")
+	}
+	e.Msg("")
+
+	var evt struct {
+		Protected []string `json:"_protected"`
+	}
+	if err := json.Unmarshal([]byte(decodeIfBinaryToString(out.Bytes())), &evt); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(evt.Protected, keys[:maxProtectedKeys]) {
+		t.Errorf("protected keys = %v, want %v", evt.Protected, keys[:maxProtectedKeys])
+	}
+}
+
+func TestClockSkewHook(t *testing.T) {
+	var offset time.Duration
+	clock := func() time.Time { return time.Now().Add(offset) }
+
+	out := &bytes.Buffer{}
+	l := New(out).With().TimestampFunc(clock).Logger().Hook(ClockSkewHook(time.Second))
+	l.Log().Msg("synced")
+	offset = 5 * time.Second
+	l.Log().Msg("forward")
+	l.Log().Msg("stable")
+	offset = 5*time.Second + 500*time.Millisecond
+	l.Log().Msg("small")
+	offset = 0
+	l.Log().Msg("backward")
+
+	want := `{"message":"synced"}` + "\n" +
+		`{"clock_skew_ms":5000,"message":"forward"}` + "\n" +
+		`{"message":"stable"}` + "\n" +
+		`{"message":"small"}` + "\n" +
+		`{"clock_skew_ms":-5000,"message":"backward"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func BenchmarkHooks(b *testing.B) {
 	logger := New(io.Discard)
 	b.ResetTimer()
@@ -199,3 +376,78 @@ func BenchmarkHooks(b *testing.B) {
 		})
 	})
 }
+
+func TestHookRecursion(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	hookRecursionStderr = stderr
+	defer func() { hookRecursionStderr = os.Stderr }()
+
+	out := &bytes.Buffer{}
+	var l *Logger
+	runs := 0
+	l = New(out).Hook(HookFunc(func(e *Event, level Level, message string) {
+		runs++
+		if runs > 10 {
+			t.Fatal("hook recursion not detected")
+		}
+		l.Warn().Ctx(e.GetCtx()).Msg("from hook")
+	}))
+	l.Info().Msg("outer")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"outer"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if runs != 1 {
+		t.Errorf("hook ran %d times, want 1", runs)
+	}
+	if !strings.Contains(stderr.String(), "hook") {
+		t.Errorf("no warning written, got %q", stderr.String())
+	}
+
+	// Logging through another logger from a hook is allowed.
+	other := &bytes.Buffer{}
+	l2 := New(other).Hook(HookFunc(func(e *Event, level Level, message string) {}))
+	l3 := New(out).Hook(HookFunc(func(e *Event, level Level, message string) {
+		l2.Warn().Ctx(e.GetCtx()).Msg("from hook")
+	}))
+	l3.Info().Msg("outer")
+	if got, want := decodeIfBinaryToString(other.Bytes()), `{"level":"warn","message":"from hook"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHookRecursionOtherGoroutine(t *testing.T) {
+	stderr := &bytes.Buffer{}
+	hookRecursionStderr = stderr
+	defer func() { hookRecursionStderr = os.Stderr }()
+
+	// The hooks of l run on another goroutine while m forwards its events
+	// to l from its hook.
+	running, release := make(chan struct{}), make(chan struct{})
+	out := &lockedBuffer{}
+	l := New(out).Hook(HookFunc(func(e *Event, level Level, message string) {
+		if message == "blocked" {
+			close(running)
+			<-release
+		}
+	}))
+	m := New(io.Discard).Hook(HookFunc(func(e *Event, level Level, message string) {
+		l.Info().Ctx(e.GetCtx()).Msg("forwarded")
+	}))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Info().Msg("blocked")
+	}()
+	<-running
+	m.Info().Msg("outer")
+	close(release)
+	<-done
+
+	want := `{"level":"info","message":"forwarded"}` + "\n" + `{"level":"info","message":"blocked"}` + "\n"
+	if got := decodeIfBinaryToString([]byte(out.String())); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if stderr.Len() > 0 {
+		t.Errorf("unexpected warning %q", stderr.String())
+	}
+}
@@ -0,0 +1,76 @@
+package zerolog
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// httpFields is the LogObjectMarshaler returned by HeaderFields and
+// QueryFields.
+type httpFields struct {
+	m      map[string][]string
+	redact []string
+}
+
+// HeaderFields returns a LogObjectMarshaler adding the HTTP header h, an
+// http.Header, as an object, to log the headers of a request:
+//
+//	log.Info().Object("headers", zerolog.HeaderFields(r.Header, "Authorization", "Cookie")).Msg("")
+//
+// The headers with a single value are added as strings and the others as
+// arrays of strings. The values of the headers named like one of redact,
+// ignoring the case, are replaced by "[REDACTED]". The headers are added in
+// sorted order, up to HTTPFieldsMaxSize.
+//
+// h is a map[string][]string, which http.Header values are assignable to, so
+// that this package doesn't depend on net/http.
+func HeaderFields(h map[string][]string, redact ...string) LogObjectMarshaler {
+	return httpFields{m: h, redact: redact}
+}
+
+// QueryFields returns a LogObjectMarshaler adding the query parameters v as
+// an object, like HeaderFields.
+func QueryFields(v url.Values, redact ...string) LogObjectMarshaler {
+	return httpFields{m: v, redact: redact}
+}
+
+// MarshalZerologObject implements the LogObjectMarshaler interface.
+func (f httpFields) MarshalZerologObject(e *Event) {
+	keys := make([]string, 0, len(f.m))
+	for k := range f.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	size := 0
+	for _, k := range keys {
+		vals := f.m[k]
+		if f.redacted(k) {
+			vals = []string{redactedValue}
+		}
+		n := len(k)
+		for _, v := range vals {
+			n += len(v)
+		}
+		if HTTPFieldsMaxSize > 0 && size+n > HTTPFieldsMaxSize {
+			e.Bool(HTTPFieldsTruncatedFieldName, true)
+			return
+		}
+		size += n
+		if len(vals) == 1 {
+			e.Str(k, vals[0])
+		} else {
+			e.Strs(k, vals)
+		}
+	}
+}
+
+// redacted returns true if the values of key are redacted.
+func (f httpFields) redacted(key string) bool {
+	for _, r := range f.redact {
+		if strings.EqualFold(key, r) {
+			return true
+		}
+	}
+	return false
+}
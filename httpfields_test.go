@@ -0,0 +1,45 @@
+package zerolog
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestHeaderFields(t *testing.T) {
+	h := http.Header{}
+	h.Add("Accept", "text/html")
+	h.Add("Accept", "application/json")
+	h.Set("Authorization", "Bearer secret")
+	h.Set("User-Agent", "test")
+	h["cookie"] = []string{"a=1", "b=2"}
+	out := &bytes.Buffer{}
+	New(out).Log().Object("headers", HeaderFields(h, "authorization", "COOKIE")).Msg("")
+	want := `{"headers":{"Accept":["text/html","application/json"],"Authorization":"[REDACTED]","User-Agent":"test","cookie":"[REDACTED]"}}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestQueryFields(t *testing.T) {
+	v := url.Values{"q": {"zerolog"}, "tag": {"a", "b"}, "Token": {"secret"}}
+	out := &bytes.Buffer{}
+	New(out).Log().Object("query", QueryFields(v, "token")).Msg("")
+	want := `{"query":{"Token":"[REDACTED]","q":"zerolog","tag":["a","b"]}}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestHTTPFieldsMaxSize(t *testing.T) {
+	defer func(size int) { HTTPFieldsMaxSize = size }(HTTPFieldsMaxSize)
+	HTTPFieldsMaxSize = 10
+	v := url.Values{"a": {"1234"}, "b": {"5678"}, "c": {"9"}}
+	out := &bytes.Buffer{}
+	New(out).Log().Object("query", QueryFields(v)).Msg("")
+	want := `{"query":{"a":"1234","b":"5678","_truncated":true}}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
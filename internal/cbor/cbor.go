@@ -12,6 +12,8 @@ const (
 	additionalTypeBoolFalse byte = 20
 	additionalTypeBoolTrue  byte = 21
 	additionalTypeNull      byte = 22
+	additionalTypeUndefined byte = 23
+	additionalTypeSimple8   byte = 24 // simple value in the following byte
 
 	// Integer (+ve and -ve) Sub-types.
 	additionalTypeIntUint8  byte = 24
@@ -26,13 +28,17 @@ const (
 	additionalTypeBreak   byte = 31
 
 	// Tag Sub-types.
-	additionalTypeTimestamp byte = 01
+	additionalTypeDateTimeString byte = 00
+	additionalTypeTimestamp      byte = 01
+	additionalTypeBase64URL      byte = 21 // expected conversion to base64url
+	additionalTypeBase64         byte = 22 // expected conversion to base64
 
 	// Extended Tags - from https://www.iana.org/assignments/cbor-tags/cbor-tags.xhtml
 	additionalTypeTagNetworkAddr   uint16 = 260
 	additionalTypeTagNetworkPrefix uint16 = 261
 	additionalTypeEmbeddedJSON     uint16 = 262
 	additionalTypeTagHexString     uint16 = 263
+	additionalTypeTagSelfDescribe  uint16 = 55799 // marks CBOR data, RFC 8949 section 3.4.6
 
 	// Unspecified number of elements.
 	additionalTypeInfiniteCount byte = 31
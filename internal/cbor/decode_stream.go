@@ -5,17 +5,18 @@ package cbor
 import (
 	"bufio"
 	"bytes"
+	"encoding/base64"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"net"
+	"os"
 	"runtime"
 	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
-
-	"github.com/x0f5c3/zerolog/internal/utils"
 )
 
 var decodeTimeZone *time.Location
@@ -25,6 +26,23 @@ const hexTable = "0123456789abcdef"
 const isFloat32 = 4
 const isFloat64 = 8
 
+// handleErr panics with err, described by msg, if it is not nil. Like the
+// decoding errors, it is recovered by ManyObjCBOR2JSON.
+func handleErr(err error, msg string) {
+	if err != nil {
+		panic(fmt.Errorf("%s: %w", msg, err))
+	}
+}
+
+// reportDecodeErr writes err to os.Stderr if it is not nil, for the
+// functions returning the partially decoded input. This package can't log
+// it with zerolog, which imports it.
+func reportDecodeErr(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "zerolog: can't convert many objects from CBOR to JSON: %v\n", err)
+	}
+}
+
 func readNBytes(src *bufio.Reader, n int) []byte {
 	ret := make([]byte, n)
 	for i := 0; i < n; i++ {
@@ -45,10 +63,10 @@ func readByte(src *bufio.Reader) byte {
 	return b
 }
 
-func decodeIntAdditionalType(src *bufio.Reader, minor byte) int64 {
-	val := int64(0)
+func decodeIntAdditionalType(src *bufio.Reader, minor byte) uint64 {
+	val := uint64(0)
 	if minor <= 23 {
-		val = int64(minor)
+		val = uint64(minor)
 	} else {
 		bytesToRead := 0
 		switch minor {
@@ -66,7 +84,7 @@ func decodeIntAdditionalType(src *bufio.Reader, minor byte) int64 {
 		pb := readNBytes(src, bytesToRead)
 		for i := 0; i < bytesToRead; i++ {
 			val = val * 256
-			val += int64(pb[i])
+			val += uint64(pb[i])
 		}
 	}
 	return val
@@ -81,9 +99,25 @@ func decodeInteger(src *bufio.Reader) int64 {
 	}
 	val := decodeIntAdditionalType(src, minor)
 	if major == 0 {
-		return val
+		return int64(val)
 	}
-	return -1 - val
+	return -1 - int64(val)
+}
+
+// appendInteger appends the decimal representation of the integer of the
+// given major type with the content value val to dst, using the full 64 bits
+// range of both unsigned and negative integers.
+func appendInteger(dst []byte, major byte, val uint64) []byte {
+	if major == majorTypeUnsignedInt {
+		return strconv.AppendUint(dst, val, 10)
+	}
+	if val <= math.MaxInt64 {
+		return strconv.AppendInt(dst, -1-int64(val), 10)
+	}
+	// -1-val is beyond the int64 range.
+	n := new(big.Int).SetUint64(val)
+	n.Add(n, big.NewInt(1)).Neg(n)
+	return n.Append(dst, 10)
 }
 
 func decodeFloat(src *bufio.Reader) (float64, int) {
@@ -247,8 +281,16 @@ func decodeUTF8String(src *bufio.Reader) []byte {
 }
 
 func array2Json(src *bufio.Reader, dst io.Writer) {
+	var d *Decoder
+	d.array2Json(src, dst, 0)
+}
+
+// array2Json decodes the array read from src, nested in depth arrays and
+// maps, to dst.
+func (d *Decoder) array2Json(src *bufio.Reader, dst io.Writer, depth int) {
+	d.checkDepth(depth)
 	_, err := dst.Write([]byte{'['})
-	utils.HandleErr(err, "Failed to write start of array")
+	handleErr(err, "Failed to write start of array")
 	pb := readByte(src)
 	major := pb & maskOutAdditionalType
 	minor := pb & maskOutMajorType
@@ -274,7 +316,7 @@ func array2Json(src *bufio.Reader, dst io.Writer) {
 				break
 			}
 		}
-		cbor2JsonOneObject(src, dst)
+		d.cbor2JsonOneObject(src, dst, depth+1)
 		if unSpecifiedCount {
 			pb, e := src.Peek(1)
 			if e != nil {
@@ -285,17 +327,25 @@ func array2Json(src *bufio.Reader, dst io.Writer) {
 				break
 			}
 			_, err = dst.Write([]byte{','})
-			utils.HandleErr(err, "Failed to write a comma")
+			handleErr(err, "Failed to write a comma")
 		} else if i+1 < len2 {
 			_, err = dst.Write([]byte{','})
-			utils.HandleErr(err, "Failed to write a comma")
+			handleErr(err, "Failed to write a comma")
 		}
 	}
 	_, err = dst.Write([]byte{']'})
-	utils.HandleErr(err, "Failed to write a closing bracket")
+	handleErr(err, "Failed to write a closing bracket")
 }
 
 func map2Json(src *bufio.Reader, dst io.Writer) {
+	var d *Decoder
+	d.map2Json(src, dst, 0)
+}
+
+// map2Json decodes the map read from src, nested in depth arrays and maps,
+// to dst.
+func (d *Decoder) map2Json(src *bufio.Reader, dst io.Writer, depth int) {
+	d.checkDepth(depth)
 	pb := readByte(src)
 	major := pb & maskOutAdditionalType
 	minor := pb & maskOutMajorType
@@ -311,7 +361,7 @@ func map2Json(src *bufio.Reader, dst io.Writer) {
 		l = int(length)
 	}
 	_, err := dst.Write([]byte{'{'})
-	utils.HandleErr(err, "Can't write")
+	handleErr(err, "Can't write")
 	for i := 0; unSpecifiedCount || i < l; i++ {
 		if unSpecifiedCount {
 			pb, e := src.Peek(1)
@@ -323,11 +373,11 @@ func map2Json(src *bufio.Reader, dst io.Writer) {
 				break
 			}
 		}
-		cbor2JsonOneObject(src, dst)
+		d.cbor2JsonOneObject(src, dst, depth+1)
 		if i%2 == 0 {
 			// Even position values are keys.
 			_, err = dst.Write([]byte{':'})
-			utils.HandleErr(err, "Can't write")
+			handleErr(err, "Can't write")
 		} else {
 			if unSpecifiedCount {
 				pb, e := src.Peek(1)
@@ -339,18 +389,25 @@ func map2Json(src *bufio.Reader, dst io.Writer) {
 					break
 				}
 				_, err = dst.Write([]byte{','})
-				utils.HandleErr(err, "Can't write")
+				handleErr(err, "Can't write")
 			} else if i+1 < l {
 				_, err = dst.Write([]byte{','})
-				utils.HandleErr(err, "Can't write")
+				handleErr(err, "Can't write")
 			}
 		}
 	}
 	_, err = dst.Write([]byte{'}'})
-	utils.HandleErr(err, "Can't write")
+	handleErr(err, "Can't write")
 }
 
 func decodeTagData(src *bufio.Reader) []byte {
+	var d *Decoder
+	return d.decodeTagData(src, 0)
+}
+
+// decodeTagData decodes the tagged item read from src, nested in depth
+// arrays and maps.
+func (d *Decoder) decodeTagData(src *bufio.Reader, depth int) []byte {
 	pb := readByte(src)
 	major := pb & maskOutAdditionalType
 	minor := pb & maskOutMajorType
@@ -358,8 +415,22 @@ func decodeTagData(src *bufio.Reader) []byte {
 		panic(fmt.Errorf("major type is: %d in decodeTagData", major))
 	}
 	switch minor {
+	case additionalTypeDateTimeString:
+		return d.decodeDateTimeString(src)
+
 	case additionalTypeTimestamp:
-		return decodeTimeStamp(src)
+		return d.decodeTimeStamp(src)
+
+	case additionalTypeBase64URL, additionalTypeBase64:
+		// RFC 8949 expects base64url without padding, and base64 with it.
+		enc := base64.StdEncoding
+		if minor == additionalTypeBase64URL {
+			enc = base64.RawURLEncoding
+		}
+		octets := decodeString(src, true)
+		ss := []byte{'"'}
+		ss = append(ss, enc.EncodeToString(octets)...)
+		return append(ss, '"')
 
 	// Tag value is larger than 256 (so uint16).
 	case additionalTypeIntUint16:
@@ -372,23 +443,27 @@ func decodeTagData(src *bufio.Reader) []byte {
 			if dataMajor != majorTypeByteString {
 				panic(fmt.Errorf("unsupported embedded Type: %d in decodeEmbeddedJSON", dataMajor))
 			}
-			utils.HandleErr(src.UnreadByte(), "Can't unread byte")
+			handleErr(src.UnreadByte(), "Can't unread byte")
 			return decodeString(src, true)
 
 		case additionalTypeTagNetworkAddr:
 			octets := decodeString(src, true)
 			ss := []byte{'"'}
 			switch len(octets) {
-			case 6: // MAC address.
+			case 6, 8: // MAC address, EUI-48 or EUI-64.
 				ha := net.HardwareAddr(octets)
 				ss = append(append(ss, ha.String()...), '"')
 			case 4: // IPv4 address.
 				fallthrough
 			case 16: // IPv6 address.
 				ip := net.IP(octets)
+				if ip4 := ip.To4(); ip4 != nil {
+					// Render IPv4-mapped IPv6 addresses as dotted quads.
+					ip = ip4
+				}
 				ss = append(append(ss, ip.String()...), '"')
 			default:
-				panic(fmt.Errorf("unexpected Network Address length: %d (expected 4,6,16)", len(octets)))
+				panic(fmt.Errorf("unexpected Network Address length: %d (expected 4,6,8,16)", len(octets)))
 			}
 			return ss
 
@@ -402,7 +477,12 @@ func decodeTagData(src *bufio.Reader) []byte {
 			ip := net.IP(octets)
 			var mask net.IPMask
 			pfxLen := int(val)
-			if len(octets) == 4 {
+			if ip4 := ip.To4(); ip4 != nil && len(ip) == 16 && pfxLen >= 96 {
+				// Render IPv4-mapped IPv6 prefixes as IPv4 ones.
+				ip = ip4
+				pfxLen -= 96
+			}
+			if len(ip) == 4 {
 				mask = net.CIDRMask(pfxLen, 32)
 			} else {
 				mask = net.CIDRMask(pfxLen, 128)
@@ -420,6 +500,12 @@ func decodeTagData(src *bufio.Reader) []byte {
 			}
 			return append(ss, '"')
 
+		case additionalTypeTagSelfDescribe:
+			// The tag only marks the enclosed item as CBOR data.
+			var buf bytes.Buffer
+			d.cbor2JsonOneObject(src, &buf, depth)
+			return buf.Bytes()
+
 		default:
 			panic(fmt.Errorf("unsupported Additional Tag Type: %d in decodeTagData", val))
 		}
@@ -427,14 +513,44 @@ func decodeTagData(src *bufio.Reader) []byte {
 	panic(fmt.Errorf("unsupported Additional Type: %d in decodeTagData", minor))
 }
 
-func decodeTimeStamp(src *bufio.Reader) []byte {
+// decodeDateTimeString decodes the RFC 3339 string of a tag 0 timestamp.
+// It is kept as is, unless a time zone or a reference time is configured.
+func (d *Decoder) decodeDateTimeString(src *bufio.Reader) []byte {
+	pb := readByte(src)
+	major := pb & maskOutAdditionalType
+	minor := pb & maskOutMajorType
+	if major != majorTypeUtf8String {
+		panic(fmt.Errorf("major type is: %d in decodeDateTimeString", major))
+	}
+	length := decodeIntAdditionalType(src, minor)
+	s := string(readNBytes(src, int(length)))
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		panic(fmt.Errorf("invalid RFC 3339 timestamp %q in decodeDateTimeString", s))
+	}
+	if d.relative() {
+		return d.appendRelativeTime(nil, t)
+	}
+	tsb := []byte{'"'}
+	if decodeTimeZone != nil {
+		tsb = t.In(decodeTimeZone).AppendFormat(tsb, NanoTimeFieldFormat)
+	} else {
+		tsb = append(tsb, s...)
+	}
+	return append(tsb, '"')
+}
+
+func (d *Decoder) decodeTimeStamp(src *bufio.Reader) []byte {
 	pb := readByte(src)
 	err := src.UnreadByte()
-	utils.HandleErr(err, "Can't unread byte")
+	handleErr(err, "Can't unread byte")
 	tsMajor := pb & maskOutAdditionalType
 	if tsMajor == majorTypeUnsignedInt || tsMajor == majorTypeNegativeInt {
 		n := decodeInteger(src)
 		t := time.Unix(n, 0)
+		if d.relative() {
+			return d.appendRelativeTime(nil, t)
+		}
 		if decodeTimeZone != nil {
 			t = t.In(decodeTimeZone)
 		} else {
@@ -451,6 +567,9 @@ func decodeTimeStamp(src *bufio.Reader) []byte {
 		n -= float64(secs)
 		n *= 1e9
 		t := time.Unix(secs, int64(n))
+		if d.relative() {
+			return d.appendRelativeTime(nil, t)
+		}
 		if decodeTimeZone != nil {
 			t = t.In(decodeTimeZone)
 		} else {
@@ -466,6 +585,14 @@ func decodeTimeStamp(src *bufio.Reader) []byte {
 }
 
 func decodeSimpleFloat(src *bufio.Reader) []byte {
+	var d *Decoder
+	return d.decodeSimpleFloat(src)
+}
+
+// decodeSimpleFloat decodes a simple value or a float. The undefined value
+// is rendered as null and, unless d is strict, the unassigned simple values
+// as strings like "simple(16)".
+func (d *Decoder) decodeSimpleFloat(src *bufio.Reader) []byte {
 	pb := readByte(src)
 	major := pb & maskOutAdditionalType
 	minor := pb & maskOutMajorType
@@ -477,17 +604,27 @@ func decodeSimpleFloat(src *bufio.Reader) []byte {
 		return []byte("true")
 	case additionalTypeBoolFalse:
 		return []byte("false")
-	case additionalTypeNull:
+	case additionalTypeNull, additionalTypeUndefined:
 		return []byte("null")
+	case additionalTypeSimple8:
+		// The values lower than 32 must use the one byte form.
+		v := readByte(src)
+		if v < 32 {
+			panic(fmt.Errorf("invalid two bytes simple value: %d in decodeSimpleFloat", v))
+		}
+		return d.unassignedSimple(v)
 	case additionalTypeFloat16:
 		fallthrough
 	case additionalTypeFloat32:
 		fallthrough
 	case additionalTypeFloat64:
 		err := src.UnreadByte()
-		utils.HandleErr(err, "Can't unread byte")
+		handleErr(err, "Can't unread byte")
 		v, bc := decodeFloat(src)
 		var ba []byte
+		if (math.IsNaN(v) || math.IsInf(v, 0)) && d != nil && d.numericSpecials {
+			return []byte("null")
+		}
 		switch {
 		case math.IsNaN(v):
 			return []byte("\"NaN\"")
@@ -505,11 +642,31 @@ func decodeSimpleFloat(src *bufio.Reader) []byte {
 		}
 		return ba
 	default:
+		if minor < additionalTypeBoolFalse {
+			return d.unassignedSimple(minor)
+		}
 		panic(fmt.Errorf("invalid Additional Type: %d in decodeSimpleFloat", minor))
 	}
 }
 
+// unassignedSimple renders the unassigned simple value v.
+func (d *Decoder) unassignedSimple(v byte) []byte {
+	if d != nil && d.strictSimple {
+		panic(fmt.Errorf("unassigned simple value: %d in decodeSimpleFloat", v))
+	}
+	b := strconv.AppendUint([]byte(`"simple(`), uint64(v), 10)
+	return append(b, ')', '"')
+}
+
 func cbor2JsonOneObject(src *bufio.Reader, dst io.Writer) {
+	var d *Decoder
+	d.cbor2JsonOneObject(src, dst, 0)
+}
+
+// cbor2JsonOneObject decodes the item read from src, nested in depth arrays
+// and maps, to dst. It panics with an error if the item has containers
+// nested deeper than the maximum depth of d.
+func (d *Decoder) cbor2JsonOneObject(src *bufio.Reader, dst io.Writer, depth int) {
 	pb, e := src.Peek(1)
 	if e != nil {
 		panic(e)
@@ -520,35 +677,36 @@ func cbor2JsonOneObject(src *bufio.Reader, dst io.Writer) {
 	case majorTypeUnsignedInt:
 		fallthrough
 	case majorTypeNegativeInt:
-		n := decodeInteger(src)
-		_, err := dst.Write([]byte(strconv.Itoa(int(n))))
-		utils.HandleErr(err, "Can't write")
+		pb := readByte(src)
+		val := decodeIntAdditionalType(src, pb&maskOutMajorType)
+		_, err := dst.Write(appendInteger(nil, major, val))
+		handleErr(err, "Can't write")
 
 	case majorTypeByteString:
 		s := decodeString(src, false)
 		_, err := dst.Write(s)
-		utils.HandleErr(err, "Can't write")
+		handleErr(err, "Can't write")
 
 	case majorTypeUtf8String:
 		s := decodeUTF8String(src)
 		_, err := dst.Write(s)
-		utils.HandleErr(err, "Can't write")
+		handleErr(err, "Can't write")
 
 	case majorTypeArray:
-		array2Json(src, dst)
+		d.array2Json(src, dst, depth)
 
 	case majorTypeMap:
-		map2Json(src, dst)
+		d.map2Json(src, dst, depth)
 
 	case majorTypeTags:
-		s := decodeTagData(src)
+		s := d.decodeTagData(src, depth)
 		_, err := dst.Write(s)
-		utils.HandleErr(err, "Can't write")
+		handleErr(err, "Can't write")
 
 	case majorTypeSimpleAndFloat:
-		s := decodeSimpleFloat(src)
+		s := d.decodeSimpleFloat(src)
 		_, err := dst.Write(s)
-		utils.HandleErr(err, "Can't write")
+		handleErr(err, "Can't write")
 	}
 }
 
@@ -556,7 +714,7 @@ func moreBytesToRead(src *bufio.Reader) bool {
 	_, e := src.ReadByte()
 	if e == nil {
 		err := src.UnreadByte()
-		utils.HandleErr(err, "Can't unread byte")
+		handleErr(err, "Can't unread byte")
 		return true
 	}
 	return false
@@ -571,6 +729,13 @@ func moreBytesToRead(src *bufio.Reader) bool {
 // The child functions will generate a panic when error is encountered and
 // this function will recover non-runtime Errors and return the reason as error.
 func ManyObjCBOR2JSON(src io.Reader, dst io.Writer) (err error) {
+	var d *Decoder
+	return d.ManyObjCBOR2JSON(src, dst)
+}
+
+// ManyObjCBOR2JSON is like the ManyObjCBOR2JSON function, rendering the
+// timestamps as configured by the options of d.
+func (d *Decoder) ManyObjCBOR2JSON(src io.Reader, dst io.Writer) (err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			if _, ok := r.(runtime.Error); ok {
@@ -581,9 +746,9 @@ func ManyObjCBOR2JSON(src io.Reader, dst io.Writer) (err error) {
 	}()
 	bufRdr := bufio.NewReader(src)
 	for moreBytesToRead(bufRdr) {
-		cbor2JsonOneObject(bufRdr, dst)
+		d.cbor2JsonOneObject(bufRdr, dst, 0)
 		_, err := dst.Write([]byte("\n"))
-		utils.HandleErr(err, "Can't write")
+		handleErr(err, "Can't write")
 	}
 	return nil
 }
@@ -606,7 +771,7 @@ func DecodeIfBinaryToString(in []byte) string {
 	if binaryFmt(in) {
 		var b bytes.Buffer
 		err := ManyObjCBOR2JSON(strings.NewReader(string(in)), &b)
-		utils.HandleErr(err, "Can't convert many objects from CBOR to JSON")
+		reportDecodeErr(err)
 		return b.String()
 	}
 	return string(in)
@@ -623,13 +788,23 @@ func DecodeObjectToStr(in []byte) string {
 	return string(in)
 }
 
+// DecodeItemToStr decodes the single data item in, like a string or an
+// integer, and returns its JSON rendering. Unlike DecodeObjectToStr, in is
+// always decoded, as most items don't start like a binary log. It panics if
+// in is not a valid data item.
+func DecodeItemToStr(in []byte) string {
+	var b bytes.Buffer
+	cbor2JsonOneObject(getReader(string(in)), &b)
+	return b.String()
+}
+
 // DecodeIfBinaryToBytes checks if the input is a binary format, if so,
 // it will decode all Objects and return the decoded string as byte array.
 func DecodeIfBinaryToBytes(in []byte) []byte {
 	if binaryFmt(in) {
 		var b bytes.Buffer
 		err := ManyObjCBOR2JSON(bytes.NewReader(in), &b)
-		utils.HandleErr(err, "Can't convert many objects from CBOR to JSON")
+		reportDecodeErr(err)
 		return b.Bytes()
 	}
 	return in
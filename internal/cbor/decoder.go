@@ -0,0 +1,108 @@
+package cbor
+
+import (
+	"fmt"
+	"time"
+)
+
+// Decoder converts CBOR encoded logs to JSON like ManyObjCBOR2JSON, with
+// options. A nil *Decoder uses the default options. A Decoder in auto
+// reference mode is not safe for concurrent use.
+type Decoder struct {
+	ref             time.Time
+	auto            bool
+	strictSimple    bool
+	numericSpecials bool
+	maxDepth        int
+}
+
+// DefaultMaxDepth is the maximum nesting depth of the arrays and maps
+// decoded by the Decoders without the WithMaxDepth option.
+const DefaultMaxDepth = 1000
+
+// DecoderOption configures a Decoder.
+type DecoderOption func(d *Decoder)
+
+// WithReferenceTime renders the timestamps relative to ref, as a signed
+// duration like "+1.5s". A zero ref selects the auto mode, where the
+// reference is the first timestamp decoded by the Decoder, so that
+// historical logs are rendered relative to their own first event.
+func WithReferenceTime(ref time.Time) DecoderOption {
+	return func(d *Decoder) {
+		d.ref = ref
+		d.auto = ref.IsZero()
+	}
+}
+
+// WithStrictSimpleValues makes the unassigned simple values, like
+// simple(16), decoding errors instead of strings like "simple(16)".
+func WithStrictSimpleValues() DecoderOption {
+	return func(d *Decoder) {
+		d.strictSimple = true
+	}
+}
+
+// WithNumericSpecials renders the NaN and infinite floats as null, like
+// many JSON encoders, so that strict JSON consumers expecting numbers accept
+// them, instead of the more readable strings "NaN", "+Inf" and "-Inf".
+func WithNumericSpecials() DecoderOption {
+	return func(d *Decoder) {
+		d.numericSpecials = true
+	}
+}
+
+// WithMaxDepth limits the nesting of the arrays and maps decoded to n
+// levels, DefaultMaxDepth if n is 0 or less. Deeper items, which could
+// exhaust the stack, are decoding errors.
+func WithMaxDepth(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.maxDepth = n
+	}
+}
+
+// NewDecoder creates a Decoder with the given options. Without options,
+// timestamps are rendered as absolute times.
+func NewDecoder(options ...DecoderOption) *Decoder {
+	d := &Decoder{}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+// checkDepth panics with an error if an array or a map nested in depth
+// arrays and maps exceeds the maximum depth of d.
+func (d *Decoder) checkDepth(depth int) {
+	max := DefaultMaxDepth
+	if d != nil && d.maxDepth > 0 {
+		max = d.maxDepth
+	}
+	if depth >= max {
+		panic(fmt.Errorf("arrays and maps nested deeper than %d levels", max))
+	}
+}
+
+// relative returns true if d renders timestamps relative to a reference.
+func (d *Decoder) relative() bool {
+	return d != nil && (d.auto || !d.ref.IsZero())
+}
+
+// appendRelativeTime appends t relative to the reference time of d to dst,
+// as a JSON string.
+func (d *Decoder) appendRelativeTime(dst []byte, t time.Time) []byte {
+	if d.ref.IsZero() {
+		d.ref = t
+	}
+	dst = append(dst, '"')
+	dst = appendElapsed(dst, t.Sub(d.ref))
+	return append(dst, '"')
+}
+
+// appendElapsed appends the duration elapsed since a reference time to dst,
+// signed, like "+1.5s" or "-2m0s".
+func appendElapsed(dst []byte, elapsed time.Duration) []byte {
+	if elapsed >= 0 {
+		dst = append(dst, '+')
+	}
+	return append(dst, elapsed.String()...)
+}
@@ -2,7 +2,9 @@ package cbor
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/hex"
+	"strings"
 	"testing"
 	"time"
 )
@@ -17,6 +19,31 @@ func TestDecodeInteger(t *testing.T) {
 	}
 }
 
+func TestDecodeInteger64Cbor2Json(t *testing.T) {
+	// These values don't fit in a 32 bits int, nor most of them in an int64.
+	for _, tc := range []struct {
+		binary string
+		json   string
+	}{
+		{"\x1a\xff\xff\xff\xff", "4294967295"},
+		{"\x1b\x00\x00\x00\x01\x00\x00\x00\x00", "4294967296"},
+		{"\x3a\x80\x00\x00\x00", "-2147483649"},
+		{"\x1b\x7f\xff\xff\xff\xff\xff\xff\xff", "9223372036854775807"},
+		{"\x1b\x80\x00\x00\x00\x00\x00\x00\x00", "9223372036854775808"},
+		{"\x1b\xff\xff\xff\xff\xff\xff\xff\xfe", "18446744073709551614"},
+		{"\x1b\xff\xff\xff\xff\xff\xff\xff\xff", "18446744073709551615"},
+		{"\x3b\x7f\xff\xff\xff\xff\xff\xff\xff", "-9223372036854775808"},
+		{"\x3b\x80\x00\x00\x00\x00\x00\x00\x00", "-9223372036854775809"},
+		{"\x3b\xff\xff\xff\xff\xff\xff\xff\xff", "-18446744073709551616"},
+	} {
+		buf := bytes.NewBuffer([]byte{})
+		cbor2JsonOneObject(getReader(tc.binary), buf)
+		if buf.String() != tc.json {
+			t.Errorf("cbor2JsonOneObject(0x%s)=%s, want: %s", hex.EncodeToString([]byte(tc.binary)), buf.String(), tc.json)
+		}
+	}
+}
+
 func TestDecodeString(t *testing.T) {
 	for _, tt := range encodeStringTests {
 		got := decodeUTF8String(getReader(tt.binary))
@@ -105,6 +132,77 @@ func TestDecodeBool(t *testing.T) {
 	}
 }
 
+func TestDecodeSimpleValues(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		bin    string
+		json   string // "" if the value is invalid
+		strict bool   // the value is valid in strict mode
+	}{
+		{"undefined", "\xf7", "null", true},
+		{"simple(0)", "\xe0", `"simple(0)"`, false},
+		{"simple(19)", "\xf3", `"simple(19)"`, false},
+		{"simple(32)", "\xf8\x20", `"simple(32)"`, false},
+		{"simple(255)", "\xf8\xff", `"simple(255)"`, false},
+		{"two bytes simple(16)", "\xf8\x10", "", false},
+		{"two bytes true", "\xf8\x15", "", false},
+		{"reserved 28", "\xfc", "", false},
+		{"reserved 30", "\xfe", "", false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			in := "\xbf\x61v" + tt.bin + "\xff"
+			for _, strict := range []bool{false, true} {
+				d := NewDecoder()
+				if strict {
+					d = NewDecoder(WithStrictSimpleValues())
+				}
+				buf := bytes.NewBuffer([]byte{})
+				err := d.ManyObjCBOR2JSON(strings.NewReader(in), buf)
+				if tt.json == "" || strict && !tt.strict {
+					if err == nil {
+						t.Errorf("ManyObjCBOR2JSON(0x%s) strict=%v: no error, output %s", hex.EncodeToString([]byte(in)), strict, buf.String())
+					}
+					continue
+				}
+				if want := `{"v":` + tt.json + "}\n"; err != nil || buf.String() != want {
+					t.Errorf("ManyObjCBOR2JSON(0x%s) strict=%v = %s, %v, want: %s", hex.EncodeToString([]byte(in)), strict, buf.String(), err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeNumericSpecials(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		bin     string
+		quoted  string
+		numeric string
+	}{
+		{"float32 NaN", float32Nan, `"NaN"`, "null"},
+		{"float64 NaN", float64Nan, `"NaN"`, "null"},
+		{"float32 +Inf", float32PosInfinity, `"+Inf"`, "null"},
+		{"float64 -Inf", float64NegInfinity, `"-Inf"`, "null"},
+		{"float64", "\xfb\x3f\xf8\x00\x00\x00\x00\x00\x00", "1.5", "1.5"},
+		{"array", "\x82" + float64Nan + "\x01", `["NaN",1]`, "[null,1]"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			in := "\xbf\x61v" + tt.bin + "\xff"
+			for _, d := range []*Decoder{nil, NewDecoder(), NewDecoder(WithNumericSpecials())} {
+				want := tt.quoted
+				if d != nil && d.numericSpecials {
+					want = tt.numeric
+				}
+				buf := bytes.NewBuffer([]byte{})
+				err := d.ManyObjCBOR2JSON(strings.NewReader(in), buf)
+				if want = `{"v":` + want + "}\n"; err != nil || buf.String() != want {
+					t.Errorf("ManyObjCBOR2JSON(0x%s) numericSpecials=%v = %s, %v, want: %s", hex.EncodeToString([]byte(in)), d != nil && d.numericSpecials, buf.String(), err, want)
+				}
+			}
+		})
+	}
+}
+
 func TestDecodeFloat(t *testing.T) {
 	for _, tc := range float32TestCases {
 		got, _ := decodeFloat(getReader(tc.binary))
@@ -136,6 +234,49 @@ func TestDecodeTimestamp(t *testing.T) {
 	}
 }
 
+func TestDecodeDateTimeString(t *testing.T) {
+	defer func(tz *time.Location) { decodeTimeZone = tz }(decodeTimeZone)
+	decodeTimeZone = nil
+	in := "\xbf\x62ts\xc0\x74" + "2001-02-03T04:05:06Z" + "\xff"
+	buf := &bytes.Buffer{}
+	if err := ManyObjCBOR2JSON(getReader(in), buf); err != nil {
+		t.Fatalf("ManyObjCBOR2JSON() error = %v", err)
+	}
+	if got, want := buf.String(), `{"ts":"2001-02-03T04:05:06Z"}`+"\n"; got != want {
+		t.Errorf("ManyObjCBOR2JSON() = %s, want: %s", got, want)
+	}
+
+	decodeTimeZone = time.FixedZone("UTC+1", 3600)
+	if got, want := string(decodeTagData(getReader("\xc0\x74"+"2001-02-03T04:05:06Z"))), `"2001-02-03T05:05:06+01:00"`; got != want {
+		t.Errorf("decodeTagData() = %s, want: %s", got, want)
+	}
+
+	in = "\xbf\x62ts\xc0\x67" + "garbage" + "\xff"
+	buf.Reset()
+	err := ManyObjCBOR2JSON(getReader(in), buf)
+	if want := `invalid RFC 3339 timestamp "garbage" in decodeDateTimeString`; err == nil || err.Error() != want {
+		t.Errorf("ManyObjCBOR2JSON() error = %v, want: %s", err, want)
+	}
+}
+
+func TestDecodeBase64(t *testing.T) {
+	tests := []struct {
+		enc  *base64.Encoding
+		want string
+	}{
+		{base64.StdEncoding, `"+/8="`},
+		{base64.RawStdEncoding, `"+/8="`},
+		{base64.URLEncoding, `"-_8"`},
+		{base64.RawURLEncoding, `"-_8"`},
+	}
+	for _, tt := range tests {
+		b := Encoder{}.AppendBase64(nil, []byte{0xfb, 0xff}, tt.enc)
+		if got := string(decodeTagData(getReader(string(b)))); got != tt.want {
+			t.Errorf("decodeTagData(0x%s)=%s, want:%s", hex.EncodeToString(b), got, tt.want)
+		}
+	}
+}
+
 func TestDecodeNetworkAddr(t *testing.T) {
 	for _, tc := range ipAddrTestCases {
 		d1 := decodeTagData(getReader(tc.binary))
@@ -145,6 +286,24 @@ func TestDecodeNetworkAddr(t *testing.T) {
 	}
 }
 
+func TestDecodeIPv4MappedAddr(t *testing.T) {
+	tests := []struct {
+		binary string
+		text   string
+	}{
+		// 16 byte IPv4-mapped address.
+		{"\xd9\x01\x04\x50\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff\x01\x02\x03\x04", "\"1.2.3.4\""},
+		// 16 byte IPv4-mapped prefix.
+		{"\xd9\x01\x05\xa1\x50\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\xff\xff\x01\x02\x03\x00\x18\x78", "\"1.2.3.0/24\""},
+	}
+	for _, tc := range tests {
+		d1 := decodeTagData(getReader(tc.binary))
+		if string(d1) != tc.text {
+			t.Errorf("decodeTagData(0x%s)=%s, want:%s", hex.EncodeToString([]byte(tc.binary)), d1, tc.text)
+		}
+	}
+}
+
 func TestDecodeMACAddr(t *testing.T) {
 	for _, tc := range macAddrTestCases {
 		d1 := decodeTagData(getReader(tc.binary))
@@ -181,6 +340,17 @@ func TestDecodeCbor2Json(t *testing.T) {
 	}
 }
 
+func TestDecodeSelfDescribe(t *testing.T) {
+	for _, tc := range compositeCborTestCases {
+		buf := bytes.NewBuffer([]byte{})
+		in := append([]byte("\xd9\xd9\xf7"), tc.binary...)
+		err := ManyObjCBOR2JSON(bytes.NewReader(in), buf)
+		if buf.String() != tc.json || err != nil {
+			t.Errorf("ManyObjCBOR2JSON(0x%s)=%s, %v, want: %s", hex.EncodeToString(in), buf.String(), err, tc.json)
+		}
+	}
+}
+
 var negativeCborTestCases = []struct {
 	binary []byte
 	errStr string
@@ -203,3 +373,65 @@ func TestDecodeNegativeCbor2Json(t *testing.T) {
 		}
 	}
 }
+
+func TestDecoderReferenceTime(t *testing.T) {
+	t0 := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	var enc Encoder
+	var in []byte
+	for _, ts := range []time.Time{t0, t0.Add(1500 * time.Millisecond), t0.Add(-time.Minute)} {
+		in = enc.AppendBeginMarker(in)
+		in = enc.AppendTime(enc.AppendKey(in, "time"), ts, "")
+		in = enc.AppendEndMarker(in)
+	}
+
+	decodeTimeZone = time.UTC
+	for _, tt := range []struct {
+		name string
+		d    *Decoder
+		want string
+	}{
+		{"explicit", NewDecoder(WithReferenceTime(t0.Add(-time.Second))), `{"time":"+1s"}` + "\n" + `{"time":"+2.5s"}` + "\n" + `{"time":"-59s"}` + "\n"},
+		{"auto", NewDecoder(WithReferenceTime(time.Time{})), `{"time":"+0s"}` + "\n" + `{"time":"+1.5s"}` + "\n" + `{"time":"-1m0s"}` + "\n"},
+		{"unset", NewDecoder(), `{"time":"2001-02-03T04:05:06Z"}` + "\n" + `{"time":"2001-02-03T04:05:07.5Z"}` + "\n" + `{"time":"2001-02-03T04:04:06Z"}` + "\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.NewBuffer([]byte{})
+			if err := tt.d.ManyObjCBOR2JSON(bytes.NewReader(in), buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("ManyObjCBOR2JSON()=%s, want: %s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecoderMaxDepth(t *testing.T) {
+	// nested returns the CBOR of depth containers, each holding the next
+	// one, around the integer 1.
+	nested := func(container string, depth int) string {
+		return strings.Repeat(container, depth) + "\x01"
+	}
+	for _, tt := range []struct {
+		name    string
+		d       *Decoder
+		in      string
+		wantErr bool
+	}{
+		{"array at limit", NewDecoder(WithMaxDepth(3)), nested("\x81", 3), false},
+		{"array beyond limit", NewDecoder(WithMaxDepth(3)), nested("\x81", 4), true},
+		{"map at limit", NewDecoder(WithMaxDepth(3)), nested("\xbf\x61a", 3) + strings.Repeat("\xff", 3), false},
+		{"map beyond limit", NewDecoder(WithMaxDepth(3)), nested("\xbf\x61a", 4) + strings.Repeat("\xff", 4), true},
+		{"self-described beyond limit", NewDecoder(WithMaxDepth(3)), nested("\x81\xd9\xd9\xf7", 4), true},
+		{"default limit", nil, nested("\x81", DefaultMaxDepth), false},
+		{"beyond default limit", nil, nested("\x81", 1<<20), true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			err := tt.d.ManyObjCBOR2JSON(strings.NewReader(tt.in), buf)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ManyObjCBOR2JSON() error = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package cbor
+
+// This file contains code to find the bounds of the CBOR data items of an
+// encoded stream without decoding them.
+
+// ItemLen returns the length of the data item at the start of p, or false
+// if p doesn't start with a complete and well formed item.
+func ItemLen(p []byte) (int, bool) {
+	n, arg, indefinite, ok := itemHead(p)
+	if !ok {
+		return 0, false
+	}
+	switch major := p[0] & maskOutAdditionalType; major {
+	case majorTypeUnsignedInt, majorTypeNegativeInt:
+		if indefinite {
+			return 0, false
+		}
+		return n, true
+	case majorTypeByteString, majorTypeUtf8String:
+		if indefinite {
+			return itemsLen(p, n, 0, true)
+		}
+		if uint64(len(p)-n) < arg {
+			return 0, false
+		}
+		return n + int(arg), true
+	case majorTypeArray, majorTypeMap:
+		if major == majorTypeMap {
+			arg *= 2
+		}
+		return itemsLen(p, n, arg, indefinite)
+	case majorTypeTags:
+		if indefinite {
+			return 0, false
+		}
+		m, ok := ItemLen(p[n:])
+		return n + m, ok
+	default: // majorTypeSimpleAndFloat
+		// A break is only valid inside an indefinite length item.
+		if indefinite {
+			return 0, false
+		}
+		return n, true
+	}
+}
+
+// MapValue returns the bounds of the value of the key field in the map at
+// the start of p, or false if p doesn't start with a map holding such a
+// field.
+func MapValue(p []byte, key string) (start, end int, ok bool) {
+	if len(p) == 0 || p[0]&maskOutAdditionalType != majorTypeMap {
+		return 0, 0, false
+	}
+	i, count, indefinite, ok := itemHead(p)
+	if !ok {
+		return 0, 0, false
+	}
+	want := string(Encoder{}.AppendString(nil, key))
+	for ; indefinite || count > 0; count-- {
+		if i >= len(p) || indefinite && p[i] == majorTypeSimpleAndFloat|additionalTypeBreak {
+			return 0, 0, false
+		}
+		k, ok := ItemLen(p[i:])
+		if !ok {
+			return 0, 0, false
+		}
+		v, ok := ItemLen(p[i+k:])
+		if !ok {
+			return 0, 0, false
+		}
+		if string(p[i:i+k]) == want {
+			return i + k, i + k + v, true
+		}
+		i += k + v
+	}
+	return 0, 0, false
+}
+
+// itemHead decodes the head of the data item at the start of p: its
+// length, its argument and whether the item has an indefinite length.
+func itemHead(p []byte) (n int, arg uint64, indefinite bool, ok bool) {
+	if len(p) == 0 {
+		return 0, 0, false, false
+	}
+	minor := p[0] & maskOutMajorType
+	switch {
+	case minor < additionalTypeIntUint8:
+		return 1, uint64(minor), false, true
+	case minor == additionalTypeInfiniteCount:
+		return 1, 0, true, true
+	case minor > additionalTypeIntUint64:
+		return 0, 0, false, false
+	}
+	n = 1 + 1<<(minor-additionalTypeIntUint8)
+	if len(p) < n {
+		return 0, 0, false, false
+	}
+	for _, b := range p[1:n] {
+		arg = arg<<8 | uint64(b)
+	}
+	return n, arg, false, true
+}
+
+// itemsLen returns the length of the item whose head of length n at the
+// start of p is followed by count items, or by items up to a break if
+// indefinite is true.
+func itemsLen(p []byte, n int, count uint64, indefinite bool) (int, bool) {
+	for ; indefinite || count > 0; count-- {
+		if n >= len(p) {
+			return 0, false
+		}
+		if indefinite && p[n] == majorTypeSimpleAndFloat|additionalTypeBreak {
+			return n + 1, true
+		}
+		m, ok := ItemLen(p[n:])
+		if !ok {
+			return 0, false
+		}
+		n += m
+	}
+	return n, true
+}
@@ -0,0 +1,87 @@
+package cbor
+
+import (
+	"testing"
+	"time"
+)
+
+func TestItemLen(t *testing.T) {
+	var enc Encoder
+	event := enc.AppendBeginMarker(nil)
+	event = enc.AppendKey(event, "time")
+	event = enc.AppendTime(event, time.Unix(1500000000, 500), "")
+	event = enc.AppendKey(event, "ints")
+	event = enc.AppendInts(event, []int{1, 300, -70000})
+	event = enc.AppendKey(event, "msg")
+	event = enc.AppendString(event, "hello")
+	event = enc.AppendEndMarker(event)
+
+	tests := []struct {
+		name string
+		in   string
+		want int
+		ok   bool
+	}{
+		{"small int", "\x05", 1, true},
+		{"uint16", "\x19\x01\x2c", 3, true},
+		{"truncated uint16", "\x19\x01", 0, false},
+		{"string", "\x64IETF", 5, true},
+		{"truncated string", "\x64IET", 0, false},
+		{"indefinite string", "\x7f\x62ab\x61c\xff", 7, true},
+		{"array", "\x83\x01\x02\x03", 4, true},
+		{"map", "\xa1\x61a\x01", 4, true},
+		{"tag", "\xc1\x1a\x59\x68\x2f\x00", 6, true},
+		{"float64", "\xfb\x3f\xf0\x00\x00\x00\x00\x00\x00", 9, true},
+		{"lone break", "\xff", 0, false},
+		{"reserved", "\x1c", 0, false},
+		{"event", string(event), len(event), true},
+		{"two events", string(event) + string(event), len(event), true},
+		{"truncated event", string(event[:len(event)-1]), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ItemLen([]byte(tt.in))
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("ItemLen(%q) = %d, %v, want %d, %v", tt.in, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestMapValue(t *testing.T) {
+	var enc Encoder
+	event := enc.AppendBeginMarker(nil)
+	event = enc.AppendKey(event, "level")
+	event = enc.AppendString(event, "time")
+	event = enc.AppendKey(event, "time")
+	ts := enc.AppendTime(nil, time.Unix(1500000000, 0), "")
+	event = append(event, ts...)
+	event = enc.AppendEndMarker(event)
+
+	start, end, ok := MapValue(event, "time")
+	if !ok || string(event[start:end]) != string(ts) {
+		t.Errorf("MapValue() = %d, %d, %v, want %q", start, end, ok, ts)
+	}
+	if _, _, ok := MapValue(event, "message"); ok {
+		t.Errorf("MapValue() found a missing key")
+	}
+	if _, _, ok := MapValue([]byte("\x83\x01\x02\x03"), "time"); ok {
+		t.Errorf("MapValue() found a key in an array")
+	}
+}
+
+func TestDecodeItemToStr(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want string
+	}{
+		{"\x64IETF", `"IETF"`},
+		{"\x18\x2a", "42"},
+		{"\xf5", "true"},
+		{"\x82\x01\x61a", `[1,"a"]`},
+	} {
+		if got := DecodeItemToStr([]byte(tt.in)); got != tt.want {
+			t.Errorf("DecodeItemToStr(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package cbor
 
 import (
+	"encoding/base64"
 	"fmt"
 	"math"
 	"net"
@@ -448,11 +449,21 @@ func (e Encoder) AppendType(dst []byte, i interface{}) []byte {
 }
 
 // AppendIPAddr encodes and inserts an IP Address (IPv4 or IPv6).
+// IPv4 addresses, including IPv4-mapped IPv6 ones, are encoded on 4 bytes.
 func (e Encoder) AppendIPAddr(dst []byte, ip net.IP) []byte {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return e.AppendNetworkAddr(dst, ip)
+}
+
+// AppendNetworkAddr encodes and inserts a network address: an IPv4 address
+// (4 bytes), a MAC address (6 bytes) or an IPv6 address (16 bytes).
+func (e Encoder) AppendNetworkAddr(dst []byte, addr []byte) []byte {
 	dst = append(dst, majorTypeTags|additionalTypeIntUint16)
 	dst = append(dst, byte(additionalTypeTagNetworkAddr>>8))
 	dst = append(dst, byte(additionalTypeTagNetworkAddr&0xff))
-	return e.AppendBytes(dst, ip)
+	return e.AppendBytes(dst, addr)
 }
 
 // AppendIPPrefix encodes and inserts an IP Address Prefix (Address + Mask Length).
@@ -464,17 +475,34 @@ func (e Encoder) AppendIPPrefix(dst []byte, pfx net.IPNet) []byte {
 	// Prefix is a tuple (aka MAP of 1 pair of elements) -
 	// first element is prefix, second is mask length.
 	dst = append(dst, majorTypeMap|0x1)
-	dst = e.AppendBytes(dst, pfx.IP)
-	maskLen, _ := pfx.Mask.Size()
+	ip := pfx.IP
+	maskLen, bits := pfx.Mask.Size()
+	if ip4 := ip.To4(); ip4 != nil && (bits == 32 || maskLen >= 96) {
+		// Encode IPv4 prefixes, including IPv4-mapped IPv6 ones, on 4 bytes.
+		ip = ip4
+		if bits == 128 {
+			maskLen -= 96
+		}
+	}
+	dst = e.AppendBytes(dst, ip)
 	return e.AppendUint8(dst, uint8(maskLen))
 }
 
 // AppendMACAddr encodes and inserts a Hardware (MAC) address.
 func (e Encoder) AppendMACAddr(dst []byte, ha net.HardwareAddr) []byte {
-	dst = append(dst, majorTypeTags|additionalTypeIntUint16)
-	dst = append(dst, byte(additionalTypeTagNetworkAddr>>8))
-	dst = append(dst, byte(additionalTypeTagNetworkAddr&0xff))
-	return e.AppendBytes(dst, ha)
+	return e.AppendNetworkAddr(dst, ha)
+}
+
+// AppendBase64 adds a TAG and inserts val as bytes to be rendered as
+// base64url if enc is base64.URLEncoding or base64.RawURLEncoding, and as
+// base64 otherwise.
+func (e Encoder) AppendBase64(dst, val []byte, enc *base64.Encoding) []byte {
+	tag := additionalTypeBase64
+	if enc == base64.URLEncoding || enc == base64.RawURLEncoding {
+		tag = additionalTypeBase64URL
+	}
+	dst = append(dst, majorTypeTags|tag)
+	return e.AppendBytes(dst, val)
 }
 
 // AppendHex adds a TAG and inserts a hex bytes as a string.
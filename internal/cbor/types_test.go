@@ -185,6 +185,7 @@ var ipAddrTestCases = []struct {
 	binary string // CBOR representation of ipaddr
 }{
 	{net.IP{10, 0, 0, 1}, "\"10.0.0.1\"", "\xd9\x01\x04\x44\x0a\x00\x00\x01"},
+	{net.IPv4(10, 0, 0, 1), "\"10.0.0.1\"", "\xd9\x01\x04\x44\x0a\x00\x00\x01"},
 	{net.IP{0x20, 0x01, 0x0d, 0xb8, 0x85, 0xa3, 0x0, 0x0, 0x0, 0x0, 0x8a, 0x2e, 0x03, 0x70, 0x73, 0x34},
 		"\"2001:db8:85a3::8a2e:370:7334\"",
 		"\xd9\x01\x04\x50\x20\x01\x0d\xb8\x85\xa3\x00\x00\x00\x00\x8a\x2e\x03\x70\x73\x34"},
@@ -209,6 +210,7 @@ var macAddrTestCases = []struct {
 }{
 	{net.HardwareAddr{0x12, 0x34, 0x56, 0x78, 0x90, 0xab}, "\"12:34:56:78:90:ab\"", "\xd9\x01\x04\x46\x12\x34\x56\x78\x90\xab"},
 	{net.HardwareAddr{0x20, 0x01, 0x0d, 0xb8, 0x85, 0xa3}, "\"20:01:0d:b8:85:a3\"", "\xd9\x01\x04\x46\x20\x01\x0d\xb8\x85\xa3"},
+	{net.HardwareAddr{0x02, 0x00, 0x5e, 0x10, 0x00, 0x00, 0x00, 0x01}, "\"02:00:5e:10:00:00:00:01\"", "\xd9\x01\x04\x48\x02\x00\x5e\x10\x00\x00\x00\x01"},
 }
 
 func TestAppendMACAddr(t *testing.T) {
@@ -231,6 +233,10 @@ var IPPrefixTestCases = []struct {
 	{net.IPNet{IP: net.IP{0, 0, 0, 0}, Mask: net.CIDRMask(0, 32)}, "\"0.0.0.0/0\"", "\xd9\x01\x05\xa1\x44\x00\x00\x00\x00\x00"},
 	{net.IPNet{IP: net.IP{192, 168, 0, 100}, Mask: net.CIDRMask(24, 32)}, "\"192.168.0.100/24\"",
 		"\xd9\x01\x05\xa1\x44\xc0\xa8\x00\x64\x18\x18"},
+	{net.IPNet{IP: net.IPv4(192, 168, 0, 100), Mask: net.CIDRMask(24, 32)}, "\"192.168.0.100/24\"",
+		"\xd9\x01\x05\xa1\x44\xc0\xa8\x00\x64\x18\x18"},
+	{net.IPNet{IP: net.IPv4(192, 168, 0, 100), Mask: net.CIDRMask(120, 128)}, "\"192.168.0.100/24\"",
+		"\xd9\x01\x05\xa1\x44\xc0\xa8\x00\x64\x18\x18"},
 }
 
 func TestAppendIPPrefix(t *testing.T) {
@@ -8,6 +8,10 @@ package json
 // you might get a nil pointer dereference panic at runtime.
 var MarshalFunc func(v interface{}) ([]byte, error)
 
+// FloatPrecision, if set, returns the number of significant digits the floats
+// are rounded to, or 0 to keep their shortest representation.
+var FloatPrecision func() int
+
 type Encoder struct{}
 
 // AppendKey appends a new key to the output JSON.
@@ -1,6 +1,9 @@
 package json
 
-import "unicode/utf8"
+import (
+	"encoding/base64"
+	"unicode/utf8"
+)
 
 // AppendBytes is a mirror of appendString with []byte arg
 func (Encoder) AppendBytes(dst, s []byte) []byte {
@@ -28,6 +31,22 @@ func (Encoder) AppendHex(dst, s []byte) []byte {
 	return append(dst, '"')
 }
 
+// AppendBase64 encodes the input bytes with enc and appends the encoded
+// string to the input byte slice.
+func (Encoder) AppendBase64(dst, s []byte, enc *base64.Encoding) []byte {
+	dst = append(dst, '"')
+	n := len(dst)
+	l := enc.EncodedLen(len(s))
+	if cap(dst)-n < l {
+		grown := make([]byte, n, n+l+1)
+		copy(grown, dst)
+		dst = grown
+	}
+	dst = dst[:n+l]
+	enc.Encode(dst[n:], s)
+	return append(dst, '"')
+}
+
 // appendBytesComplex is a mirror of the appendStringComplex
 // with []byte arg
 func appendBytesComplex(dst, s []byte, i int) []byte {
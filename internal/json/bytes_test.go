@@ -1,6 +1,7 @@
 package json
 
 import (
+	"encoding/base64"
 	"testing"
 	"unicode"
 )
@@ -25,6 +26,25 @@ func TestAppendHex(t *testing.T) {
 	}
 }
 
+func TestAppendBase64(t *testing.T) {
+	tests := []struct {
+		in   string
+		enc  *base64.Encoding
+		want string
+	}{
+		{"", base64.StdEncoding, `""`},
+		{"\xfb\xff", base64.StdEncoding, `"+/8="`},
+		{"\xfb\xff", base64.URLEncoding, `"-_8="`},
+		{"\xfb\xff", base64.RawURLEncoding, `"-_8"`},
+		{"hello world", base64.RawStdEncoding, `"aGVsbG8gd29ybGQ"`},
+	}
+	for _, tt := range tests {
+		if got := string(enc.AppendBase64([]byte("x"), []byte(tt.in), tt.enc)); got != "x"+tt.want {
+			t.Errorf("AppendBase64(%q) = %s, want %s", tt.in, got, "x"+tt.want)
+		}
+	}
+}
+
 func TestStringBytes(t *testing.T) {
 	t.Parallel()
 	// Test that encodeState.stringBytes and encodeState.string use the same encoding.
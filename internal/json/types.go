@@ -311,9 +311,24 @@ func appendFloat(dst []byte, val float64, bitSize int) []byte {
 	case math.IsInf(val, -1):
 		return append(dst, `"-Inf"`...)
 	}
+	if FloatPrecision != nil {
+		if prec := FloatPrecision(); prec > 0 {
+			val = roundFloat(val, prec, bitSize)
+		}
+	}
 	return strconv.AppendFloat(dst, val, 'f', -1, bitSize)
 }
 
+// roundFloat returns val rounded to prec significant digits.
+func roundFloat(val float64, prec, bitSize int) float64 {
+	var buf [32]byte
+	b := strconv.AppendFloat(buf[:0], val, 'g', prec, bitSize)
+	if r, err := strconv.ParseFloat(string(b), bitSize); err == nil {
+		return r
+	}
+	return val
+}
+
 // AppendFloat32 converts the input float32 to a string and
 // appends the encoded string to the input byte slice.
 func (Encoder) AppendFloat32(dst []byte, val float32) []byte {
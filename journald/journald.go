@@ -1,4 +1,4 @@
-//go:build !windows
+//go:build !windows && !js
 
 // Package journald provides a io.Writer to send the logs
 // to journalD component of systemd.
@@ -0,0 +1,25 @@
+//go:build js
+
+package journald
+
+import (
+	"errors"
+	"io"
+)
+
+// errNotSupported is returned by the writer of NewJournalDWriter in js, where
+// there is no journalD to send the logs to.
+var errNotSupported = errors.New("journald: not supported in js")
+
+// NewJournalDWriter returns a zerolog log destination failing to write as
+// journalD is not available in js.
+func NewJournalDWriter() io.Writer {
+	return journalWriter{}
+}
+
+type journalWriter struct {
+}
+
+func (w journalWriter) Write(p []byte) (n int, err error) {
+	return 0, errNotSupported
+}
@@ -101,9 +101,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	stdlog "log"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Level defines log levels.
@@ -153,12 +157,151 @@ func (l *Level) String() string {
 	case NoLevel:
 		return ""
 	}
+	if name, ok := registeredLevelName(*l); ok {
+		return name
+	}
 	return strconv.Itoa(int(*l))
 }
 
+var (
+	levelsMu         sync.RWMutex
+	registeredLevels = map[Level]string{}
+)
+
+// RegisterLevel registers a custom level with the given value and name, so
+// it can be marshaled by Level.String, parsed by ParseLevel and logged with
+// Logger.WithLevel. The level is ordered by its value: a value greater than
+// PanicLevel makes a level only filtered by disabled loggers, like an audit
+// level. Use RegisterLevelAbove to order a level between two others.
+//
+// RegisterLevel returns an error if value is used by a built-in level or if
+// name is empty or already used by another level.
+// It is not safe to call concurrently with logging using registered levels.
+func RegisterLevel(value int8, name string) (Level, error) {
+	l := Level(value)
+	if l >= TraceLevel && l <= Disabled {
+		return l, fmt.Errorf("level %d is reserved", value)
+	}
+	if name == "" {
+		return l, errors.New("level name cannot be empty")
+	}
+	if lvl, err := ParseLevel(name); err == nil && lvl != l {
+		return l, fmt.Errorf("level name %q already used by level %d", name, lvl)
+	}
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+	registeredLevels[l] = name
+	return l, nil
+}
+
+// RegisterLevelAbove registers a custom level like RegisterLevel, but orders
+// it right above base, below the level that came next, instead of by its
+// value. For instance, a notice level registered above InfoLevel is filtered
+// by loggers at WarnLevel, and a logger at the notice level filters InfoLevel
+// events.
+// It is not safe to call concurrently with logging.
+func RegisterLevelAbove(value int8, name string, base Level) (Level, error) {
+	l, err := RegisterLevel(value, name)
+	if err != nil {
+		return l, err
+	}
+	levelsMu.RLock()
+	positions := make([]float64, 0, len(registeredLevels)+int(Disabled-TraceLevel)+1)
+	for lvl := TraceLevel; lvl <= Disabled; lvl++ {
+		positions = append(positions, float64(lvl))
+	}
+	for lvl := range registeredLevels {
+		if lvl != l {
+			positions = append(positions, levelOrder(lvl))
+		}
+	}
+	levelsMu.RUnlock()
+	lo := levelOrder(base)
+	hi := lo + 1
+	for _, p := range positions {
+		if p > lo && p < hi {
+			hi = p
+		}
+	}
+
+	orders, _ := levelOrders.Load().(map[Level]float64)
+	next := make(map[Level]float64, len(orders)+1)
+	for lvl, o := range orders {
+		next[lvl] = o
+	}
+	next[l] = (lo + hi) / 2
+	levelOrders.Store(next)
+	return l, nil
+}
+
+// levelOrders holds the positions of the levels registered with
+// RegisterLevelAbove. It is replaced as a whole so it can be read without
+// locking.
+var levelOrders atomic.Value // map[Level]float64
+
+// levelOrder returns the position of l in the level ordering.
+func levelOrder(l Level) float64 {
+	if orders, _ := levelOrders.Load().(map[Level]float64); orders != nil {
+		if o, ok := orders[l]; ok {
+			return o
+		}
+	}
+	return float64(l)
+}
+
+// levelLess returns true if a is ordered below b.
+func levelLess(a, b Level) bool {
+	if levelOrders.Load() == nil {
+		return a < b
+	}
+	return levelOrder(a) < levelOrder(b)
+}
+
+// registeredLevelName returns the name l was registered with.
+func registeredLevelName(l Level) (string, bool) {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	name, ok := registeredLevels[l]
+	return name, ok
+}
+
+// registeredLevelByName returns the level registered with name, compared
+// case-insensitively.
+func registeredLevelByName(name string) (Level, bool) {
+	levelsMu.RLock()
+	defer levelsMu.RUnlock()
+	for l, n := range registeredLevels {
+		if strings.EqualFold(n, name) {
+			return l, true
+		}
+	}
+	return NoLevel, false
+}
+
+// LevelAliases maps alternative level names, matched case-insensitively by
+// ParseLevel, to their Level. Keys must be lower case.
+var LevelAliases = map[string]Level{
+	"trc":      TraceLevel,
+	"dbg":      DebugLevel,
+	"inf":      InfoLevel,
+	"wrn":      WarnLevel,
+	"warning":  WarnLevel,
+	"err":      ErrorLevel,
+	"ftl":      FatalLevel,
+	"crit":     FatalLevel,
+	"critical": FatalLevel,
+	"pnc":      PanicLevel,
+}
+
 // ParseLevel converts a level string into a zerolog Level value.
+// Surrounding whitespace is ignored, and aliases from LevelAliases as well as
+// levels registered with RegisterLevel and numeric values are accepted.
 // returns an error if the input string does not match known values.
 func ParseLevel(levelStr string) (Level, error) {
+	levelStr = strings.TrimSpace(levelStr)
+	if l, ok := LevelAliases[strings.ToLower(levelStr)]; ok {
+		return l, nil
+	}
 	switch {
 	case strings.EqualFold(levelStr, LevelFieldMarshalFunc(TraceLevel)):
 		return TraceLevel, nil
@@ -179,6 +322,9 @@ func ParseLevel(levelStr string) (Level, error) {
 	case strings.EqualFold(levelStr, LevelFieldMarshalFunc(NoLevel)):
 		return NoLevel, nil
 	}
+	if l, ok := registeredLevelByName(levelStr); ok {
+		return l, nil
+	}
 	i, err := strconv.Atoi(levelStr)
 	if err != nil {
 		return NoLevel, fmt.Errorf("unknown Level String: '%s', defaulting to NoLevel", levelStr)
@@ -200,8 +346,77 @@ func (l *Level) UnmarshalText(text []byte) error {
 }
 
 // MarshalText implements encoding.TextMarshaler to allow for easy writing into toml/yaml/json formats
-func (l *Level) MarshalText() ([]byte, error) {
-	return []byte(LevelFieldMarshalFunc(*l)), nil
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(LevelFieldMarshalFunc(l)), nil
+}
+
+// LevelVar is a Level variable, allowing the level of the loggers bound to it
+// with Logger.LevelVar to be changed at runtime. It is safe for concurrent
+// use.
+type LevelVar struct {
+	l int32
+}
+
+// NewLevelVar creates a LevelVar set to l.
+func NewLevelVar(l Level) *LevelVar {
+	lv := &LevelVar{}
+	lv.Set(l)
+	return lv
+}
+
+// Level returns the current level of lv.
+func (lv *LevelVar) Level() Level {
+	return Level(atomic.LoadInt32(&lv.l))
+}
+
+// Set sets the level of lv to l.
+func (lv *LevelVar) Set(l Level) {
+	atomic.StoreInt32(&lv.l, int32(l))
+}
+
+// AtomicLevel is an alias of LevelVar, for the code setting the level of
+// long-lived loggers at runtime, like a /debug/loglevel HTTP handler:
+//
+//	level := zerolog.NewAtomicLevel(zerolog.InfoLevel)
+//	log := zerolog.New(os.Stderr).WithAtomicLevel(level)
+//	...
+//	level.SetLevel(zerolog.DebugLevel)
+type AtomicLevel = LevelVar
+
+// NewAtomicLevel creates an AtomicLevel set to l.
+func NewAtomicLevel(l Level) *AtomicLevel {
+	return NewLevelVar(l)
+}
+
+// GetLevel returns the current level of lv, like Level.
+func (lv *LevelVar) GetLevel() Level {
+	return lv.Level()
+}
+
+// SetLevel sets the level of lv to l, like Set.
+func (lv *LevelVar) SetLevel(l Level) {
+	lv.Set(l)
+}
+
+func (lv *LevelVar) String() string {
+	l := lv.Level()
+	return fmt.Sprintf("LevelVar(%s)", l.String())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (lv *LevelVar) MarshalText() ([]byte, error) {
+	return lv.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the same
+// values as ParseLevel.
+func (lv *LevelVar) UnmarshalText(text []byte) error {
+	l, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	lv.Set(l)
+	return nil
 }
 
 // A Logger represents an active logging object that generates lines
@@ -210,14 +425,93 @@ func (l *Level) MarshalText() ([]byte, error) {
 // serialization to the Writer. If your Writer is not thread safe,
 // you may consider a sync wrapper.
 type Logger struct {
-	w       LevelWriter
-	level   Level
-	sampler Sampler
-	context []byte
-	hooks   []Hook
-	stack   bool
+	// droppedAfterClose and closed are accessed atomically. They come first
+	// to keep droppedAfterClose 64-bit aligned on 32-bit platforms.
+	droppedAfterClose uint64
+	closed            uint32
+
+	w        LevelWriter
+	level    Level
+	levelVar *LevelVar
+	sampler  Sampler
+	context  []byte
+	hooks    []Hook
+	stack    bool
+
+	// printLevel overrides the PrintLevel global when set.
+	printLevel *Level
+
+	// writeLevel is the level of the events written with Write, NoLevel
+	// when unset.
+	writeLevel *Level
+
+	// timestampFunc and timeFieldFormat override the TimestampFunc and
+	// TimeFieldFormat globals when set.
+	timestampFunc   func() time.Time
+	timeFieldFormat *string
+
+	// levelFieldName, levelFieldMarshalFunc and errorStackFieldName
+	// override the LevelFieldName, LevelFieldMarshalFunc and
+	// ErrorStackFieldName globals when set.
+	levelFieldName        *string
+	levelFieldMarshalFunc func(l Level) string
+	errorStackFieldName   *string
+
+	afterClose AfterClosePolicy
+
+	// pprofLabels are the keys of the pprof labels added to the events, see
+	// WithPprofLabels.
+	pprofLabels []string
+
+	// principal is the field name of the principal added to the events,
+	// see RequirePrincipal.
+	principal string
 }
 
+// AfterClosePolicy defines what happens to the events logged with a Logger
+// after its Close method has been called.
+type AfterClosePolicy uint8
+
+const (
+	// AfterCloseDrop drops the events, counting them in
+	// Logger.DroppedAfterClose.
+	AfterCloseDrop AfterClosePolicy = iota
+	// AfterCloseRedirectToStderr writes the events to os.Stderr instead of
+	// the closed writer.
+	AfterCloseRedirectToStderr
+	// AfterClosePanic panics, which is mostly useful in tests to find the
+	// code logging too late.
+	AfterClosePanic
+)
+
+// InvalidLevelPolicy defines how WithLevel handles the invalid levels: the
+// values above Disabled which are not registered with RegisterLevel.
+type InvalidLevelPolicy uint8
+
+const (
+	// InvalidLevelAsNoLevel logs the events without level, with the
+	// InvalidLevelFieldName field set to the value of the level.
+	InvalidLevelAsNoLevel InvalidLevelPolicy = iota
+	// InvalidLevelDrop drops the events.
+	InvalidLevelDrop
+)
+
+// PrincipalMissingPolicy defines how the events of a Logger requiring a
+// principal, see Logger.RequirePrincipal, are handled when their context
+// has none.
+type PrincipalMissingPolicy uint8
+
+const (
+	// PrincipalMissingFlag logs the events with the
+	// PrincipalMissingFieldName field set to true.
+	PrincipalMissingFlag PrincipalMissingPolicy = iota
+	// PrincipalMissingDrop drops the events.
+	PrincipalMissingDrop
+)
+
+// afterCloseStderr is the writer used by AfterCloseRedirectToStderr.
+var afterCloseStderr io.Writer = os.Stderr
+
 // New creates a root logger with given output writer. If the output writer implements
 // the LevelWriter interface, the WriteLevel method will be called instead of the Write
 // one.
@@ -245,8 +539,19 @@ func Nop() *Logger {
 func (l *Logger) Output(w io.Writer) *Logger {
 	l2 := New(w)
 	l2.level = l.level
+	l2.levelVar = l.levelVar
 	l2.sampler = l.sampler
 	l2.stack = l.stack
+	l2.printLevel = l.printLevel
+	l2.writeLevel = l.writeLevel
+	l2.timestampFunc = l.timestampFunc
+	l2.timeFieldFormat = l.timeFieldFormat
+	l2.levelFieldName = l.levelFieldName
+	l2.levelFieldMarshalFunc = l.levelFieldMarshalFunc
+	l2.errorStackFieldName = l.errorStackFieldName
+	l2.afterClose = l.afterClose
+	l2.pprofLabels = l.pprofLabels
+	l2.principal = l.principal
 	if len(l.hooks) > 0 {
 		l2.hooks = append(l2.hooks, l.hooks...)
 	}
@@ -257,6 +562,42 @@ func (l *Logger) Output(w io.Writer) *Logger {
 	return l2
 }
 
+// AfterClose sets the policy applied to the events logged with l after it has
+// been closed. The default policy is AfterCloseDrop.
+func (l *Logger) AfterClose(policy AfterClosePolicy) *Logger {
+	l.afterClose = policy
+	return l
+}
+
+// Close marks l as closed and closes its writer if it implements io.Closer,
+// unless it is os.Stdout or os.Stderr. The writers combined with
+// MultiLevelWriter or SyncWriter are closed as well. Events logged with l
+// afterward are handled according to its AfterClose policy. Calling Close more than once
+// is a no-op.
+func (l *Logger) Close() error {
+	if !atomic.CompareAndSwapUint32(&l.closed, 0, 1) {
+		return nil
+	}
+	return closeOutput(l.w)
+}
+
+// Sync calls the Sync method of the writer of l if it has one, like
+// os.File or BufferedLevelWriter, to flush the events written so far,
+// unless it is os.Stdout or os.Stderr. The writers combined with
+// MultiLevelWriter or SyncWriter are synced as well.
+func (l *Logger) Sync() error {
+	if l.w == nil {
+		return nil
+	}
+	return syncOutput(l.w)
+}
+
+// DroppedAfterClose returns the number of events dropped because they were
+// logged with l after it was closed.
+func (l *Logger) DroppedAfterClose() uint64 {
+	return atomic.LoadUint64(&l.droppedAfterClose)
+}
+
 // With creates a child logger with the field added to its context.
 func (l *Logger) With() Context {
 	context := l.context
@@ -291,14 +632,105 @@ func (l *Logger) UpdateContext(update func(c Context) Context) {
 // Level creates a child logger with the minimum accepted level set to level.
 func (l *Logger) Level(lvl Level) *Logger {
 	l.level = lvl
+	l.levelVar = nil
 	return l
 }
 
+// LevelVar binds the minimum accepted level of l to lv, so that changing lv
+// affects all the loggers bound to it. It replaces any level set with Level.
+func (l *Logger) LevelVar(lv *LevelVar) *Logger {
+	l.levelVar = lv
+	return l
+}
+
+// WithAtomicLevel returns a copy of l whose minimum accepted level is read
+// from al on each event, like with LevelVar. l is not modified.
+func (l *Logger) WithAtomicLevel(al *AtomicLevel) *Logger {
+	l2 := l.Output(l.w)
+	l2.levelVar = al
+	return l2
+}
+
 // GetLevel returns the current Level of l.
 func (l *Logger) GetLevel() Level {
+	if l.levelVar != nil {
+		return l.levelVar.Level()
+	}
 	return l.level
 }
 
+// WithPrintLevel returns a logger whose Print, Printf and Println methods
+// log at lvl instead of zerolog.PrintLevel.
+func (l *Logger) WithPrintLevel(lvl Level) *Logger {
+	l.printLevel = &lvl
+	return l
+}
+
+// WithWriteLevel returns a logger whose Write method, used by StdLog, logs at
+// lvl instead of without level.
+func (l *Logger) WithWriteLevel(lvl Level) *Logger {
+	l.writeLevel = &lvl
+	return l
+}
+
+// WithClock sets the clock used for the timestamp of l's events instead of
+// zerolog.TimestampFunc. Passing nil reverts to zerolog.TimestampFunc. It
+// lets tests pin the time of their own loggers without racing on the global.
+// Context.TimestampFunc sets the same clock.
+func (l *Logger) WithClock(clock func() time.Time) *Logger {
+	l.timestampFunc = clock
+	return l
+}
+
+// WithPprofLabels adds to the events of l the pprof labels with the given
+// keys found in their context, set with Event.Ctx. It lets the labels set
+// with pprof.Do for profiling, like a tenant or an endpoint, be logged as
+// well. Calling it without keys disables it.
+//
+// The labels are read with the pure Go runtime/pprof API, on any platform,
+// and only for the enabled events. As this API gives no access to the
+// labels of the current goroutine, the labeled context passed to the
+// function of pprof.Do must be set on the events:
+//
+//	pprof.Do(ctx, pprof.Labels("tenant", tenant), func(ctx context.Context) {
+//	    log.Info().Ctx(ctx).Msg("request")
+//	})
+func (l *Logger) WithPprofLabels(keys ...string) *Logger {
+	if len(keys) == 0 {
+		l.pprofLabels = nil
+	} else {
+		l.pprofLabels = append([]string(nil), keys...)
+	}
+	return l
+}
+
+// RequirePrincipal adds to the events of l, for audit logs, the principal
+// found in their context, set with Event.Ctx, by PrincipalExtractor, as the
+// fieldName field. The events of PrincipalMissingLevel or above whose
+// context has no principal are handled according to
+// PrincipalMissingHandling, so that the gaps are visible. The events without
+// context are left as is. Calling it with an empty fieldName disables it.
+//
+//	zerolog.PrincipalExtractor = func(ctx context.Context) (string, bool) {
+//	    user, ok := ctx.Value(userKey{}).(string)
+//	    return user, ok
+//	}
+//	audit := zerolog.New(w).RequirePrincipal("principal")
+//	audit.Info().Ctx(r.Context()).Msg("record deleted")
+func (l *Logger) RequirePrincipal(fieldName string) *Logger {
+	l.principal = fieldName
+	return l
+}
+
+// GetPrintLevel returns the level used by the Print, Printf and Println
+// methods of l.
+func (l *Logger) GetPrintLevel() Level {
+	if l.printLevel != nil {
+		return *l.printLevel
+	}
+	return PrintLevel
+}
+
 // Sample returns a logger with the s sampler.
 func (l *Logger) Sample(s Sampler) *Logger {
 	l.sampler = s
@@ -311,6 +743,21 @@ func (l *Logger) Hook(h Hook) *Logger {
 	return l
 }
 
+// Hooks returns a copy of the hooks of l.
+func (l *Logger) Hooks() []Hook {
+	if len(l.hooks) == 0 {
+		return nil
+	}
+	return append([]Hook(nil), l.hooks...)
+}
+
+// WithoutHooks returns a copy of l without its hooks. l is not modified.
+func (l *Logger) WithoutHooks() *Logger {
+	l2 := l.Output(l.w)
+	l2.hooks = nil
+	return l2
+}
+
 // Trace starts a new message with trace level.
 //
 // You must call Msg on the returned event in order to send the event.
@@ -358,12 +805,84 @@ func (l *Logger) Err(err error) *Event {
 	return l.Info()
 }
 
-// Fatal starts a new message with fatal level. The os.Exit(1) function
-// is called by the Msg method, which terminates the program immediately.
+// LogError sends an error level event with err as a field and msg as the
+// message, and returns err so that an error can be logged and propagated
+// at once:
+//
+//	if err != nil {
+//	    return log.LogError(err, "cannot open config")
+//	}
+//
+// Nothing is logged if err is nil.
+func (l *Logger) LogError(err error, msg string) error {
+	if err != nil {
+		l.Error().Err(err).CallerSkipFrame(1).Msg(msg)
+	}
+	return err
+}
+
+// Fatal starts a new message with fatal level. The ExitFunc function,
+// os.Exit by default, is called with 1 by the Msg method, which terminates
+// the program immediately.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l *Logger) Fatal() *Event {
-	return l.newEvent(FatalLevel, func(msg string) { os.Exit(1) })
+	return l.newEvent(FatalLevel, func(msg string) { ExitFunc(1) })
+}
+
+// FatalTimeout is like Fatal, but the Msg method flushes the writer of l,
+// see FlushOnPanic, before calling ExitFunc, waiting at most d for the event
+// to be written and flushed, or DefaultFatalTimeout if d is 0 or less. If
+// the writer is stuck, the event is written to os.Stderr instead, so that
+// it is neither lost nor delaying the exit endlessly.
+//
+// You must call Msg on the returned event in order to send the event.
+func (l *Logger) FatalTimeout(d time.Duration) *Event {
+	e := l.newEvent(FatalLevel, func(msg string) { ExitFunc(1) })
+	if e != nil {
+		if d <= 0 {
+			d = DefaultFatalTimeout
+		}
+		e.w = fatalTimeoutWriter{w: e.w, timeout: d}
+	}
+	return e
+}
+
+// fatalStderr is the writer of the events of Logger.FatalTimeout whose
+// writer is stuck.
+var fatalStderr io.Writer = os.Stderr
+
+// fatalTimeoutWriter writes the events to w and flushes it in a goroutine,
+// and writes them to fatalStderr if this takes longer than timeout.
+type fatalTimeoutWriter struct {
+	w       LevelWriter
+	timeout time.Duration
+}
+
+func (w fatalTimeoutWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+func (w fatalTimeoutWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	// p is reused once the write returns, while the goroutine may still be
+	// writing it.
+	line := append([]byte(nil), p...)
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.w.WriteLevel(l, line)
+		if ferr := flushWriter(w.w); err == nil {
+			err = ferr
+		}
+		done <- err
+	}()
+	t := time.NewTimer(w.timeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		return len(p), err
+	case <-t.C:
+		return fatalStderr.Write(line)
+	}
 }
 
 // Panic starts a new message with panic level. The panic() function
@@ -376,7 +895,11 @@ func (l *Logger) Panic() *Event {
 
 // WithLevel starts a new message with level. Unlike Fatal and Panic
 // methods, WithLevel does not terminate the program or stop the ordinary
-// flow of a goroutine when used with their respective levels.
+// flow of a goroutine when used with their respective levels. Levels
+// registered with RegisterLevel are logged with their registered name, and
+// the levels below TraceLevel with their value. Disabled returns a nil
+// event, and the other levels above Disabled are invalid: they are handled
+// according to InvalidLevelHandling. See WithLevelStrict to reject them.
 //
 // You must call Msg on the returned event in order to send the event.
 func (l *Logger) WithLevel(level Level) *Event {
@@ -400,10 +923,35 @@ func (l *Logger) WithLevel(level Level) *Event {
 	case Disabled:
 		return nil
 	default:
+		if !validLevel(level) {
+			if InvalidLevelHandling == InvalidLevelDrop {
+				return nil
+			}
+			return l.Log().Int(InvalidLevelFieldName, int(level))
+		}
 		return l.newEvent(level, nil)
 	}
 }
 
+// WithLevelStrict starts a new message with level like WithLevel, but
+// returns an error instead of handling the invalid levels according to
+// InvalidLevelHandling.
+func (l *Logger) WithLevelStrict(level Level) (*Event, error) {
+	if !validLevel(level) {
+		return nil, fmt.Errorf("invalid level %d", level)
+	}
+	return l.WithLevel(level), nil
+}
+
+// validLevel returns false if level is above Disabled and not registered.
+func validLevel(level Level) bool {
+	if level <= Disabled {
+		return true
+	}
+	_, ok := registeredLevelName(level)
+	return ok
+}
+
 // Log starts a new message with no level. Setting GlobalLevel to Disabled
 // will still disable events produced by this method.
 //
@@ -412,34 +960,98 @@ func (l *Logger) Log() *Event {
 	return l.newEvent(NoLevel, nil)
 }
 
-// Print sends a log event using debug level and no extra field.
+// Print sends a log event using the print level (debug by default, see
+// PrintLevel and WithPrintLevel) and no extra field.
 // Arguments are handled in the manner of fmt.Print.
 func (l *Logger) Print(v ...interface{}) {
-	if e := l.Debug(); e.Enabled() {
+	if e := l.WithLevel(l.GetPrintLevel()); e.Enabled() {
 		e.CallerSkipFrame(1).Msg(fmt.Sprint(v...))
 	}
 }
 
-// Printf sends a log event using debug level and no extra field.
+// Printf sends a log event using the print level (debug by default, see
+// PrintLevel and WithPrintLevel) and no extra field.
 // Arguments are handled in the manner of fmt.Printf.
 func (l *Logger) Printf(format string, v ...interface{}) {
-	if e := l.Debug(); e.Enabled() {
+	if e := l.WithLevel(l.GetPrintLevel()); e.Enabled() {
 		e.CallerSkipFrame(1).Msg(fmt.Sprintf(format, v...))
 	}
 }
 
+// Println sends a log event using the print level (debug by default, see
+// PrintLevel and WithPrintLevel) and no extra field.
+// Arguments are handled in the manner of fmt.Println, without the trailing
+// newline.
+func (l *Logger) Println(v ...interface{}) {
+	if e := l.WithLevel(l.GetPrintLevel()); e.Enabled() {
+		msg := fmt.Sprintln(v...)
+		e.CallerSkipFrame(1).Msg(msg[:len(msg)-1])
+	}
+}
+
+// Debugf sends a debug level event with no extra field. Arguments are
+// handled in the manner of fmt.Printf, only if the level is enabled.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.logf(DebugLevel, format, v)
+}
+
+// Infof sends an info level event with no extra field. Arguments are
+// handled in the manner of fmt.Printf, only if the level is enabled.
+func (l *Logger) Infof(format string, v ...interface{}) {
+	l.logf(InfoLevel, format, v)
+}
+
+// Warnf sends a warn level event with no extra field. Arguments are handled
+// in the manner of fmt.Printf, only if the level is enabled.
+func (l *Logger) Warnf(format string, v ...interface{}) {
+	l.logf(WarnLevel, format, v)
+}
+
+// Errorf sends an error level event with no extra field. Arguments are
+// handled in the manner of fmt.Printf, only if the level is enabled.
+func (l *Logger) Errorf(format string, v ...interface{}) {
+	l.logf(ErrorLevel, format, v)
+}
+
+// logf sends an event of level with the message formatted from format and
+// v, for the methods like Infof.
+func (l *Logger) logf(level Level, format string, v []interface{}) {
+	if e := l.newEvent(level, nil); e.Enabled() {
+		e.CallerSkipFrame(2).Msg(fmt.Sprintf(format, v...))
+	}
+}
+
 // Write implements the io.Writer interface. This is useful to set as a writer
-// for the standard library log.
+// for the standard library log. Each write is logged as the message of an
+// event, without level or at the level set with WithWriteLevel, and without
+// its trailing newline.
 func (l *Logger) Write(p []byte) (n int, err error) {
 	n = len(p)
 	if n > 0 && p[n-1] == '\n' {
-		// Trim CR added by stdlog.
+		// Trim LF added by stdlog.
 		p = p[0 : n-1]
 	}
-	l.Log().CallerSkipFrame(1).Msg(string(p))
+	level := NoLevel
+	if l.writeLevel != nil {
+		level = *l.writeLevel
+	}
+	if e := l.WithLevel(level); e.Enabled() {
+		e.CallerSkipFrame(1).Msg(string(p))
+	}
 	return
 }
 
+// StdLog returns a standard library logger writing each of its lines to a
+// copy of l as the message of an event at level:
+//
+//	srv := &http.Server{ErrorLog: log.StdLog(zerolog.ErrorLevel)}
+//
+// The returned logger has no prefix and no flags, the time and the caller
+// being added by l if configured.
+func (l *Logger) StdLog(level Level) *stdlog.Logger {
+	return stdlog.New(l.Output(l.w).WithWriteLevel(level), "", 0)
+}
+
 func (l *Logger) newEvent(level Level, done func(string)) *Event {
 	enabled := l.should(level)
 	if !enabled {
@@ -448,13 +1060,67 @@ func (l *Logger) newEvent(level Level, done func(string)) *Event {
 		}
 		return nil
 	}
-	e := newEvent(l.w, level)
+	w := l.writer()
+	if w == nil {
+		if l.w == nil && verifying() {
+			verifyLogger(l)
+		}
+		if done != nil {
+			done("")
+		}
+		return nil
+	}
+	e := l.initEvent(w, level)
 	e.done = done
+	return e
+}
+
+// writer returns the writer of the events of l, applying its AfterClose
+// policy once closed. It returns nil if the event must be dropped.
+func (l *Logger) writer() LevelWriter {
+	if atomic.LoadUint32(&l.closed) == 0 {
+		return l.w
+	}
+	switch l.afterClose {
+	case AfterCloseRedirectToStderr:
+		return levelWriterAdapter{afterCloseStderr}
+	case AfterClosePanic:
+		panic("zerolog: event logged after Logger.Close")
+	default:
+		atomic.AddUint64(&l.droppedAfterClose, 1)
+		return nil
+	}
+}
+
+// initEvent creates an event written to w, with the hooks, the level field
+// and the context of l.
+func (l *Logger) initEvent(w LevelWriter, level Level) *Event {
+	e := newEvent(w, level)
 	e.ch = l.hooks
-	if level != NoLevel && LevelFieldName != "" {
-		e.Str(LevelFieldName, LevelFieldMarshalFunc(level))
+	e.redactKeys, e.redactAudit = redactKeys(l.hooks)
+	e.timestampFunc = l.timestampFunc
+	e.timeFormat = l.timeFormat()
+	e.pprofLabels = l.pprofLabels
+	e.principal = l.principal
+	if l.errorStackFieldName != nil {
+		e.stackKey = *l.errorStackFieldName
+	}
+	if level != NoLevel {
+		name, marshal := LevelFieldName, LevelFieldMarshalFunc
+		if l.levelFieldName != nil {
+			name = *l.levelFieldName
+		}
+		if l.levelFieldMarshalFunc != nil {
+			marshal = l.levelFieldMarshalFunc
+		}
+		if name != "" {
+			e.Str(name, marshal(level))
+		}
 	}
 	if l.context != nil && len(l.context) > 1 {
+		if e.trace != nil {
+			e.traceSpan("Context", "")
+		}
 		e.buf = enc.AppendObjectData(e.buf, l.context)
 	}
 	if l.stack {
@@ -463,9 +1129,22 @@ func (l *Logger) newEvent(level Level, done func(string)) *Event {
 	return e
 }
 
+// timeFormat returns the time field format of l, falling back to the
+// TimeFieldFormat global.
+func (l *Logger) timeFormat() string {
+	if l.timeFieldFormat != nil {
+		return *l.timeFieldFormat
+	}
+	return TimeFieldFormat
+}
+
 // should returns true if the log event should be logged.
 func (l *Logger) should(lvl Level) bool {
-	if lvl < l.level || lvl < GlobalLevel() {
+	min, gmin := l.GetLevel(), GlobalLevel()
+	if min == Disabled || gmin == Disabled {
+		return false
+	}
+	if levelLess(lvl, min) || levelLess(lvl, gmin) {
 		return false
 	}
 	if l.sampler != nil && !samplingDisabled() {
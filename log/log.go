@@ -30,35 +30,47 @@ func defaultErrWrite(err error, msg string) {
 // Logger is the global logger.
 var Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
 
+// shared holds Logger for the functions of the package, whose Level, Sample,
+// Hook and With create child loggers instead of modifying it in place.
+var shared = zerolog.NewSharedLogger(Logger)
+
+// logger returns shared, holding Logger even if it was replaced.
+func logger() *zerolog.SharedLogger {
+	if l := Logger; shared.Load() != l {
+		shared.Store(l)
+	}
+	return shared
+}
+
 // Output duplicates the global logger and sets w as its output.
 //
 //goland:noinspection GoUnusedExportedFunction
 func Output(w io.Writer) *zerolog.Logger {
-	return Logger.Output(w)
+	return logger().Output(w)
 }
 
 // With creates a child logger with the field added to its context.
 func With() zerolog.Context {
-	return Logger.With()
+	return logger().With()
 }
 
 // Level creates a child logger with the minimum accepted level set to level.
 //
 //goland:noinspection GoUnusedExportedFunction
 func Level(level zerolog.Level) *zerolog.Logger {
-	return Logger.Level(level)
+	return logger().Level(level)
 }
 
-// Sample returns a logger with the s sampler.
+// Sample creates a child logger with the s sampler.
 //
 //goland:noinspection GoUnusedExportedFunction
 func Sample(s zerolog.Sampler) *zerolog.Logger {
-	return Logger.Sample(s)
+	return logger().Sample(s)
 }
 
-// Hook returns a logger with the h Hook.
+// Hook creates a child logger with the h Hook.
 func Hook(h zerolog.Hook) *zerolog.Logger {
-	return Logger.Hook(h)
+	return logger().Hook(h)
 }
 
 // Err starts a new message with error level with err as a field if not nil or
@@ -66,42 +78,42 @@ func Hook(h zerolog.Hook) *zerolog.Logger {
 //
 // You must call Msg on the returned event in order to send the event.
 func Err(err error) *zerolog.Event {
-	return Logger.Err(err)
+	return logger().Err(err)
 }
 
 // Trace starts a new message with trace level.
 //
 // You must call Msg on the returned event in order to send the event.
 func Trace() *zerolog.Event {
-	return Logger.Trace()
+	return logger().Trace()
 }
 
 // Debug starts a new message with debug level.
 //
 // You must call Msg on the returned event in order to send the event.
 func Debug() *zerolog.Event {
-	return Logger.Debug()
+	return logger().Debug()
 }
 
 // Info starts a new message with info level.
 //
 // You must call Msg on the returned event in order to send the event.
 func Info() *zerolog.Event {
-	return Logger.Info()
+	return logger().Info()
 }
 
 // Warn starts a new message with warn level.
 //
 // You must call Msg on the returned event in order to send the event.
 func Warn() *zerolog.Event {
-	return Logger.Warn()
+	return logger().Warn()
 }
 
 // Error starts a new message with error level.
 //
 // You must call Msg on the returned event in order to send the event.
 func Error() *zerolog.Event {
-	return Logger.Error()
+	return logger().Error()
 }
 
 // Fatal starts a new message with fatal level. The os.Exit(1) function
@@ -109,7 +121,7 @@ func Error() *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Fatal() *zerolog.Event {
-	return Logger.Fatal()
+	return logger().Fatal()
 }
 
 // Panic starts a new message with panic level. The message is also sent
@@ -119,7 +131,7 @@ func Fatal() *zerolog.Event {
 //
 //goland:noinspection GoUnusedExportedFunction
 func Panic() *zerolog.Event {
-	return Logger.Panic()
+	return logger().Panic()
 }
 
 // WithLevel starts a new message with level.
@@ -128,7 +140,7 @@ func Panic() *zerolog.Event {
 //
 //goland:noinspection GoUnusedExportedFunction
 func WithLevel(level zerolog.Level) *zerolog.Event {
-	return Logger.WithLevel(level)
+	return logger().WithLevel(level)
 }
 
 // Log starts a new message with no level. Setting zerolog.GlobalLevel to
@@ -136,19 +148,32 @@ func WithLevel(level zerolog.Level) *zerolog.Event {
 //
 // You must call Msg on the returned event in order to send the event.
 func Log() *zerolog.Event {
-	return Logger.Log()
+	return logger().Log()
 }
 
-// Print sends a log event using debug level and no extra field.
-// Arguments are handled in the manner of fmt.Print.
+// Print sends a log event using the print level (debug by default) and no
+// extra field. Arguments are handled in the manner of fmt.Print.
 func Print(v ...interface{}) {
-	Logger.Debug().CallerSkipFrame(1).Msg(fmt.Sprint(v...))
+	l := logger().Load()
+	l.WithLevel(l.GetPrintLevel()).CallerSkipFrame(1).Msg(fmt.Sprint(v...))
 }
 
-// Printf sends a log event using debug level and no extra field.
-// Arguments are handled in the manner of fmt.Printf.
+// Printf sends a log event using the print level (debug by default) and no
+// extra field. Arguments are handled in the manner of fmt.Printf.
 func Printf(format string, v ...interface{}) {
-	Logger.Debug().CallerSkipFrame(1).Msgf(format, v...)
+	l := logger().Load()
+	l.WithLevel(l.GetPrintLevel()).CallerSkipFrame(1).Msgf(format, v...)
+}
+
+// Println sends a log event using the print level (debug by default) and no
+// extra field. Arguments are handled in the manner of fmt.Println, without
+// the trailing newline.
+func Println(v ...interface{}) {
+	l := logger().Load()
+	if e := l.WithLevel(l.GetPrintLevel()); e.Enabled() {
+		msg := fmt.Sprintln(v...)
+		e.CallerSkipFrame(1).Msg(msg[:len(msg)-1])
+	}
 }
 
 // Ctx returns the Logger associated with the ctx. If no logger
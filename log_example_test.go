@@ -7,6 +7,8 @@ import (
 	"fmt"
 	stdlog "log"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"time"
 
@@ -40,6 +42,25 @@ func ExampleLogger_Level() {
 	// Output: {"level":"error","message":"kept message"}
 }
 
+func ExampleLogger_LevelVar() {
+	lv := zerolog.NewLevelVar(zerolog.InfoLevel)
+	log := zerolog.New(os.Stdout).LevelVar(lv)
+
+	// An admin endpoint changing the level of all the loggers bound to lv,
+	// e.g. PUT /loglevel?level=debug
+	admin := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := lv.UnmarshalText([]byte(r.URL.Query().Get("level"))); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+
+	log.Debug().Msg("filtered out message")
+	admin.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/loglevel?level=debug", nil))
+	log.Debug().Msg("kept message")
+
+	// Output: {"level":"debug","message":"kept message"}
+}
+
 func ExampleLogger_Sample() {
 	log := zerolog.New(os.Stdout).Sample(&zerolog.BasicSampler{N: 2})
 
@@ -0,0 +1,51 @@
+//go:build js && wasm && !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"syscall/js"
+	"testing"
+)
+
+// jsWriter writes to a JavaScript callback, like a browser application
+// would.
+type jsWriter struct {
+	fn js.Value
+}
+
+func (w jsWriter) Write(p []byte) (n int, err error) {
+	w.fn.Invoke(string(p))
+	return len(p), nil
+}
+
+func TestJSWriter(t *testing.T) {
+	var lines []string
+	fn := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		lines = append(lines, args[0].String())
+		return nil
+	})
+	defer fn.Release()
+
+	log := New(jsWriter{fn.Value}).With().Str("foo", "bar").Logger()
+	log.Info().Int("n", 1).Msg("hello")
+
+	want := `{"level":"info","foo":"bar","n":1,"message":"hello"}` + "\n"
+	if len(lines) != 1 || lines[0] != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", lines, want)
+	}
+}
+
+func TestJSConsoleWriterNoColor(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := ConsoleWriter{Out: out, PartsExclude: []string{"time"}}
+	if _, err := w.Write([]byte(`{"level":"info","message":"hello"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "INF hello\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+	if !NewConsoleWriter().NoColor {
+		t.Error("NoColor is false in js")
+	}
+}
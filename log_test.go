@@ -2,13 +2,21 @@ package zerolog
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	stdlog "log"
 	"net"
+	"os"
 	"reflect"
 	"runtime"
+	"runtime/pprof"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -349,6 +357,36 @@ func TestFields(t *testing.T) {
 	}
 }
 
+func TestNetworkAddrRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		addr interface{}
+		want string
+	}{
+		{"IPv4", net.IP{1, 2, 3, 4}, `"1.2.3.4"`},
+		{"IPv4-mapped", net.IPv4(1, 2, 3, 4), `"1.2.3.4"`},
+		{"IPv6", net.ParseIP("2001:db8::1"), `"2001:db8::1"`},
+		{"MAC", net.HardwareAddr{0x00, 0x14, 0x22, 0x01, 0x23, 0x45}, `"00:14:22:01:23:45"`},
+		{"EUI-64 MAC", net.HardwareAddr{0x02, 0x00, 0x5e, 0x10, 0x00, 0x00, 0x00, 0x01}, `"02:00:5e:10:00:00:00:01"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			e := New(out).Log()
+			switch addr := tt.addr.(type) {
+			case net.IP:
+				e.IPAddr("addr", addr)
+			case net.HardwareAddr:
+				e.MACAddr("addr", addr)
+			}
+			e.Msg("")
+			if got, want := decodeIfBinaryToString(out.Bytes()), `{"addr":`+tt.want+"}\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
 func TestFieldsArrayEmpty(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out)
@@ -433,6 +471,177 @@ func TestFieldsArrayMultipleElement(t *testing.T) {
 	}
 }
 
+func TestUintptrAndByte(t *testing.T) {
+	out := &bytes.Buffer{}
+	New(out).Log().
+		Uintptr("handle", uintptr(0xdeadbeef)).
+		Byte("byte", 'A').
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"handle":3735928559,"byte":65}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTimesUnix(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	times := []time.Time{
+		time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC),
+		time.Date(2001, 2, 3, 4, 5, 7, 500000000, time.UTC),
+	}
+	log.Log().
+		Times("times", times).
+		TimesUnix("unix", times).
+		TimesUnix("nil", nil).
+		TimesUnix("empty", []time.Time{}).
+		Msg("")
+	want := `{"times":["2001-02-03T04:05:06Z","2001-02-03T04:05:07Z"],"unix":[981173106,981173107],"nil":[],"empty":[]}` + "\n"
+	if out.Bytes()[0] > 0x7f {
+		// The binary format renders its time tags as RFC3339 times.
+		want = `{"times":["2001-02-03T04:05:06Z","2001-02-03T04:05:07.5Z"],"unix":["2001-02-03T04:05:06Z","2001-02-03T04:05:07Z"],"nil":[],"empty":[]}` + "\n"
+	}
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestBase64Bytes(t *testing.T) {
+	val := []byte("\xfb\xff\x00 binary blob")
+	tests := []struct {
+		name string
+		enc  *base64.Encoding
+		dec  *base64.Encoding // decoding of the JSON or of the decoded binary output
+	}{
+		{"nil", nil, base64.StdEncoding},
+		{"std", base64.StdEncoding, base64.StdEncoding},
+		{"raw std", base64.RawStdEncoding, base64.RawStdEncoding},
+		{"url", base64.URLEncoding, base64.URLEncoding},
+		{"raw url", base64.RawURLEncoding, base64.RawURLEncoding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			New(out).Log().Base64Bytes("b", val, tt.enc).Msg("")
+
+			var evt map[string]string
+			if err := json.Unmarshal([]byte(decodeIfBinaryToString(out.Bytes())), &evt); err != nil {
+				t.Fatalf("invalid JSON %q: %v", out.Bytes(), err)
+			}
+			s := evt["b"]
+			if out.Bytes()[0] > 0x7f {
+				// The binary format renders base64url without padding and
+				// base64 with padding.
+				if tt.dec == base64.URLEncoding {
+					tt.dec = base64.RawURLEncoding
+				} else if tt.dec == base64.RawStdEncoding {
+					tt.dec = base64.StdEncoding
+				}
+			}
+			got, err := tt.dec.DecodeString(s)
+			if err != nil || !bytes.Equal(got, val) {
+				t.Errorf("Base64Bytes() = %q, decoded to %q, %v, want %q", s, got, err, val)
+			}
+		})
+	}
+}
+
+func TestUnitFields(t *testing.T) {
+	log := func() string {
+		out := &bytes.Buffer{}
+		New(out).Log().
+			DurMs("latency", 1500*time.Microsecond).
+			DurUs("wait", 2*time.Millisecond).
+			DurS("uptime", 90*time.Second).
+			ByteSizeBytes("size", 2048).
+			ByteSizeMiB("heap", 3<<19).
+			Msg("")
+		return decodeIfBinaryToString(out.Bytes())
+	}
+	if got, want := log(), `{"latency_ms":1.5,"wait_us":2000,"uptime_s":90,"size_bytes":2048,"heap_mib":1.5}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	defer func() { UnitFieldSuffix, UnitFieldSeparator = true, "_" }()
+	UnitFieldSeparator = "."
+	if got, want := log(), `{"latency.ms":1.5,"wait.us":2000,"uptime.s":90,"size.bytes":2048,"heap.mib":1.5}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	UnitFieldSuffix = false
+	if got, want := log(), `{"latency":1.5,"wait":2000,"uptime":90,"size":2048,"heap":1.5}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestFieldsCapped(t *testing.T) {
+	vals := []string{"a", "b", "c"}
+	ints := []int{1, 2, 3}
+	ifaces := []interface{}{"a", 2, true}
+	tests := []struct {
+		max  int
+		want string
+	}{
+		{4, `{"strs":["a","b","c"],"ints":[1,2,3],"ifaces":["a",2,true]}`},
+		{3, `{"strs":["a","b","c"],"ints":[1,2,3],"ifaces":["a",2,true]}`},
+		{2, `{"strs":["a","b","...(+1 more)"],"ints":[1,2,"...(+1 more)"],"ifaces":["a",2,"...(+1 more)"]}`},
+		{0, `{"strs":["...(+3 more)"],"ints":["...(+3 more)"],"ifaces":["...(+3 more)"]}`},
+		{-1, `{"strs":["...(+3 more)"],"ints":["...(+3 more)"],"ifaces":["...(+3 more)"]}`},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.max), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			New(out).Log().
+				StrsN("strs", vals, tt.max).
+				IntsN("ints", ints, tt.max).
+				InterfacesN("ifaces", ifaces, tt.max).
+				Msg("")
+			if got, want := decodeIfBinaryToString(out.Bytes()), tt.want+"\n"; got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSince(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	log := New(out).WithClock(func() time.Time { return now })
+	log.Log().
+		Since("latency", now.Add(-1500*time.Millisecond)).
+		Since("future", now.Add(time.Second)).
+		Msg("")
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"latency":1500,"future":0}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestMsgKey(t *testing.T) {
+	params := map[string]string{"user": `bob "the" <admin>`, "count": "2\n"}
+	t.Run("NoRenderer", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Info().MsgKey("login.failed", params)
+		want := `{"level":"info","message_key":"login.failed","message_params":{"count":"2\n","user":"bob \"the\" <admin>"}}` + "\n"
+		if got := decodeIfBinaryToString(out.Bytes()); got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	t.Run("Renderer", func(t *testing.T) {
+		MessageRenderer = func(key string, params map[string]string) string {
+			return key + ": " + params["user"]
+		}
+		defer func() { MessageRenderer = nil }()
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Info().MsgKey("login.failed", params)
+		log.Info().MsgKey("logout", nil)
+		want := `{"level":"info","message_key":"login.failed","message_params":{"count":"2\n","user":"bob \"the\" <admin>"},"message":"login.failed: bob \"the\" <admin>"}` + "\n" +
+			`{"level":"info","message_key":"logout","message":"logout: "}` + "\n"
+		if got := decodeIfBinaryToString(out.Bytes()); got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+}
+
 func TestFieldsDisabled(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out).Level(InfoLevel)
@@ -476,6 +685,190 @@ func TestMsgf(t *testing.T) {
 	}
 }
 
+func TestPrintLevel(t *testing.T) {
+	t.Run("Default", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out)
+		log.Print("one")
+		log.Printf("%s", "two")
+		log.Println("three", 4)
+		want := `{"level":"debug","message":"one"}` + "\n" +
+			`{"level":"debug","message":"two"}` + "\n" +
+			`{"level":"debug","message":"three 4"}` + "\n"
+		if got := decodeIfBinaryToString(out.Bytes()); got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	t.Run("Global", func(t *testing.T) {
+		defer func() { PrintLevel = DebugLevel }()
+		PrintLevel = InfoLevel
+		out := &bytes.Buffer{}
+		log := New(out).Level(InfoLevel)
+		log.Print("one")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"one"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	t.Run("Logger", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).WithPrintLevel(WarnLevel).Level(InfoLevel)
+		log.Printf("one")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"warn","message":"one"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+	t.Run("Filtered", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		log := New(out).Level(InfoLevel)
+		log.Print("one")
+		log.WithPrintLevel(Disabled).Print("two")
+		if got, want := decodeIfBinaryToString(out.Bytes()), ""; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	})
+}
+
+func TestLoggerWrite(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").Logger()
+	fmt.Fprintln(log, "one")
+	log.WithWriteLevel(WarnLevel).Write([]byte("two"))
+	want := `{"foo":"bar","message":"one"}` + "\n" +
+		`{"level":"warn","foo":"bar","message":"two"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestStdLog(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(InfoLevel)
+	std := log.StdLog(ErrorLevel)
+	std.Printf("failed %d times", 3)
+	log.StdLog(DebugLevel).Print("filtered")
+	stdlog.New(log, "", 0).Println("no level")
+	want := `{"level":"error","message":"failed 3 times"}` + "\n" +
+		`{"message":"no level"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if log.writeLevel != nil {
+		t.Error("StdLog modified the write level of the logger")
+	}
+}
+
+type countingStringer struct {
+	calls int
+}
+
+func (s *countingStringer) String() string {
+	s.calls++
+	return "str"
+}
+
+func TestLevelf(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Debugf("one %s", "debug")
+	log.Infof("two %d", 2)
+	log.Warnf("three %.1f", 3.5)
+	log.Errorf("four %v", errors.New("err"))
+	want := `{"level":"debug","message":"one debug"}` + "\n" +
+		`{"level":"info","message":"two 2"}` + "\n" +
+		`{"level":"warn","message":"three 3.5"}` + "\n" +
+		`{"level":"error","message":"four err"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestLevelfDisabled(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Level(ErrorLevel)
+	s := &countingStringer{}
+	log.Debugf("%v", s)
+	log.Infof("%v", s)
+	log.Warnf("%v", s)
+	if s.calls != 0 {
+		t.Errorf("String called %d times for disabled levels", s.calls)
+	}
+	if out.Len() != 0 {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", decodeIfBinaryToString(out.Bytes()), "")
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		log.Infof("one %s", "two")
+	})
+	if allocs != 0 {
+		t.Errorf("Infof allocated %v times for a disabled level", allocs)
+	}
+}
+
+func TestWithPprofLabels(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).WithPprofLabels("tenant", "endpoint")
+	ctx := context.Background()
+	pprof.Do(ctx, pprof.Labels("tenant", "acme", "other", "x"), func(ctx context.Context) {
+		log.Info().Ctx(ctx).Msg("inside")
+		log.Debug().Ctx(ctx).Str("foo", "bar").Msg("")
+	})
+	log.Info().Ctx(ctx).Msg("outside")
+	log.Info().Msg("no context")
+	want := `{"level":"info","tenant":"acme","message":"inside"}` + "\n" +
+		`{"level":"debug","foo":"bar","tenant":"acme"}` + "\n" +
+		`{"level":"info","message":"outside"}` + "\n" +
+		`{"level":"info","message":"no context"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	pprof.Do(ctx, pprof.Labels("tenant", "acme"), func(ctx context.Context) {
+		log.WithPprofLabels().Info().Ctx(ctx).Msg("")
+	})
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+type principalKey struct{}
+
+func TestRequirePrincipal(t *testing.T) {
+	PrincipalExtractor = func(ctx context.Context) (string, bool) {
+		p, ok := ctx.Value(principalKey{}).(string)
+		return p, ok
+	}
+	defer func() { PrincipalExtractor = nil }()
+	present := context.WithValue(context.Background(), principalKey{}, "alice")
+	absent := context.Background()
+	for _, tt := range []struct {
+		name   string
+		policy PrincipalMissingPolicy
+		want   string
+	}{
+		{"Flag", PrincipalMissingFlag, `{"level":"info","principal":"alice","message":"present"}` + "\n" +
+			`{"level":"info","principal_missing":true,"message":"absent"}` + "\n" +
+			`{"level":"debug","message":"absent below level"}` + "\n" +
+			`{"level":"info","message":"no context"}` + "\n"},
+		{"Drop", PrincipalMissingDrop, `{"level":"info","principal":"alice","message":"present"}` + "\n" +
+			`{"level":"debug","message":"absent below level"}` + "\n" +
+			`{"level":"info","message":"no context"}` + "\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			PrincipalMissingHandling = tt.policy
+			defer func() { PrincipalMissingHandling = PrincipalMissingFlag }()
+			out := &bytes.Buffer{}
+			log := New(out).RequirePrincipal("principal")
+			log.Info().Ctx(present).Msg("present")
+			log.Info().Ctx(absent).Msg("absent")
+			log.Debug().Ctx(absent).Msg("absent below level")
+			log.Info().Msg("no context")
+			if got := decodeIfBinaryToString(out.Bytes()); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestWithAndFieldsCombined(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out).With().Str("f1", "val").Str("f2", "val").Logger()
@@ -658,6 +1051,63 @@ func TestLevelWriter(t *testing.T) {
 	}
 }
 
+func TestWithLevelInvalid(t *testing.T) {
+	custom, err := RegisterLevel(120, "withlevel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.WithLevel(Level(50)).Msg("invalid")
+	log.WithLevel(custom).Msg("custom")
+	if e := log.WithLevel(Disabled); e != nil {
+		t.Errorf("WithLevel(Disabled) = %v, want nil", e)
+	}
+	want := `{"bad_level":50,"message":"invalid"}` + "\n" + `{"level":"withlevel","message":"custom"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	defer func() { InvalidLevelHandling = InvalidLevelAsNoLevel }()
+	InvalidLevelHandling = InvalidLevelDrop
+	log.WithLevel(Level(50)).Msg("invalid")
+	if out.Len() != 0 {
+		t.Errorf("WithLevel() of an invalid level logged %q", decodeIfBinaryToString(out.Bytes()))
+	}
+
+	if e, err := log.WithLevelStrict(Level(50)); e != nil || err == nil {
+		t.Errorf("WithLevelStrict(50) = %v, %v, want nil and an error", e, err)
+	}
+	if e, err := log.WithLevelStrict(Disabled); e != nil || err != nil {
+		t.Errorf("WithLevelStrict(Disabled) = %v, %v, want nil, nil", e, err)
+	}
+	if _, err := log.WithLevelStrict(Level(-5)); err != nil {
+		t.Errorf("WithLevelStrict(-5) error = %v, want nil", err)
+	}
+	for _, level := range []Level{TraceLevel, InfoLevel, NoLevel, custom} {
+		if e, err := log.WithLevelStrict(level); e == nil || err != nil {
+			t.Errorf("WithLevelStrict(%d) = %v, %v, want an event", level, e, err)
+		}
+	}
+}
+
+func TestWithLevelFatalPanic(t *testing.T) {
+	defer func() {
+		if r := recover(); r != nil {
+			t.Errorf("WithLevel(PanicLevel) panicked: %v", r)
+		}
+	}()
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.WithLevel(FatalLevel).Msg("fatal")
+	log.WithLevel(PanicLevel).Msg("panic")
+	want := `{"level":"fatal","message":"fatal"}` + "\n" + `{"level":"panic","message":"panic"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestContextTimestamp(t *testing.T) {
 	TimestampFunc = func() time.Time {
 		return time.Date(2001, time.February, 3, 4, 5, 6, 7, time.UTC)
@@ -776,6 +1226,33 @@ func TestErrorMarshalFunc(t *testing.T) {
 	}
 }
 
+// codedError is an error implementing LogObjectMarshaler.
+type codedError struct {
+	code      string
+	retryable bool
+}
+
+func (e codedError) Error() string {
+	return "failed with " + e.code
+}
+
+func (e codedError) MarshalZerologObject(evt *Event) {
+	evt.Str("code", e.code).Bool("retryable", e.retryable)
+}
+
+func TestErrObjectMarshaler(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().AnErr("cause", codedError{"E1", false}).Logger()
+	log.Log().
+		Err(codedError{"E2", true}).
+		Errs("errs", []error{codedError{"E3", false}, errors.New("plain"), nil}).
+		Msg("")
+	want := `{"cause":{"code":"E1","retryable":false},"error":{"code":"E2","retryable":true},"errs":[{"code":"E3","retryable":false},"plain",null]}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
 func TestCallerMarshalFunc(t *testing.T) {
 	out := &bytes.Buffer{}
 	log := New(out)
@@ -867,6 +1344,32 @@ func TestErrorHandler(t *testing.T) {
 	}
 }
 
+func TestLogError(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out)
+	err := errors.New("boom")
+	if got := log.LogError(err, "failed"); got != err {
+		t.Errorf("LogError() = %v, want %v", got, err)
+	}
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"error","error":"boom","message":"failed"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	if got := log.LogError(nil, "failed"); got != nil {
+		t.Errorf("LogError(nil) = %v, want nil", got)
+	}
+	if out.Len() != 0 {
+		t.Errorf("LogError(nil) logged %q", decodeIfBinaryToString(out.Bytes()))
+	}
+
+	out.Reset()
+	log.With().Caller().Logger().LogError(err, "failed")
+	if got := decodeIfBinaryToString(out.Bytes()); !strings.Contains(got, "log_test.go") {
+		t.Errorf("invalid caller in log output %q", got)
+	}
+}
+
 func TestUpdateEmptyContext(t *testing.T) {
 	var buf bytes.Buffer
 	log := New(&buf)
@@ -957,6 +1460,14 @@ func TestParseLevel(t *testing.T) {
 		{"-1", args{"-1"}, TraceLevel, false},
 		{"-2", args{"-2"}, Level(-2), false},
 		{"-3", args{"-3"}, Level(-3), false},
+		{"4", args{"4"}, FatalLevel, false},
+		{"WARNING", args{"WARNING"}, WarnLevel, false},
+		{"err", args{"err"}, ErrorLevel, false},
+		{"critical", args{"Critical"}, FatalLevel, false},
+		{"whitespace", args{" info\n"}, InfoLevel, false},
+		{"numeric whitespace", args{" 2 "}, WarnLevel, false},
+		{"unknown", args{"verbose"}, NoLevel, true},
+		{"out of bounds", args{"128"}, NoLevel, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -972,6 +1483,261 @@ func TestParseLevel(t *testing.T) {
 	}
 }
 
+func TestLevelJSON(t *testing.T) {
+	type config struct {
+		Level Level `json:"level"`
+	}
+	b, err := json.Marshal(config{WarnLevel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"level":"warn"}`; got != want {
+		t.Errorf("json.Marshal() got = %v, want %v", got, want)
+	}
+	var c config
+	if err := json.Unmarshal([]byte(`{"level":"warning"}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Level != WarnLevel {
+		t.Errorf("json.Unmarshal() got = %v, want %v", c.Level, WarnLevel)
+	}
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice, err := RegisterLevelAbove(10, "notice", InfoLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	audit, err := RegisterLevel(100, "audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := notice.String(), "notice"; got != want {
+		t.Errorf("Level.String() got = %v, want %v", got, want)
+	}
+	if got, err := ParseLevel("NOTICE"); err != nil || got != notice {
+		t.Errorf("ParseLevel() got = %v, %v, want %v", got, err, notice)
+	}
+
+	tests := []struct {
+		level Level
+		want  string
+	}{
+		{InfoLevel, `{"level":"info"}` + "\n" + `{"level":"notice"}` + "\n" + `{"level":"warn"}` + "\n" + `{"level":"audit"}` + "\n"},
+		{notice, `{"level":"notice"}` + "\n" + `{"level":"warn"}` + "\n" + `{"level":"audit"}` + "\n"},
+		{WarnLevel, `{"level":"warn"}` + "\n" + `{"level":"audit"}` + "\n"},
+		{ErrorLevel, `{"level":"audit"}` + "\n"},
+		{Disabled, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			out := &bytes.Buffer{}
+			log := New(out).Level(tt.level)
+			for _, l := range []Level{InfoLevel, notice, WarnLevel, audit} {
+				log.WithLevel(l).Msg("")
+			}
+			if got := decodeIfBinaryToString(out.Bytes()); got != tt.want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+
+	// A level registered above notice is ordered between notice and warn.
+	notice2, err := RegisterLevelAbove(11, "notice2", notice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !levelLess(notice, notice2) || !levelLess(notice2, WarnLevel) {
+		t.Errorf("invalid order of %v between %v and %v", notice2, notice, WarnLevel)
+	}
+
+	for _, tt := range []struct {
+		value int8
+		name  string
+	}{
+		{2, "notice3"},
+		{12, ""},
+		{12, "warning"},
+		{12, "Notice"},
+	} {
+		if _, err := RegisterLevel(tt.value, tt.name); err == nil {
+			t.Errorf("RegisterLevel(%d, %q) should have failed", tt.value, tt.name)
+		}
+	}
+}
+
+type closeWriter struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (w *closeWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestLoggerClose(t *testing.T) {
+	logAfterClose := func(log *Logger) (recovered interface{}) {
+		done := make(chan interface{})
+		go func() {
+			defer func() { done <- recover() }()
+			log.Info().Msg("late")
+		}()
+		return <-done
+	}
+
+	t.Run("Drop", func(t *testing.T) {
+		out := &closeWriter{}
+		log := New(out)
+		log.Info().Msg("early")
+		if err := log.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if !out.closed {
+			t.Error("Close() did not close the writer")
+		}
+		logAfterClose(log)
+		logAfterClose(log)
+		log.Debug().Msg("late")
+		if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"early"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+		if got, want := log.DroppedAfterClose(), uint64(3); got != want {
+			t.Errorf("DroppedAfterClose() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("RedirectToStderr", func(t *testing.T) {
+		stderr := &bytes.Buffer{}
+		afterCloseStderr = stderr
+		defer func() { afterCloseStderr = os.Stderr }()
+		out := &closeWriter{}
+		log := New(out).AfterClose(AfterCloseRedirectToStderr)
+		_ = log.Close()
+		logAfterClose(log)
+		if got := out.String(); got != "" {
+			t.Errorf("closed writer got unexpected output %q", got)
+		}
+		if got, want := decodeIfBinaryToString(stderr.Bytes()), `{"level":"info","message":"late"}`+"\n"; got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+		if got := log.DroppedAfterClose(); got != 0 {
+			t.Errorf("DroppedAfterClose() = %v, want 0", got)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		log := New(&closeWriter{}).AfterClose(AfterClosePanic)
+		_ = log.Close()
+		if r := logAfterClose(log); r == nil {
+			t.Error("logging after Close did not panic")
+		}
+	})
+}
+
+func TestLevelVar(t *testing.T) {
+	lv := NewLevelVar(InfoLevel)
+	out1, out2 := &bytes.Buffer{}, &bytes.Buffer{}
+	log1 := New(out1).LevelVar(lv)
+	log2 := New(out2).LevelVar(lv)
+
+	log1.Debug().Msg("filtered")
+	lv.Set(DebugLevel)
+	log1.Debug().Msg("debug")
+	log2.Debug().Msg("debug")
+	if got, want := log1.GetLevel(), DebugLevel; got != want {
+		t.Errorf("GetLevel() = %v, want %v", got, want)
+	}
+	want := `{"level":"debug","message":"debug"}` + "\n"
+	if got := decodeIfBinaryToString(out1.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := decodeIfBinaryToString(out2.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	log1.Level(WarnLevel)
+	if got, want := log1.GetLevel(), WarnLevel; got != want {
+		t.Errorf("GetLevel() after Level() = %v, want %v", got, want)
+	}
+
+	if err := lv.UnmarshalText([]byte("error")); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := lv.String(), "LevelVar(error)"; got != want {
+		t.Errorf("String() = %v, want %v", got, want)
+	}
+}
+
+func TestLevelVarRace(t *testing.T) {
+	lv := NewLevelVar(InfoLevel)
+	log := New(io.Discard).LevelVar(lv)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			lv.Set(Level(i%3 - 1))
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			log.Debug().Int("i", i).Msg("")
+		}
+	}()
+	wg.Wait()
+}
+
+func TestWithAtomicLevel(t *testing.T) {
+	al := NewAtomicLevel(InfoLevel)
+	out := &bytes.Buffer{}
+	base := New(out).Level(ErrorLevel)
+	log := base.WithAtomicLevel(al)
+
+	log.Debug().Msg("filtered")
+	al.SetLevel(DebugLevel)
+	log.Debug().Msg("debug")
+	al.SetLevel(WarnLevel)
+	log.Info().Msg("filtered")
+	log.Warn().Msg("warn")
+	want := `{"level":"debug","message":"debug"}` + "\n" + `{"level":"warn","message":"warn"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got, want := al.GetLevel(), WarnLevel; got != want {
+		t.Errorf("GetLevel() = %v, want %v", got, want)
+	}
+	if got, want := base.GetLevel(), ErrorLevel; got != want {
+		t.Errorf("WithAtomicLevel() modified the receiver: GetLevel() = %v, want %v", got, want)
+	}
+}
+
+func TestWithClock(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		time time.Time
+		want string
+	}{
+		{"2001", time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC), `{"level":"info","time":"2001-02-03T04:05:06Z"}` + "\n"},
+		{"2010", time.Date(2010, time.March, 4, 5, 6, 7, 0, time.UTC), `{"level":"info","time":"2010-03-04T05:06:07Z"}` + "\n"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			out := &bytes.Buffer{}
+			log := New(out).WithClock(func() time.Time { return tt.time })
+			for i := 0; i < 100; i++ {
+				out.Reset()
+				log.Info().Timestamp().Msg("")
+				if got := decodeIfBinaryToString(out.Bytes()); got != tt.want {
+					t.Fatalf("invalid log output:\ngot:  %v\nwant: %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
 func TestUnmarshalTextLevel(t *testing.T) {
 	type args struct {
 		levelStr string
@@ -1009,3 +1775,119 @@ func TestUnmarshalTextLevel(t *testing.T) {
 		})
 	}
 }
+
+// nestingLoop is a LogObjectMarshaler nesting itself endlessly.
+type nestingLoop struct{}
+
+func (o nestingLoop) MarshalZerologObject(e *Event) {
+	e.Int("n", e.depth).Object("obj", o)
+}
+
+func (o nestingLoop) MarshalZerologArray(a *Array) {
+	a.Object(nestingLoopArr{})
+}
+
+// nestingLoopArr is a LogObjectMarshaler nesting itself endlessly through
+// arrays.
+type nestingLoopArr struct{}
+
+func (o nestingLoopArr) MarshalZerologObject(e *Event) {
+	e.Array("arr", nestingLoop{})
+}
+
+func TestMaxNestingDepth(t *testing.T) {
+	defer func(d int) { MaxNestingDepth = d }(MaxNestingDepth)
+	MaxNestingDepth = 3
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().Object("obj", nestingLoop{}).Str("after", "ok").Msg("")
+	log.Log().Array("arr", nestingLoop{}).Msg("")
+	log.Log().DictFn("d", func(d *Event) { d.Object("obj", nestingLoop{}) }).Msg("")
+	log.Log().ArrayFn("a", func(a *Array) { a.Object(nestingLoopArr{}) }).Msg("")
+	New(out).With().Object("obj", nestingLoop{}).Logger().Log().Msg("")
+
+	want := `{"obj":{"n":1,"obj":{"n":2,"obj":{"n":3}}},"after":"ok","nesting_truncated":true}` + "\n" +
+		`{"arr":[{"arr":[]}],"nesting_truncated":true}` + "\n" +
+		`{"d":{"obj":{"n":2,"obj":{"n":3}}},"nesting_truncated":true}` + "\n" +
+		`{"a":[{"arr":[]}],"nesting_truncated":true}` + "\n" +
+		`{"obj":{"n":1,"obj":{"n":2,"obj":{"n":3}}},"nesting_truncated":true}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+// logWrapper is a logging function of a library wrapping the logger.
+func logWrapper(l *Logger, msg string) {
+	l.Log().Msg(msg)
+}
+
+// logWrapper2 wraps logWrapper.
+func logWrapper2(l *Logger, msg string) {
+	logWrapper(l, msg)
+}
+
+// logEventWrapper wraps the logger with events skipping its own frame.
+func logEventWrapper(l *Logger, msg string) {
+	l.Log().CallerSkipFrame(1).Msg(msg)
+}
+
+// logEventWrapper2 wraps logEventWrapper.
+func logEventWrapper2(l *Logger, msg string) {
+	logEventWrapper(l, msg)
+}
+
+func TestContextCallerStack(t *testing.T) {
+	ErrorStackMarshaler = func(err error) interface{} { return "stack of " + err.Error() }
+	defer func() { ErrorStackMarshaler = nil }()
+	out := &bytes.Buffer{}
+	log := New(out).With().Caller().Stack().Logger()
+
+	_, file, line, _ := runtime.Caller(0)
+	log.Info().Msg("info")
+	log.Error().Err(errors.New("boom")).Msg("error")
+	log.Log().Send()
+	log.Print("print")
+
+	want := fmt.Sprintf(`{"level":"info","caller":"%s:%d","message":"info"}`, file, line+1) + "\n" +
+		fmt.Sprintf(`{"level":"error","stack":"stack of boom","error":"boom","caller":"%s:%d","message":"error"}`, file, line+2) + "\n" +
+		fmt.Sprintf(`{"caller":"%s:%d"}`, file, line+3) + "\n" +
+		fmt.Sprintf(`{"level":"debug","caller":"%s:%d","message":"print"}`, file, line+4) + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestContextFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().
+		Stringers("ips", []fmt.Stringer{net.IP{127, 0, 0, 1}, nil}).
+		Base64Bytes("b64", []byte("hi"), nil).
+		Type("type", 1).
+		Logger()
+	log.Log().Send()
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"ips":["127.0.0.1",null],"b64":"aGk=","type":"int"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestCallerWrappers(t *testing.T) {
+	out := &bytes.Buffer{}
+	one := New(out).With().CallerWithSkipFrameCount(CallerSkipFrameCount + 1).Logger()
+	two := New(out).With().Caller().CallerWithSkipFrameCount(CallerSkipFrameCount + 2).Logger()
+	global := New(out).With().CallerWithSkipFrameCount(-1).Logger()
+
+	_, file, line, _ := runtime.Caller(0)
+	logWrapper(one, "one")
+	logWrapper2(two, "two")
+	logEventWrapper(global, "event")
+	logEventWrapper2(one, "both")
+
+	want := ""
+	for i, msg := range []string{"one", "two", "event", "both"} {
+		want += fmt.Sprintf(`{"caller":"%s:%d","message":"%s"}`, file, line+i+1, msg) + "\n"
+	}
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
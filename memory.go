@@ -0,0 +1,312 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/goccy/go-json"
+)
+
+// ParsedEvent is an event retained by a MemorySink, decoded for inspection.
+type ParsedEvent struct {
+	// Level is the level the event was written with, or the level parsed
+	// from its level field when it was written without one.
+	Level Level
+
+	// Fields holds the decoded fields of the event. Numbers are decoded as
+	// json.Number.
+	Fields map[string]interface{}
+
+	// Raw is the event as it was written.
+	Raw []byte
+}
+
+type memoryEvent struct {
+	level Level
+	raw   []byte
+}
+
+// MemorySink is a LevelWriter retaining the last events written to it in
+// memory so they can be queried later, for instance in examples or while
+// debugging. It is safe for concurrent use.
+type MemorySink struct {
+	mu     sync.Mutex
+	events []memoryEvent // ring buffer
+	start  int
+	n      int
+	max    int
+}
+
+// NewMemorySink creates a MemorySink retaining at most maxEvents events, the
+// oldest events being evicted first. If maxEvents is lower than 1, all events
+// are retained.
+func NewMemorySink(maxEvents int) *MemorySink {
+	if maxEvents < 1 {
+		maxEvents = 0
+	}
+	return &MemorySink{max: maxEvents}
+}
+
+// Write implements the io.Writer interface.
+func (s *MemorySink) Write(p []byte) (n int, err error) {
+	return s.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (s *MemorySink) WriteLevel(l Level, p []byte) (n int, err error) {
+	// p is pooled in zerolog so we can't hold it past this call, hence the
+	// copy.
+	evt := memoryEvent{level: l, raw: append([]byte(nil), p...)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.max == 0:
+		s.events = append(s.events, evt)
+		s.n++
+	case s.n < s.max:
+		s.events = append(s.events, evt)
+		s.n++
+	default:
+		s.events[s.start] = evt
+		s.start = (s.start + 1) % s.max
+	}
+	return len(p), nil
+}
+
+// Clear drops all the retained events.
+func (s *MemorySink) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = nil
+	s.start = 0
+	s.n = 0
+}
+
+// WriteTo writes the retained events, oldest first, to w as they were
+// written to s. It implements the io.WriterTo interface.
+func (s *MemorySink) WriteTo(w io.Writer) (n int64, err error) {
+	for _, evt := range s.snapshot() {
+		m, err := w.Write(evt.raw)
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Query returns the retained events matching filter, oldest first.
+//
+// The filter is a list of terms separated by spaces, all of which must match:
+//
+//	key          the field is present
+//	!key         the field is absent
+//	key=value    the field is equal to value
+//	key!=value   the field is absent or not equal to value
+//	key>value    the field is greater than value, also >=, < and <=
+//
+// Values can be double quoted to contain spaces. Ordering comparisons are
+// numeric, except for the level field where level names are compared by
+// severity. Keys containing dots also match nested fields. An empty filter
+// matches all events.
+func (s *MemorySink) Query(filter string) ([]ParsedEvent, error) {
+	terms, err := parseMemoryFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	var res []ParsedEvent
+	for _, evt := range s.snapshot() {
+		pe, err := parseMemoryEvent(evt)
+		if err != nil {
+			return nil, err
+		}
+		matched := true
+		for _, t := range terms {
+			if !t.match(pe) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			res = append(res, pe)
+		}
+	}
+	return res, nil
+}
+
+// snapshot returns the retained events, oldest first.
+func (s *MemorySink) snapshot() []memoryEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]memoryEvent, 0, s.n)
+	events = append(events, s.events[s.start:]...)
+	return append(events, s.events[:s.start]...)
+}
+
+func parseMemoryEvent(evt memoryEvent) (ParsedEvent, error) {
+	pe := ParsedEvent{Level: evt.level, Raw: evt.raw}
+	d := json.NewDecoder(bytes.NewReader(decodeIfBinaryToBytes(evt.raw)))
+	d.UseNumber()
+	if err := d.Decode(&pe.Fields); err != nil {
+		return pe, fmt.Errorf("cannot decode event: %s", err)
+	}
+	if pe.Level == NoLevel {
+		if l, ok := pe.Fields[LevelFieldName].(string); ok {
+			if lvl, err := ParseLevel(l); err == nil {
+				pe.Level = lvl
+			}
+		}
+	}
+	return pe, nil
+}
+
+type memoryFilterTerm struct {
+	key    string
+	op     string // "", "!", "=", "!=", ">", ">=", "<" or "<="
+	value  string
+	number float64
+}
+
+// parseMemoryFilter splits filter into its terms.
+func parseMemoryFilter(filter string) ([]memoryFilterTerm, error) {
+	var terms []memoryFilterTerm
+	for filter = strings.TrimSpace(filter); filter != ""; filter = strings.TrimSpace(filter) {
+		var t memoryFilterTerm
+		i := strings.IndexAny(filter, " \t=!<>")
+		if i == 0 && filter[0] == '!' {
+			t.op = "!"
+			filter = filter[1:]
+			i = strings.IndexAny(filter, " \t=!<>")
+		}
+		if i < 0 {
+			i = len(filter)
+		}
+		if t.key = filter[:i]; t.key == "" {
+			return nil, fmt.Errorf("invalid filter %q: missing key", filter)
+		}
+		filter = filter[i:]
+		for _, op := range []string{"!=", ">=", "<=", "=", ">", "<"} {
+			if strings.HasPrefix(filter, op) {
+				if t.op == "!" {
+					return nil, fmt.Errorf("invalid filter term !%s%s", t.key, op)
+				}
+				t.op = op
+				filter = filter[len(op):]
+				break
+			}
+		}
+		if t.op != "" && t.op != "!" {
+			var err error
+			if t.value, filter, err = parseMemoryFilterValue(filter); err != nil {
+				return nil, err
+			}
+			if t.op != "=" && t.op != "!=" {
+				if t.number, err = t.ordinal(t.value); err != nil {
+					return nil, fmt.Errorf("invalid filter term %s%s%s: %v", t.key, t.op, t.value, err)
+				}
+			}
+		} else if filter != "" && filter[0] != ' ' && filter[0] != '\t' {
+			return nil, fmt.Errorf("invalid filter near %q", filter)
+		}
+		terms = append(terms, t)
+	}
+	return terms, nil
+}
+
+// parseMemoryFilterValue reads a possibly quoted value at the start of s.
+func parseMemoryFilterValue(s string) (value, rest string, err error) {
+	if strings.HasPrefix(s, `"`) {
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+			case '"':
+				value, err = strconv.Unquote(s[:i+1])
+				return value, s[i+1:], err
+			}
+		}
+		return "", "", fmt.Errorf("invalid filter value %s: missing closing quote", s)
+	}
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		i = len(s)
+	}
+	return s[:i], s[i:], nil
+}
+
+// ordinal returns the value used to order v for the key of t.
+func (t memoryFilterTerm) ordinal(v string) (float64, error) {
+	if t.key == LevelFieldName {
+		l, err := ParseLevel(v)
+		return levelOrder(l), err
+	}
+	return strconv.ParseFloat(v, 64)
+}
+
+func (t memoryFilterTerm) match(pe ParsedEvent) bool {
+	v, ok := lookupMemoryField(pe.Fields, t.key)
+	switch t.op {
+	case "":
+		return ok
+	case "!":
+		return !ok
+	case "!=":
+		return !ok || memoryFieldString(v) != t.value
+	}
+	if !ok {
+		return false
+	}
+	if t.op == "=" {
+		return memoryFieldString(v) == t.value
+	}
+	n, err := t.ordinal(memoryFieldString(v))
+	if err != nil {
+		return false
+	}
+	switch t.op {
+	case ">":
+		return n > t.number
+	case ">=":
+		return n >= t.number
+	case "<":
+		return n < t.number
+	default:
+		return n <= t.number
+	}
+}
+
+// lookupMemoryField returns the field key of fields, walking nested objects
+// for dotted keys.
+func lookupMemoryField(fields map[string]interface{}, key string) (interface{}, bool) {
+	if v, ok := fields[key]; ok {
+		return v, true
+	}
+	if i := strings.IndexByte(key, '.'); i > 0 {
+		if sub, ok := fields[key[:i]].(map[string]interface{}); ok {
+			return lookupMemoryField(sub, key[i+1:])
+		}
+	}
+	return nil, false
+}
+
+// memoryFieldString returns the decoded field value v as a string.
+func memoryFieldString(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case nil:
+		return "null"
+	default:
+		b, err := InterfaceMarshalFunc(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(b)
+	}
+}
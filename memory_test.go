@@ -0,0 +1,124 @@
+package zerolog
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+)
+
+func TestMemorySinkRetention(t *testing.T) {
+	s := NewMemorySink(3)
+	log := New(s)
+	for i := 0; i < 5; i++ {
+		log.Info().Int("i", i).Msg("")
+	}
+	events, err := s.Query("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("invalid number of retained events: got %d, want 3", len(events))
+	}
+	for i, e := range events {
+		if got, want := memoryFieldString(e.Fields["i"]), []string{"2", "3", "4"}[i]; got != want {
+			t.Errorf("invalid retained event %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if _, err := s.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := decodeIfBinaryToString(buf.Bytes())
+	want := `{"level":"info","i":2}` + "\n" + `{"level":"info","i":3}` + "\n" + `{"level":"info","i":4}` + "\n"
+	if got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	s.Clear()
+	if events, _ := s.Query(""); len(events) != 0 {
+		t.Errorf("invalid number of events after Clear: got %d, want 0", len(events))
+	}
+}
+
+func TestMemorySinkQuery(t *testing.T) {
+	s := NewMemorySink(0)
+	log := New(s)
+	log.Debug().Str("tenant", "a").Msg("start")
+	log.Info().Str("tenant", "b").Int("n", 10).Msg("hello world")
+	log.Error().Str("tenant", "a").Int("n", 20).Dict("req", Dict().Str("id", "r1")).Msg("failed")
+	log.Log().Str("tenant", "c").Msg("no level")
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"start", "hello world", "failed", "no level"}},
+		{"tenant=a", []string{"start", "failed"}},
+		{"level=error tenant=a", []string{"failed"}},
+		{"level>=info", []string{"hello world", "failed"}},
+		{"tenant!=a", []string{"hello world", "no level"}},
+		{"n", []string{"hello world", "failed"}},
+		{"!n", []string{"start", "no level"}},
+		{"n>10", []string{"failed"}},
+		{"n<=10", []string{"hello world"}},
+		{`message="hello world"`, []string{"hello world"}},
+		{"req.id=r1", []string{"failed"}},
+		{"tenant=z", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			events, err := s.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range events {
+				got = append(got, e.Fields[MessageFieldName].(string))
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("invalid query result:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("invalid query result:\ngot:  %v\nwant: %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	events, _ := s.Query("level=error")
+	if len(events) != 1 || events[0].Level != ErrorLevel {
+		t.Errorf("invalid event level: got %v, want %v", events, ErrorLevel)
+	}
+
+	for _, filter := range []string{"=a", "n>abc", "level>foo", `message="hello`, "!n=1"} {
+		if _, err := s.Query(filter); err == nil {
+			t.Errorf("Query(%q) should have failed", filter)
+		}
+	}
+}
+
+func TestMemorySinkConcurrent(t *testing.T) {
+	s := NewMemorySink(50)
+	log := New(s)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				log.Info().Int("j", j).Msg("")
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		if _, err := s.Query("level=info j>=0"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if events, _ := s.Query("level=info"); len(events) != 50 {
+		t.Errorf("invalid number of retained events: got %d, want 50", len(events))
+	}
+}
@@ -0,0 +1,90 @@
+package zerolog
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// MetricsLevelWriter is a LevelWriter counting the events and bytes written
+// to its underlying writer per level, to export metrics like the rate of
+// errors without parsing the logs:
+//
+//	w := zerolog.MetricsWriter(os.Stderr)
+//	log := zerolog.New(w)
+//	...
+//	errors := w.Counts()[zerolog.ErrorLevel]
+//
+// Counting only adds atomic increments to the writes. It is safe for
+// concurrent use if the underlying writer is.
+type MetricsLevelWriter struct {
+	// counts and bytes are indexed by level and accessed atomically. They
+	// come first to keep them 64-bit aligned on 32-bit platforms.
+	counts [256]uint64
+	bytes  [256]uint64
+
+	w       io.Writer
+	onWrite func(l Level, n int)
+}
+
+// MetricsWriter creates a MetricsLevelWriter writing to w. The WriteLevel
+// method of w is used if it implements LevelWriter.
+func MetricsWriter(w io.Writer, options ...func(w *MetricsLevelWriter)) *MetricsLevelWriter {
+	mw := &MetricsLevelWriter{w: w}
+	for _, opt := range options {
+		opt(mw)
+	}
+	return mw
+}
+
+// MetricsOnWrite is a MetricsWriter option setting a function called after
+// each write with the level of the event and the number of bytes written,
+// for instance to update a metrics library counter. It must be safe for
+// concurrent use and fast, as it is called on the hot path.
+func MetricsOnWrite(fn func(l Level, n int)) func(w *MetricsLevelWriter) {
+	return func(w *MetricsLevelWriter) {
+		w.onWrite = fn
+	}
+}
+
+// Write implements the io.Writer interface. The events are counted with
+// NoLevel.
+func (w *MetricsLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *MetricsLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	if lw, ok := w.w.(LevelWriter); ok {
+		n, err = lw.WriteLevel(l, p)
+	} else {
+		n, err = w.w.Write(p)
+	}
+	atomic.AddUint64(&w.counts[uint8(l)], 1)
+	atomic.AddUint64(&w.bytes[uint8(l)], uint64(n))
+	if w.onWrite != nil {
+		w.onWrite(l, n)
+	}
+	return n, err
+}
+
+// Counts returns the number of events written per level, for the levels
+// with at least one event.
+func (w *MetricsLevelWriter) Counts() map[Level]uint64 {
+	return metricsSnapshot(&w.counts)
+}
+
+// BytesWritten returns the number of bytes written per level, for the
+// levels with at least one byte written.
+func (w *MetricsLevelWriter) BytesWritten() map[Level]uint64 {
+	return metricsSnapshot(&w.bytes)
+}
+
+func metricsSnapshot(counters *[256]uint64) map[Level]uint64 {
+	m := map[Level]uint64{}
+	for i := range counters {
+		if c := atomic.LoadUint64(&counters[i]); c > 0 {
+			m[Level(int8(i))] = c
+		}
+	}
+	return m
+}
@@ -0,0 +1,58 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	var written int64
+	w := MetricsWriter(out, MetricsOnWrite(func(l Level, n int) {
+		atomic.AddInt64(&written, int64(n))
+	}))
+	log := New(w)
+	log.Info().Msg("a")
+	log.Info().Msg("b")
+	log.Error().Msg("c")
+	log.Trace().Send()
+	_, _ = w.Write([]byte("raw\n"))
+
+	if got, want := w.Counts(), map[Level]uint64{TraceLevel: 1, InfoLevel: 2, ErrorLevel: 1, NoLevel: 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %v, want %v", got, want)
+	}
+	var total uint64
+	for _, n := range w.BytesWritten() {
+		total += n
+	}
+	if total != uint64(out.Len()) || written != int64(out.Len()) {
+		t.Errorf("BytesWritten() total = %d, callback total = %d, want %d", total, written, out.Len())
+	}
+	if got, want := w.BytesWritten()[NoLevel], uint64(len("raw\n")); got != want {
+		t.Errorf("BytesWritten()[NoLevel] = %d, want %d", got, want)
+	}
+}
+
+func TestMetricsWriterConcurrent(t *testing.T) {
+	w := MetricsWriter(SyncWriter(io.Discard))
+	log := New(w)
+	const goroutines, events = 8, 1000
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < events; j++ {
+				log.Warn().Int("j", j).Send()
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := w.Counts()[WarnLevel], uint64(goroutines*events); got != want {
+		t.Errorf("Counts()[WarnLevel] = %d, want %d", got, want)
+	}
+}
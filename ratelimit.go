@@ -0,0 +1,202 @@
+package zerolog
+
+import (
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateLimitSummaryInterval is the default interval between the summaries
+// of the events dropped by a RateLimitLevelWriter.
+const RateLimitSummaryInterval = 10 * time.Second
+
+// RateLimitLevelWriter caps the volume of the events written to Writer, as
+// a safety valve: each level has a token bucket refilled at its rate, in
+// events per second, and the events exceeding it are dropped. The buckets
+// hold one second of events, so that short bursts pass:
+//
+//	w := &zerolog.RateLimitLevelWriter{
+//	    Writer:      os.Stderr,
+//	    DefaultRate: 500,
+//	    Rates:       map[zerolog.Level]float64{zerolog.ErrorLevel: 0},
+//	}
+//
+// Unlike a Sampler, it operates on the writer, so it also limits the other
+// loggers sharing it. It implements Sampler as well, to drop the events of
+// a Logger before they are encoded instead:
+//
+//	log := zerolog.New(os.Stderr).Sample(&zerolog.RateLimitLevelWriter{
+//	    Writer:      os.Stderr,
+//	    DefaultRate: 500,
+//	})
+//
+// It must then not also be the writer of the Logger, as each event would
+// take a token twice, and Writer only receives the summaries.
+//
+// Every SummaryInterval, the next event written is preceded by a summary
+// with warn level and the number of events dropped since the previous one
+// per level, in the DroppedFieldName field. The fatal and panic events are
+// never dropped. It is safe for concurrent use. Close writes the pending
+// summary.
+type RateLimitLevelWriter struct {
+	Writer io.Writer
+
+	// Rates is the maximum rate of the events of each level, in events per
+	// second. The levels with a rate of 0 or less are not limited.
+	Rates map[Level]float64
+
+	// DefaultRate is the rate of the levels missing from Rates. They are
+	// not limited if it is 0 or less.
+	DefaultRate float64
+
+	// SummaryInterval is the minimum interval between the summaries,
+	// RateLimitSummaryInterval if 0.
+	SummaryInterval time.Duration
+
+	// Clock returns the current time, time.Now if nil.
+	Clock func() time.Time
+
+	mu          sync.Mutex
+	buckets     map[Level]*tokenBucket
+	dropped     map[Level]uint64
+	lastSummary time.Time
+}
+
+// tokenBucket is the token bucket of a level of a RateLimitLevelWriter.
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take takes a token from b at now, refilling it at rate, and returns false
+// if it is empty.
+func (b *tokenBucket) take(rate float64, now time.Time) bool {
+	burst := math.Max(rate, 1)
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(burst, b.tokens+elapsed.Seconds()*rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Write implements the io.Writer interface.
+func (w *RateLimitLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface. The dropped events are
+// reported as written.
+func (w *RateLimitLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	now := w.now()
+	allowed := w.allow(l, now)
+	if now.Sub(w.lastSummary) >= w.summaryInterval() {
+		w.summarize(now)
+	}
+	w.mu.Unlock()
+	if !allowed {
+		return len(p), nil
+	}
+	if lw, ok := w.Writer.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Writer.Write(p)
+}
+
+// Sample implements the Sampler interface. The summary is written to Writer
+// before the event, if any.
+func (w *RateLimitLevelWriter) Sample(lvl Level) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	now := w.now()
+	allowed := w.allow(lvl, now)
+	if now.Sub(w.lastSummary) >= w.summaryInterval() {
+		w.summarize(now)
+	}
+	return allowed
+}
+
+// allow takes a token for an event of level l at now, and counts it as
+// dropped if there is none.
+func (w *RateLimitLevelWriter) allow(l Level, now time.Time) bool {
+	if l == FatalLevel || l == PanicLevel {
+		return true
+	}
+	rate, ok := w.Rates[l]
+	if !ok {
+		rate = w.DefaultRate
+	}
+	if rate <= 0 {
+		return true
+	}
+	if w.buckets == nil {
+		w.buckets = map[Level]*tokenBucket{}
+	}
+	b := w.buckets[l]
+	if b == nil {
+		b = &tokenBucket{}
+		w.buckets[l] = b
+	}
+	if b.take(rate, now) {
+		return true
+	}
+	if w.dropped == nil {
+		w.dropped = map[Level]uint64{}
+	}
+	w.dropped[l]++
+	return false
+}
+
+// summarize writes the summary of the events dropped since the previous
+// one, if any, at now.
+func (w *RateLimitLevelWriter) summarize(now time.Time) {
+	w.lastSummary = now
+	if len(w.dropped) == 0 {
+		return
+	}
+	levels := make([]Level, 0, len(w.dropped))
+	for l := range w.dropped {
+		levels = append(levels, l)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	dropped := Dict()
+	for _, l := range levels {
+		dropped.Uint64(l.String(), w.dropped[l])
+		delete(w.dropped, l)
+	}
+	New(w.Writer).WithClock(w.now).Warn().
+		Timestamp().
+		Dict(DroppedFieldName, dropped).
+		Msg("rate limit exceeded, events dropped")
+}
+
+func (w *RateLimitLevelWriter) now() time.Time {
+	if w.Clock != nil {
+		return w.Clock()
+	}
+	return time.Now()
+}
+
+func (w *RateLimitLevelWriter) summaryInterval() time.Duration {
+	if w.SummaryInterval > 0 {
+		return w.SummaryInterval
+	}
+	return RateLimitSummaryInterval
+}
+
+// Close writes the summary of the events dropped since the previous one.
+// It doesn't close Writer.
+func (w *RateLimitLevelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.summarize(w.now())
+	return nil
+}
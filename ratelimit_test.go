@@ -0,0 +1,91 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRateLimitLevelWriter(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	w := &RateLimitLevelWriter{
+		Writer:          out,
+		DefaultRate:     2,
+		Rates:           map[Level]float64{DebugLevel: 1, ErrorLevel: 0},
+		SummaryInterval: time.Second,
+		Clock:           func() time.Time { return now },
+	}
+	log := New(w)
+	for i := 0; i < 3; i++ {
+		log.Info().Int("i", i).Send()
+		log.Debug().Int("i", i).Send()
+		log.Error().Int("i", i).Send()
+	}
+	now = now.Add(500 * time.Millisecond)
+	log.Info().Int("i", 3).Send()  // refilled with one token
+	log.Debug().Int("i", 3).Send() // refilled with half a token
+	now = now.Add(500 * time.Millisecond)
+	log.Info().Int("i", 4).Send() // after the summary
+
+	want := `{"level":"info","i":0}` + "\n" +
+		`{"level":"debug","i":0}` + "\n" +
+		`{"level":"error","i":0}` + "\n" +
+		`{"level":"info","i":1}` + "\n" +
+		`{"level":"error","i":1}` + "\n" +
+		`{"level":"error","i":2}` + "\n" +
+		`{"level":"info","i":3}` + "\n" +
+		`{"level":"warn","time":"2024-06-01T12:00:01Z","dropped":{"debug":3,"info":1},"message":"rate limit exceeded, events dropped"}` + "\n" +
+		`{"level":"info","i":4}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRateLimitLevelWriterClose(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	w := &RateLimitLevelWriter{
+		Writer:      out,
+		DefaultRate: 1,
+		Clock:       func() time.Time { return now },
+	}
+	log := New(w)
+	log.Warn().Send()
+	log.Warn().Send()
+	log.WithLevel(FatalLevel).Send()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"warn"}` + "\n" +
+		`{"level":"fatal"}` + "\n" +
+		`{"level":"warn","time":"2024-06-01T12:00:00Z","dropped":{"warn":1},"message":"rate limit exceeded, events dropped"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRateLimitLevelWriterSampler(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	out := &bytes.Buffer{}
+	w := &RateLimitLevelWriter{
+		Writer:      out,
+		DefaultRate: 1,
+		Clock:       func() time.Time { return now },
+	}
+	log := New(out).Sample(w)
+	log.Info().Msg("a")
+	log.Info().Msg("b")
+	now = now.Add(time.Second)
+	log.Info().Msg("c")
+	log.Info().Msg("d")
+	now = now.Add(RateLimitSummaryInterval)
+	log.Info().Msg("e")
+	want := `{"level":"info","message":"a"}` + "\n" +
+		`{"level":"info","message":"c"}` + "\n" +
+		`{"level":"warn","time":"2024-06-01T12:00:11Z","dropped":{"info":2},"message":"rate limit exceeded, events dropped"}` + "\n" +
+		`{"level":"info","message":"e"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,305 @@
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/x0f5c3/zerolog/internal/cbor"
+	ijson "github.com/x0f5c3/zerolog/internal/json"
+)
+
+// ReplayOptions configures Replay.
+type ReplayOptions struct {
+	// Context cancels the replay, context.Background() if nil.
+	Context context.Context
+
+	// Speed multiplies the pace of the replay: at 2, the events are written
+	// twice as fast as they were logged. It is 1 if lower than or equal to
+	// 0, and math.Inf(1) writes the events without waiting.
+	Speed float64
+
+	// Loops is the number of times the input is replayed, 1 if lower than
+	// 1. The events are retained in memory to be replayed more than once.
+	Loops int
+
+	// RewriteTimestamps replaces the TimestampFieldName field of the events
+	// by the time returned by TimestampFunc when they are written, in the
+	// format of the event.
+	RewriteTimestamps bool
+}
+
+var (
+	// replayNow and replaySleep are the clock of Replay.
+	replayNow   = time.Now
+	replaySleep = func(ctx context.Context, d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return nil
+		}
+	}
+)
+
+// Replay reads the events of a capture from r, in JSON lines or in binary
+// format, and writes them to w with the same gaps between them as between
+// their TimestampFieldName fields, for instance to load test a log pipeline.
+// The timestamps are parsed with TimeFieldFormat, which must have the value
+// it had when the events were logged. The events without a timestamp are
+// written right after the previous event. Each event is written with a
+// single call to w.Write.
+//
+// Replay returns the first error encountered, or the error of the context
+// if it is cancelled.
+func Replay(r io.Reader, w io.Writer, opts ReplayOptions) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	rp := replayer{w: w, ctx: ctx, speed: opts.Speed, rewrite: opts.RewriteTimestamps}
+	if rp.speed <= 0 {
+		rp.speed = 1
+	}
+	loops := opts.Loops
+	if loops < 1 {
+		loops = 1
+	}
+
+	next := replayReader(bufio.NewReader(r))
+	var events [][]byte
+	for {
+		evt, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if loops > 1 {
+			events = append(events, evt)
+		}
+		if err := rp.replay(evt); err != nil {
+			return err
+		}
+	}
+	for i := 1; i < loops; i++ {
+		rp.first = time.Time{}
+		for _, evt := range events {
+			if err := rp.replay(evt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// replayReader returns a function returning the events read from r one by
+// one, then io.EOF.
+func replayReader(r *bufio.Reader) func() ([]byte, error) {
+	b, err := r.Peek(1)
+	if err != nil {
+		return func() ([]byte, error) { return nil, err }
+	}
+	if b[0] > 0x7f {
+		// Binary events are not delimited, so read them all to find the
+		// bounds of each.
+		data, err := io.ReadAll(r)
+		return func() ([]byte, error) {
+			if err != nil {
+				return nil, err
+			}
+			if len(data) == 0 {
+				return nil, io.EOF
+			}
+			n, ok := cbor.ItemLen(data)
+			if !ok {
+				return nil, errors.New("cannot decode binary event: incomplete or invalid data")
+			}
+			evt := data[:n:n]
+			data = data[n:]
+			return evt, nil
+		}
+	}
+	return func() ([]byte, error) {
+		for {
+			line, err := r.ReadBytes('\n')
+			if len(bytes.TrimSpace(line)) > 0 {
+				if len(line) > 0 && line[len(line)-1] != '\n' {
+					line = append(line, '\n')
+				}
+				return line, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// replayer writes the events of a replay on time.
+type replayer struct {
+	w       io.Writer
+	ctx     context.Context
+	speed   float64
+	rewrite bool
+	first   time.Time // timestamp of the first event of the loop
+	start   time.Time // time the first event of the loop was written at
+}
+
+// replay waits for the time of evt, then writes it.
+func (rp *replayer) replay(evt []byte) error {
+	start, end, ok := replayTimeBounds(evt)
+	if ok {
+		if t, ok := replayParseTime(evt[start:end]); ok {
+			if rp.first.IsZero() {
+				rp.first, rp.start = t, replayNow()
+			} else {
+				at := rp.start.Add(time.Duration(float64(t.Sub(rp.first)) / rp.speed))
+				if d := at.Sub(replayNow()); d > 0 {
+					if err := replaySleep(rp.ctx, d); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	if err := rp.ctx.Err(); err != nil {
+		return err
+	}
+	if rp.rewrite && ok {
+		buf := make([]byte, 0, len(evt)+16)
+		buf = append(buf, evt[:start]...)
+		if evt[0] > 0x7f {
+			buf = cbor.Encoder{}.AppendTime(buf, TimestampFunc(), "")
+		} else {
+			buf = ijson.Encoder{}.AppendTime(buf, TimestampFunc(), TimeFieldFormat)
+		}
+		evt = append(buf, evt[end:]...)
+	}
+	_, err := rp.w.Write(evt)
+	return err
+}
+
+// replayTimeBounds returns the bounds of the value of the
+// TimestampFieldName field of evt, or false if it has none.
+func replayTimeBounds(evt []byte) (start, end int, ok bool) {
+	if evt[0] > 0x7f {
+		return cbor.MapValue(evt, TimestampFieldName)
+	}
+	return jsonFieldValue(evt, TimestampFieldName)
+}
+
+// replayParseTime parses the timestamp v, a JSON or binary value.
+func replayParseTime(v []byte) (t time.Time, ok bool) {
+	if len(v) > 0 && v[0] > 0x7f {
+		// Decode the tag of the time to its JSON rendering, an RFC 3339
+		// string. The decoder panics on invalid data.
+		defer func() {
+			if recover() != nil {
+				t, ok = time.Time{}, false
+			}
+		}()
+		v = []byte(cbor.DecodeObjectToStr(v))
+	}
+	var src interface{} = json.Number(v)
+	if len(v) > 0 && v[0] == '"' {
+		s, err := strconv.Unquote(string(v))
+		if err != nil {
+			return time.Time{}, false
+		}
+		src = s
+	}
+	t, err := unmarshalTime(src)
+	return t, err == nil
+}
+
+// jsonFieldValue returns the bounds of the value of the top level field key
+// of the JSON object p, or false if it has no such field.
+func jsonFieldValue(p []byte, key string) (start, end int, ok bool) {
+	i := jsonSkipSpace(p, 0)
+	if i >= len(p) || p[i] != '{' {
+		return 0, 0, false
+	}
+	for i = jsonSkipSpace(p, i+1); i < len(p) && p[i] == '"'; {
+		k := jsonValueEnd(p, i)
+		if k < 0 {
+			return 0, 0, false
+		}
+		name := p[i+1 : k-1]
+		i = jsonSkipSpace(p, k)
+		if i >= len(p) || p[i] != ':' {
+			return 0, 0, false
+		}
+		i = jsonSkipSpace(p, i+1)
+		end := jsonValueEnd(p, i)
+		if end < 0 {
+			return 0, 0, false
+		}
+		if string(name) == key {
+			return i, end, true
+		}
+		i = jsonSkipSpace(p, end)
+		if i >= len(p) || p[i] != ',' {
+			return 0, 0, false
+		}
+		i = jsonSkipSpace(p, i+1)
+	}
+	return 0, 0, false
+}
+
+// jsonSkipSpace returns the index of the first byte of p[i:] which is not a
+// space.
+func jsonSkipSpace(p []byte, i int) int {
+	for i < len(p) && (p[i] == ' ' || p[i] == '\t' || p[i] == '\r' || p[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// jsonValueEnd returns the end of the JSON value starting at p[i], or -1 if
+// it is not terminated.
+func jsonValueEnd(p []byte, i int) int {
+	depth := 0
+	for ; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '"':
+			for i++; i < len(p) && p[i] != '"'; i++ {
+				if p[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(p) {
+				return -1
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth < 0 {
+				return i
+			}
+			if depth == 0 {
+				return i + 1
+			}
+		case ',', ' ', '\t', '\r', '\n':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	if depth == 0 {
+		return i
+	}
+	return -1
+}
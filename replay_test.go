@@ -0,0 +1,133 @@
+package zerolog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+const replayCapture = `{"level":"info","time":"2001-02-03T04:05:06Z","message":"a"}
+{"level":"info","message":"no time"}
+{"level":"info","time":"2001-02-03T04:05:07Z","message":"b"}
+
+{"level":"info","time":"2001-02-03T04:05:09Z","message":"c","data":{"time":1}}`
+
+// fakeReplayClock replaces the clock of Replay, recording the sleeps.
+func fakeReplayClock(t *testing.T) *[]time.Duration {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	var sleeps []time.Duration
+	oldNow, oldSleep := replayNow, replaySleep
+	replayNow = func() time.Time { return now }
+	replaySleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { replayNow, replaySleep = oldNow, oldSleep })
+	return &sleeps
+}
+
+func TestReplay(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   ReplayOptions
+		sleeps []time.Duration
+		loops  int
+	}{
+		{"default", ReplayOptions{}, []time.Duration{time.Second, 2 * time.Second}, 1},
+		{"speed", ReplayOptions{Speed: 2}, []time.Duration{500 * time.Millisecond, time.Second}, 1},
+		{"loops", ReplayOptions{Loops: 2}, []time.Duration{time.Second, 2 * time.Second, time.Second, 2 * time.Second}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sleeps := fakeReplayClock(t)
+			out := &bytes.Buffer{}
+			if err := Replay(strings.NewReader(replayCapture), out, tt.opts); err != nil {
+				t.Fatalf("Replay() error = %v", err)
+			}
+			if !reflect.DeepEqual(*sleeps, tt.sleeps) {
+				t.Errorf("Replay() sleeps = %v, want %v", *sleeps, tt.sleeps)
+			}
+			want := strings.Repeat(strings.Replace(replayCapture, "\n\n", "\n", 1)+"\n", tt.loops)
+			if got := out.String(); got != want {
+				t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReplayRewriteTimestamps(t *testing.T) {
+	fakeReplayClock(t)
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	TimestampFunc = func() time.Time { return time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC) }
+
+	out := &bytes.Buffer{}
+	if err := Replay(strings.NewReader(replayCapture), out, ReplayOptions{RewriteTimestamps: true}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	want := `{"level":"info","time":"2020-05-06T07:08:09Z","message":"a"}
+{"level":"info","message":"no time"}
+{"level":"info","time":"2020-05-06T07:08:09Z","message":"b"}
+{"level":"info","time":"2020-05-06T07:08:09Z","message":"c","data":{"time":1}}
+`
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestReplayBinary(t *testing.T) {
+	sleeps := fakeReplayClock(t)
+	defer func(f func() time.Time) { TimestampFunc = f }(TimestampFunc)
+	now := time.Date(2020, 5, 6, 7, 8, 9, 0, time.UTC)
+	TimestampFunc = func() time.Time { return now }
+
+	var enc cbor.Encoder
+	event := func(ts time.Time, msg string) []byte {
+		b := enc.AppendBeginMarker(nil)
+		b = enc.AppendTime(enc.AppendKey(b, "time"), ts, "")
+		b = enc.AppendString(enc.AppendKey(b, "message"), msg)
+		return enc.AppendEndMarker(b)
+	}
+	start := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	var capture []byte
+	capture = append(capture, event(start, "a")...)
+	capture = append(capture, event(start.Add(1500*time.Millisecond), "b")...)
+
+	out := &bytes.Buffer{}
+	if err := Replay(bytes.NewReader(capture), out, ReplayOptions{RewriteTimestamps: true}); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if want := []time.Duration{1500 * time.Millisecond}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("Replay() sleeps = %v, want %v", *sleeps, want)
+	}
+	want := string(event(now, "a")) + string(event(now, "b"))
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	err := Replay(bytes.NewReader(capture[:len(capture)-1]), out, ReplayOptions{})
+	if err == nil {
+		t.Errorf("Replay() of a truncated capture error = nil")
+	}
+}
+
+func TestReplayCancel(t *testing.T) {
+	fakeReplayClock(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	out := &bytes.Buffer{}
+	err := Replay(strings.NewReader(replayCapture), out, ReplayOptions{Context: ctx})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Replay() error = %v, want %v", err, context.Canceled)
+	}
+	if out.Len() != 0 {
+		t.Errorf("Replay() wrote %q after cancellation", out.String())
+	}
+}
@@ -0,0 +1,143 @@
+package zerolog
+
+import (
+	"io"
+	"sync"
+)
+
+// RingLevelWriter is a LevelWriter keeping the last events written to it in
+// memory, for post-mortem debugging. Created with RingWriter, the verbose
+// events are kept without being written anywhere, and dumped only when the
+// program fails:
+//
+//	ring := zerolog.RingWriter(64 << 10)
+//	log := zerolog.New(zerolog.MultiLevelWriter(
+//	    zerolog.FilteredLevelWriter{Writer: os.Stderr, Level: zerolog.InfoLevel},
+//	    ring,
+//	)).Hook(ring.DumpHook(os.Stderr))
+//	defer ring.DumpOnPanic(os.Stderr)()
+//
+// Created with RingTeeWriter, the events are written to another writer as
+// well. It is safe for concurrent use.
+type RingLevelWriter struct {
+	out      io.Writer // writer the events are forwarded to, or nil
+	max      int       // maximum size of lines, or 0
+	maxLines int       // maximum number of lines, or 0
+
+	mu    sync.Mutex
+	lines [][]byte // oldest first
+	size  int      // total size of lines
+}
+
+// RingWriter creates a RingLevelWriter keeping the last size bytes of
+// events. Each write is kept as a line, dropped as a whole when the older
+// lines exceed size, so that the lines are never split. A line larger than
+// size is truncated from the front.
+func RingWriter(size int) *RingLevelWriter {
+	return &RingLevelWriter{max: size}
+}
+
+// RingTeeWriter creates a RingLevelWriter writing the events to w and
+// keeping the last n of them, for instance to dump them from a deferred
+// panic handler with Tail or Dump. The WriteLevel method of w is used if it
+// implements LevelWriter.
+func RingTeeWriter(w io.Writer, n int) *RingLevelWriter {
+	return &RingLevelWriter{out: w, maxLines: n}
+}
+
+// Write implements the io.Writer interface.
+func (w *RingLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RingLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	n = len(p)
+	if w.out != nil {
+		if lw, ok := w.out.(LevelWriter); ok {
+			n, err = lw.WriteLevel(l, p)
+		} else {
+			n, err = w.out.Write(p)
+		}
+	}
+	w.keep(p)
+	return n, err
+}
+
+// keep adds a copy of p to the lines, dropping the oldest ones above the
+// limits of w.
+func (w *RingLevelWriter) keep(p []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.max > 0 && len(p) > w.max {
+		p = p[len(p)-w.max:]
+	}
+	w.lines = append(w.lines, append([]byte(nil), p...))
+	w.size += len(p)
+	drop := 0
+	for (w.max > 0 && w.size > w.max) || (w.maxLines > 0 && len(w.lines)-drop > w.maxLines) {
+		w.size -= len(w.lines[drop])
+		drop++
+	}
+	if drop > 0 {
+		k := copy(w.lines, w.lines[drop:])
+		for i := k; i < len(w.lines); i++ {
+			w.lines[i] = nil
+		}
+		w.lines = w.lines[:k]
+	}
+}
+
+// Tail returns a copy of the lines kept by w, oldest first.
+func (w *RingLevelWriter) Tail() [][]byte {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	tail := make([][]byte, len(w.lines))
+	for i, line := range w.lines {
+		tail[i] = append([]byte(nil), line...)
+	}
+	return tail
+}
+
+// Dump writes the lines kept by w to out, oldest first, each with its own
+// call to out.Write. They are kept, to be dumped again.
+func (w *RingLevelWriter) Dump(out io.Writer) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, line := range w.lines {
+		if _, err := out.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpHook returns a Hook dumping the lines kept by w to out before writing
+// the fatal and panic events, which are then written after them.
+func (w *RingLevelWriter) DumpHook(out io.Writer) Hook {
+	return HookFunc(func(e *Event, level Level, message string) {
+		if level == FatalLevel || level == PanicLevel {
+			if err := w.Dump(out); err != nil {
+				handleWriteError(err)
+			}
+		}
+	})
+}
+
+// DumpOnPanic returns a function which, deferred, dumps the lines kept by w
+// to out if the calling goroutine panics, before panicking again with the
+// same value:
+//
+//	defer ring.DumpOnPanic(os.Stderr)()
+func (w *RingLevelWriter) DumpOnPanic(out io.Writer) func() {
+	return func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		if err := w.Dump(out); err != nil {
+			handleWriteError(err)
+		}
+		panic(r)
+	}
+}
@@ -0,0 +1,123 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRingWriter(t *testing.T) {
+	w := RingWriter(21)
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(w, "line %d\n", i) // 7 bytes
+	}
+	out := &bytes.Buffer{}
+	if err := w.Dump(out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "line 7\nline 8\nline 9\n"; got != want {
+		t.Errorf("invalid dump:\ngot:  %q\nwant: %q", got, want)
+	}
+
+	fmt.Fprintf(w, "a line larger than the buffer\n")
+	out.Reset()
+	if err := w.Dump(out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "rger than the buffer\n"; got != want {
+		t.Errorf("invalid dump:\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestRingWriterConcurrent(t *testing.T) {
+	w := RingWriter(1 << 10)
+	const goroutines, lines = 8, 100
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < lines; j++ {
+				fmt.Fprintf(w, "%d %03d\n", i, j)
+			}
+		}(i)
+	}
+	wg.Wait()
+	out := &bytes.Buffer{}
+	if err := w.Dump(out); err != nil {
+		t.Fatal(err)
+	}
+	dumped := strings.SplitAfter(out.String(), "\n")
+	dumped = dumped[:len(dumped)-1]
+	if got, want := len(dumped), 1<<10/len("0 000\n"); got != want {
+		t.Fatalf("dumped %d lines, want %d", got, want)
+	}
+	last := map[string]string{}
+	for _, line := range dumped {
+		if len(line) != len("0 000\n") {
+			t.Fatalf("invalid line %q", line)
+		}
+		g := line[:1]
+		if line <= last[g] {
+			t.Errorf("line %q dumped after %q", line, last[g])
+		}
+		last[g] = line
+	}
+}
+
+func TestRingWriterDump(t *testing.T) {
+	ring := RingWriter(1 << 10)
+	out := &bytes.Buffer{}
+	log := New(MultiLevelWriter(FilteredLevelWriter{Writer: out, Level: InfoLevel}, ring)).Hook(ring.DumpHook(out))
+	log.Debug().Msg("a")
+	log.Info().Msg("b")
+	log.WithLevel(FatalLevel).Msg("c")
+	want := `{"level":"info","message":"b"}` + "\n" +
+		`{"level":"debug","message":"a"}` + "\n" +
+		`{"level":"info","message":"b"}` + "\n" +
+		`{"level":"fatal","message":"c"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	var recovered interface{}
+	func() {
+		defer func() { recovered = recover() }()
+		defer ring.DumpOnPanic(out)()
+		panic("boom")
+	}()
+	if recovered != "boom" {
+		t.Errorf("invalid panic value: %v", recovered)
+	}
+	want = `{"level":"debug","message":"a"}` + "\n" +
+		`{"level":"info","message":"b"}` + "\n" +
+		`{"level":"fatal","message":"c"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestRingTeeWriter(t *testing.T) {
+	const n = 5
+	out := &bytes.Buffer{}
+	w := RingTeeWriter(out, n)
+	log := New(w)
+	for i := 0; i < 2*n; i++ {
+		log.Info().Int("i", i).Send()
+	}
+	if got, want := strings.Count(decodeIfBinaryToString(out.Bytes()), "\n"), 2*n; got != want {
+		t.Errorf("forwarded %d lines, want %d", got, want)
+	}
+	tail := w.Tail()
+	if len(tail) != n {
+		t.Fatalf("Tail() returned %d lines, want %d", len(tail), n)
+	}
+	for i, line := range tail {
+		if got, want := decodeIfBinaryToString(line), fmt.Sprintf(`{"level":"info","i":%d}`, n+i)+"\n"; got != want {
+			t.Errorf("invalid line %d:\ngot:  %v\nwant: %v", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package zerolog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rotateTimeFormat is the format of the time suffix of the backups of a
+// RotateWriter, in UTC.
+const rotateTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// RotateWriter is a writer to the file Filename which rotates it when it
+// grows above MaxSizeBytes: the file is renamed with the time of the
+// rotation as suffix, like app-2024-06-01T12-00-00.000000000.log for
+// app.log, and a new file is created. It is safe for concurrent use.
+//
+// The file is opened, in append mode, on the first write. Reopen reopens
+// it, for instance on SIGHUP once an external tool like logrotate moved it:
+//
+//	c := make(chan os.Signal, 1)
+//	signal.Notify(c, syscall.SIGHUP)
+//	go func() {
+//	    for range c {
+//	        _ = w.Reopen()
+//	    }
+//	}()
+type RotateWriter struct {
+	Filename string
+
+	// MaxSizeBytes is the size above which the file is rotated. The file is
+	// never rotated if it is 0.
+	MaxSizeBytes int64
+
+	// MaxAge is the age above which the backups are removed, after a
+	// rotation. They are kept regardless of their age if it is 0.
+	MaxAge time.Duration
+
+	// MaxBackups is the number of backups kept after a rotation, the most
+	// recent ones. They are all kept if it is 0.
+	MaxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+
+	// header is written at the start of each file opened, before the first
+	// write, see SetSegmentHeader.
+	header        func() []byte
+	headerPending bool
+}
+
+// Write implements the io.Writer interface. The file is rotated before
+// writing p if p would make it grow above MaxSizeBytes, unless it is empty:
+// p is always written to a single file.
+func (w *RotateWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	if w.MaxSizeBytes > 0 && w.size > 0 && w.size+int64(len(p)) > w.MaxSizeBytes {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	if w.headerPending {
+		w.headerPending = false
+		hn, err := w.file.Write(w.header())
+		w.size += int64(hn)
+		if err != nil {
+			return 0, err
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// SetSegmentHeader implements the SegmentedWriter interface. The header is
+// written to the file opened by the first write, Reopen or a rotation.
+func (w *RotateWriter) SetSegmentHeader(header func() []byte) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.header = header
+	w.headerPending = header != nil && w.file != nil
+}
+
+// Reopen closes the file and opens Filename again, creating it if it was
+// moved or removed.
+func (w *RotateWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.close(); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+// Close closes the file. The next write opens it again.
+func (w *RotateWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.close()
+}
+
+func (w *RotateWriter) open() error {
+	if w.Filename == "" {
+		return errors.New("zerolog: RotateWriter without Filename")
+	}
+	f, err := os.OpenFile(w.Filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.file, w.size = f, info.Size()
+	w.headerPending = w.header != nil
+	return nil
+}
+
+func (w *RotateWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file, w.size = nil, 0
+	return err
+}
+
+// rotate renames the file with the current time as suffix, opens a new one
+// and removes the backups exceeding MaxAge or MaxBackups.
+func (w *RotateWriter) rotate() error {
+	if err := w.close(); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	prefix, ext := w.backupPrefix()
+	if err := os.Rename(w.Filename, prefix+now.Format(rotateTimeFormat)+ext); err != nil {
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	return w.prune(now)
+}
+
+// backupPrefix returns the path of the backups up to their time suffix,
+// and their extension, the one of Filename.
+func (w *RotateWriter) backupPrefix() (prefix, ext string) {
+	ext = filepath.Ext(w.Filename)
+	return strings.TrimSuffix(w.Filename, ext) + "-", ext
+}
+
+// prune removes the backups exceeding MaxAge or MaxBackups at now.
+func (w *RotateWriter) prune(now time.Time) error {
+	if w.MaxAge <= 0 && w.MaxBackups <= 0 {
+		return nil
+	}
+	prefix, ext := w.backupPrefix()
+	entries, err := os.ReadDir(filepath.Dir(w.Filename))
+	if err != nil {
+		return err
+	}
+	type backup struct {
+		path string
+		t    time.Time
+	}
+	var backups []backup
+	base := filepath.Base(prefix)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || len(name) < len(base)+len(ext) || !strings.HasPrefix(name, base) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, err := time.Parse(rotateTimeFormat, name[len(base):len(name)-len(ext)])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{filepath.Join(filepath.Dir(w.Filename), name), t})
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].t.After(backups[j].t)
+	})
+	for i, b := range backups {
+		if (w.MaxBackups > 0 && i >= w.MaxBackups) || (w.MaxAge > 0 && now.Sub(b.t) > w.MaxAge) {
+			if rerr := os.Remove(b.path); err == nil {
+				err = rerr
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,156 @@
+package zerolog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rotateBackups returns the names of the backups of the file name in dir.
+func rotateBackups(t *testing.T, dir, name string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var backups []string
+	for _, e := range entries {
+		if e.Name() != name {
+			backups = append(backups, e.Name())
+		}
+	}
+	return backups
+}
+
+func TestRotateWriter(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Filename: filepath.Join(dir, "app.log"), MaxSizeBytes: 11}
+	defer w.Close()
+	for _, s := range []string{"12345\n", "6789\n", "abcde\n"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups := rotateBackups(t, dir, "app.log")
+	if len(backups) != 1 || !strings.HasPrefix(backups[0], "app-") || !strings.HasSuffix(backups[0], ".log") {
+		t.Fatalf("invalid backups: %v", backups)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, backups[0])); string(b) != "12345\n6789\n" {
+		t.Errorf("invalid backup: %q", b)
+	}
+	if b, _ := os.ReadFile(w.Filename); string(b) != "abcde\n" {
+		t.Errorf("invalid file: %q", b)
+	}
+}
+
+func TestRotateWriterMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Filename: filepath.Join(dir, "app.log"), MaxSizeBytes: 1, MaxBackups: 2}
+	defer w.Close()
+	for _, s := range []string{"1", "2", "3", "4", "5"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups := rotateBackups(t, dir, "app.log")
+	if len(backups) != 2 {
+		t.Fatalf("invalid backups: %v", backups)
+	}
+	// The backups are sorted by name, and so by time.
+	for i, want := range []string{"3", "4"} {
+		if b, _ := os.ReadFile(filepath.Join(dir, backups[i])); string(b) != want {
+			t.Errorf("invalid backup %d: %q, want: %q", i, b, want)
+		}
+	}
+}
+
+func TestRotateWriterMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-"+time.Now().Add(-2*time.Hour).UTC().Format(rotateTimeFormat)+".log")
+	other := filepath.Join(dir, "other.log")
+	for _, f := range []string{old, other} {
+		if err := os.WriteFile(f, []byte("old"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := &RotateWriter{Filename: filepath.Join(dir, "app.log"), MaxSizeBytes: 1, MaxAge: time.Hour}
+	defer w.Close()
+	for _, s := range []string{"1", "2"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(old); !os.IsNotExist(err) {
+		t.Errorf("backup older than MaxAge not removed: %v", err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("unrelated file removed: %v", err)
+	}
+	if backups := rotateBackups(t, dir, "app.log"); len(backups) != 2 {
+		t.Errorf("invalid backups: %v", backups)
+	}
+}
+
+func TestRotateWriterReopen(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Filename: filepath.Join(dir, "app.log")}
+	defer w.Close()
+	if _, err := w.Write([]byte("one\n")); err != nil {
+		t.Fatal(err)
+	}
+	// Moved away like logrotate does.
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(w.Filename, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("two\n")); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(moved); string(b) != "one\n" {
+		t.Errorf("invalid moved file: %q", b)
+	}
+	if b, _ := os.ReadFile(w.Filename); string(b) != "two\n" {
+		t.Errorf("invalid file: %q", b)
+	}
+}
+
+func TestRotateWriterConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	w := &RotateWriter{Filename: filepath.Join(dir, "app.log"), MaxSizeBytes: 100}
+	log := New(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				log.Info().Int("j", j).Msg("concurrent")
+			}
+		}()
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var events int
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(b) > 100 {
+			t.Errorf("%s is above MaxSizeBytes: %d bytes", e.Name(), len(b))
+		}
+		events += strings.Count(decodeIfBinaryToString(b), "concurrent")
+	}
+	if events != 200 {
+		t.Errorf("got %d events, want 200", events)
+	}
+}
@@ -0,0 +1,184 @@
+package zerolog
+
+import (
+	"container/list"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+// RoutingMaxWriters is the default maximum number of writers a
+// RoutingLevelWriter keeps open.
+const RoutingMaxWriters = 64
+
+// RoutingLevelWriter is a LevelWriter writing each event to the writer of
+// its route, like the file of the tenant it belongs to. The writers are
+// created on demand and cached, the least recently used one being closed
+// when too many are open. It is safe for concurrent use.
+type RoutingLevelWriter struct {
+	route      func(level Level, line []byte) string
+	factory    func(key string) (io.Writer, error)
+	fallback   io.Writer
+	maxWriters int
+
+	mu      sync.Mutex
+	writers map[string]*list.Element // of *routingEntry, in lru
+	lru     list.List                // most recently used first
+}
+
+// routingEntry is a writer cached by a RoutingLevelWriter.
+type routingEntry struct {
+	key string
+	w   io.Writer
+}
+
+// RoutingWriter creates a RoutingLevelWriter writing each event to the
+// writer created by factory for the key returned by route, usually
+// RouteByField:
+//
+//	w := zerolog.RoutingWriter(zerolog.RouteByField("tenant"), func(tenant string) (io.Writer, error) {
+//	    return &zerolog.RotateWriter{Filename: filepath.Join("logs", filepath.Base(tenant)+".log")}, nil
+//	})
+//	defer w.Close()
+//
+// The events whose key is empty, or for which factory fails, are written
+// to the fallback writer, os.Stderr by default. The errors of factory are
+// reported to ErrorHandler and factory is called again for the next event
+// of the key. At most RoutingMaxWriters writers are kept open, the least
+// recently used ones being closed if they implement io.Closer. The WriteLevel
+// method of the writers is used if they implement LevelWriter.
+//
+// The events are written while holding a lock, so a slow writer delays the
+// events of the other routes.
+func RoutingWriter(route func(level Level, line []byte) string, factory func(key string) (io.Writer, error), options ...func(w *RoutingLevelWriter)) *RoutingLevelWriter {
+	w := &RoutingLevelWriter{
+		route:      route,
+		factory:    factory,
+		fallback:   os.Stderr,
+		maxWriters: RoutingMaxWriters,
+		writers:    map[string]*list.Element{},
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// RoutingFallback is a RoutingWriter option setting the writer of the
+// events without route.
+func RoutingFallback(fallback io.Writer) func(w *RoutingLevelWriter) {
+	return func(w *RoutingLevelWriter) {
+		w.fallback = fallback
+	}
+}
+
+// RoutingMaxOpen is a RoutingWriter option setting the maximum number of
+// writers kept open. They are not limited if n is 0 or less.
+func RoutingMaxOpen(n int) func(w *RoutingLevelWriter) {
+	return func(w *RoutingLevelWriter) {
+		w.maxWriters = n
+	}
+}
+
+// RouteByField returns a route for RoutingWriter using the value of the
+// top level field key of the events, in JSON or in binary format. The
+// strings are unquoted and the other values are used as encoded in JSON.
+// The events without the field have an empty route.
+func RouteByField(key string) func(level Level, line []byte) string {
+	return func(level Level, line []byte) (route string) {
+		var start, end int
+		var ok bool
+		binary := len(line) > 0 && line[0] > 0x7f
+		if binary {
+			start, end, ok = cbor.MapValue(line, key)
+		} else {
+			start, end, ok = jsonFieldValue(line, key)
+		}
+		if !ok {
+			return ""
+		}
+		v := line[start:end]
+		if binary {
+			// The decoder panics on invalid data.
+			defer func() {
+				if recover() != nil {
+					route = ""
+				}
+			}()
+			v = []byte(cbor.DecodeItemToStr(v))
+		}
+		if len(v) > 0 && v[0] == '"' {
+			if s, err := strconv.Unquote(string(v)); err == nil {
+				return s
+			}
+		}
+		return string(v)
+	}
+}
+
+// Write implements the io.Writer interface.
+func (w *RoutingLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *RoutingLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	key := w.route(l, p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	out := w.fallback
+	if key != "" {
+		if rw, ok := w.writer(key); ok {
+			out = rw
+		}
+	}
+	if lw, ok := out.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return out.Write(p)
+}
+
+// writer returns the writer of key, creating it if needed, or false if
+// factory fails.
+func (w *RoutingLevelWriter) writer(key string) (io.Writer, bool) {
+	if e, ok := w.writers[key]; ok {
+		w.lru.MoveToFront(e)
+		return e.Value.(*routingEntry).w, true
+	}
+	rw, err := w.factory(key)
+	if err != nil {
+		handleWriteError(err)
+		return nil, false
+	}
+	w.writers[key] = w.lru.PushFront(&routingEntry{key, rw})
+	for w.maxWriters > 0 && w.lru.Len() > w.maxWriters {
+		if err := w.evict(w.lru.Back()); err != nil {
+			handleWriteError(err)
+		}
+	}
+	return rw, true
+}
+
+// evict closes the writer of e, see closeOutput, and stops caching it.
+func (w *RoutingLevelWriter) evict(e *list.Element) error {
+	entry := w.lru.Remove(e).(*routingEntry)
+	delete(w.writers, entry.key)
+	return closeOutput(entry.w)
+}
+
+// Close closes the writers of the routes, see closeOutput, and returns the
+// first error. The next events create them again. The fallback
+// writer is not closed.
+func (w *RoutingLevelWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.lru.Len() > 0 {
+		if cerr := w.evict(w.lru.Front()); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
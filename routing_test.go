@@ -0,0 +1,123 @@
+package zerolog
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// closeBuffer is a bytes.Buffer recording whether it was closed.
+type closeBuffer struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (b *closeBuffer) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestRoutingWriter(t *testing.T) {
+	bufs := map[string]*closeBuffer{}
+	fallback := &bytes.Buffer{}
+	w := RoutingWriter(RouteByField("tenant"), func(key string) (io.Writer, error) {
+		b := &closeBuffer{}
+		bufs[key] = b
+		return b, nil
+	}, RoutingFallback(fallback))
+	log := New(w)
+	for _, tenant := range []string{"acme", "globex", "acme", "initech"} {
+		log.Info().Str("tenant", tenant).Msg("hello")
+	}
+	log.Info().Msg("no tenant")
+
+	for key, want := range map[string]string{
+		"acme":    `{"level":"info","tenant":"acme","message":"hello"}` + "\n" + `{"level":"info","tenant":"acme","message":"hello"}` + "\n",
+		"globex":  `{"level":"info","tenant":"globex","message":"hello"}` + "\n",
+		"initech": `{"level":"info","tenant":"initech","message":"hello"}` + "\n",
+	} {
+		if got := decodeIfBinaryToString(bufs[key].Bytes()); got != want {
+			t.Errorf("invalid %s output:\ngot:  %v\nwant: %v", key, got, want)
+		}
+	}
+	if got, want := decodeIfBinaryToString(fallback.Bytes()), `{"level":"info","message":"no tenant"}`+"\n"; got != want {
+		t.Errorf("invalid fallback output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for key, b := range bufs {
+		if !b.closed {
+			t.Errorf("%s not closed", key)
+		}
+	}
+}
+
+func TestRoutingWriterEviction(t *testing.T) {
+	var created []string
+	bufs := map[string]*closeBuffer{}
+	w := RoutingWriter(RouteByField("tenant"), func(key string) (io.Writer, error) {
+		created = append(created, key)
+		b := &closeBuffer{}
+		bufs[key] = b
+		return b, nil
+	}, RoutingMaxOpen(2))
+	log := New(w)
+	log.Info().Str("tenant", "acme").Send()
+	log.Info().Str("tenant", "globex").Send()
+	log.Info().Str("tenant", "acme").Send()
+	log.Info().Str("tenant", "initech").Send() // evicts globex, the coldest
+
+	if !bufs["globex"].closed || bufs["acme"].closed || bufs["initech"].closed {
+		t.Errorf("invalid eviction: acme %v, globex %v, initech %v", bufs["acme"].closed, bufs["globex"].closed, bufs["initech"].closed)
+	}
+	log.Info().Str("tenant", "globex").Send() // evicts acme
+	if want := []string{"acme", "globex", "initech", "globex"}; !reflect.DeepEqual(created, want) {
+		t.Errorf("invalid writers created: got %v, want %v", created, want)
+	}
+	if !bufs["acme"].closed {
+		t.Error("acme not closed")
+	}
+}
+
+func TestRoutingWriterFactoryError(t *testing.T) {
+	var errs []error
+	ErrorHandler = func(err error) { errs = append(errs, err) }
+	defer func() { ErrorHandler = nil }()
+	fallback := &bytes.Buffer{}
+	boom := errors.New("boom")
+	w := RoutingWriter(RouteByField("tenant"), func(key string) (io.Writer, error) {
+		return nil, boom
+	}, RoutingFallback(fallback))
+	New(w).Info().Str("tenant", "acme").Send()
+
+	if got, want := decodeIfBinaryToString(fallback.Bytes()), `{"level":"info","tenant":"acme"}`+"\n"; got != want {
+		t.Errorf("invalid fallback output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if len(errs) != 1 || errs[0] != boom {
+		t.Errorf("invalid errors: %v", errs)
+	}
+}
+
+func TestRouteByField(t *testing.T) {
+	route := RouteByField("tenant")
+	for _, tt := range []struct {
+		line string
+		want string
+	}{
+		{`{"tenant":"acme"}`, "acme"},
+		{`{"level":"info", "tenant": "a\"b"}`, `a"b`},
+		{`{"tenant":42}`, "42"},
+		{`{"level":"info"}`, ""},
+		{`not an event`, ""},
+		{"\xbf\x66tenant\x64acme\xff", "acme"},
+		{"\xbf\x66tenant\x18\x2a\xff", "42"},
+	} {
+		if got := route(InfoLevel, []byte(tt.line)); got != tt.want {
+			t.Errorf("RouteByField(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
@@ -137,3 +137,56 @@ func (s LevelSampler) Sample(lvl Level) bool {
 	}
 	return true
 }
+
+// AndSampler returns a sampler passing the events passed by all of
+// samplers, asked in order until one rejects the event, so the stateful
+// samplers following it, like BurstSampler, don't count it. The nil
+// samplers are ignored, and the events are all passed if there is no
+// sampler.
+func AndSampler(samplers ...Sampler) Sampler {
+	return andSampler(samplers)
+}
+
+type andSampler []Sampler
+
+// Sample implements the Sampler interface.
+func (s andSampler) Sample(lvl Level) bool {
+	for _, sampler := range s {
+		if sampler != nil && !sampler.Sample(lvl) {
+			return false
+		}
+	}
+	return true
+}
+
+// OrSampler returns a sampler passing the events passed by any of
+// samplers, asked in order until one passes the event. For instance, to
+// keep all the errors, and one debug event out of 10 up to 100 events per
+// second:
+//
+//	never := zerolog.RandomSampler(0)
+//	sampler := zerolog.OrSampler(
+//	    zerolog.LevelSampler{TraceSampler: never, DebugSampler: never, InfoSampler: never, WarnSampler: never},
+//	    zerolog.AndSampler(
+//	        zerolog.LevelSampler{DebugSampler: &zerolog.BasicSampler{N: 10}},
+//	        &zerolog.BurstSampler{Burst: 100, Period: time.Second},
+//	    ),
+//	)
+//
+// The nil samplers are ignored, and the events are all rejected if there is
+// no sampler.
+func OrSampler(samplers ...Sampler) Sampler {
+	return orSampler(samplers)
+}
+
+type orSampler []Sampler
+
+// Sample implements the Sampler interface.
+func (s orSampler) Sample(lvl Level) bool {
+	for _, sampler := range s {
+		if sampler != nil && sampler.Sample(lvl) {
+			return true
+		}
+	}
+	return false
+}
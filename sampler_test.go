@@ -82,3 +82,77 @@ func BenchmarkSamplers(b *testing.B) {
 		})
 	}
 }
+
+// mockSampler returns its decision, counting its calls.
+type mockSampler struct {
+	pass  bool
+	calls int
+}
+
+func (s *mockSampler) Sample(lvl Level) bool {
+	s.calls++
+	return s.pass
+}
+
+func TestComposedSamplers(t *testing.T) {
+	tests := []struct {
+		name    string
+		compose func(...Sampler) Sampler
+		passes  []bool
+		want    bool
+		calls   []int
+	}{
+		{"and none", AndSampler, nil, true, nil},
+		{"and all pass", AndSampler, []bool{true, true, true}, true, []int{1, 1, 1}},
+		{"and one rejects", AndSampler, []bool{true, false, true}, false, []int{1, 1, 0}},
+		{"or none", OrSampler, nil, false, nil},
+		{"or all reject", OrSampler, []bool{false, false, false}, false, []int{1, 1, 1}},
+		{"or one passes", OrSampler, []bool{false, true, false}, true, []int{1, 1, 0}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mocks := make([]*mockSampler, len(tt.passes))
+			samplers := []Sampler{nil}
+			for i, pass := range tt.passes {
+				mocks[i] = &mockSampler{pass: pass}
+				samplers = append(samplers, mocks[i])
+			}
+			if got := tt.compose(samplers...).Sample(InfoLevel); got != tt.want {
+				t.Errorf("Sample() = %v, want %v", got, tt.want)
+			}
+			for i, m := range mocks {
+				if m.calls != tt.calls[i] {
+					t.Errorf("sampler %d called %d times, want %d", i, m.calls, tt.calls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestComposedSamplersLevels(t *testing.T) {
+	never := RandomSampler(0)
+	sampler := OrSampler(
+		LevelSampler{TraceSampler: never, DebugSampler: never, InfoSampler: never, WarnSampler: never},
+		AndSampler(
+			LevelSampler{DebugSampler: &BasicSampler{N: 10}},
+			&BurstSampler{Burst: 5, Period: time.Hour},
+		),
+	)
+	count := func(lvl Level, n int) (got int) {
+		for i := 0; i < n; i++ {
+			if sampler.Sample(lvl) {
+				got++
+			}
+		}
+		return got
+	}
+	if got := count(DebugLevel, 30); got != 3 {
+		t.Errorf("passed %d debug events out of 30, want 3", got)
+	}
+	if got := count(InfoLevel, 30); got != 2 {
+		t.Errorf("passed %d info events out of 30, want 2", got)
+	}
+	if got := count(ErrorLevel, 30); got != 30 {
+		t.Errorf("passed %d error events out of 30, want 30", got)
+	}
+}
@@ -0,0 +1,116 @@
+//go:build go1.23
+
+package zerolog
+
+import "iter"
+
+// StrSeq adds the field key with the strings yielded by seq to the *Event
+// context, as an array. The strings are appended as they are yielded, without
+// building an intermediate slice.
+func (e *Event) StrSeq(key string, seq iter.Seq[string]) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	first := true
+	for v := range seq {
+		if !first {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		first = false
+		e.buf = enc.AppendString(e.buf, v)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
+
+// IntSeq adds the field key with the ints yielded by seq to the *Event
+// context, as an array.
+func (e *Event) IntSeq(key string, seq iter.Seq[int]) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	first := true
+	for v := range seq {
+		if !first {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		first = false
+		e.buf = enc.AppendInt(e.buf, v)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
+
+// Int64Seq adds the field key with the int64s yielded by seq to the *Event
+// context, as an array.
+func (e *Event) Int64Seq(key string, seq iter.Seq[int64]) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	first := true
+	for v := range seq {
+		if !first {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		first = false
+		e.buf = enc.AppendInt64(e.buf, v)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
+
+// Uint64Seq adds the field key with the uint64s yielded by seq to the *Event
+// context, as an array.
+func (e *Event) Uint64Seq(key string, seq iter.Seq[uint64]) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	first := true
+	for v := range seq {
+		if !first {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		first = false
+		e.buf = enc.AppendUint64(e.buf, v)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
+
+// Float64Seq adds the field key with the float64s yielded by seq to the
+// *Event context, as an array.
+func (e *Event) Float64Seq(key string, seq iter.Seq[float64]) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		return e
+	}
+	e.buf = enc.AppendArrayStart(enc.AppendKey(e.buf, key))
+	first := true
+	for v := range seq {
+		if !first {
+			e.buf = enc.AppendArrayDelim(e.buf)
+		}
+		first = false
+		e.buf = enc.AppendFloat64(e.buf, v)
+	}
+	e.buf = enc.AppendArrayEnd(e.buf)
+	return e
+}
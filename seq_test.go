@@ -0,0 +1,52 @@
+//go:build go1.23
+
+package zerolog
+
+import (
+	"bytes"
+	"iter"
+	"slices"
+	"testing"
+)
+
+func TestSeq(t *testing.T) {
+	three := func(yield func(int) bool) {
+		for i := 1; i <= 3; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	strs := func(yield func(string) bool) {
+		for _, s := range []string{"a", "b c", `"d"`} {
+			if !yield(s) {
+				return
+			}
+		}
+	}
+	var empty iter.Seq[string] = func(yield func(string) bool) {}
+
+	out := &bytes.Buffer{}
+	log := New(out)
+	log.Log().
+		StrSeq("strs", strs).
+		IntSeq("ints", three).
+		Int64Seq("ints64", func(yield func(int64) bool) { yield(-1) }).
+		Uint64Seq("uints64", slices.Values([]uint64{1, 2, 3})).
+		Float64Seq("floats64", slices.Values([]float64{1.5, 2})).
+		StrSeq("empty", empty).
+		Msg("")
+	want := &bytes.Buffer{}
+	New(want).Log().
+		Strs("strs", []string{"a", "b c", `"d"`}).
+		Ints("ints", []int{1, 2, 3}).
+		Ints64("ints64", []int64{-1}).
+		Uints64("uints64", []uint64{1, 2, 3}).
+		Floats64("floats64", []float64{1.5, 2}).
+		Strs("empty", []string{}).
+		Msg("")
+
+	if got, want := decodeIfBinaryToString(out.Bytes()), decodeIfBinaryToString(want.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,149 @@
+package zerolog
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// SharedLogger holds a Logger shared by several goroutines, which can be
+// replaced or reconfigured while the others log with it. The methods of
+// Logger modify it in place, like Level, so reconfiguring a *Logger used
+// concurrently is a data race; a SharedLogger replaces the whole Logger
+// atomically instead:
+//
+//	var logger = zerolog.NewSharedLogger(zerolog.New(os.Stderr))
+//
+//	logger.Info().Msg("hello")
+//	logger.Update(func(l *zerolog.Logger) { l.Level(zerolog.WarnLevel) })
+//
+// Its methods starting an event use the current Logger at the time of the
+// call. Its zero value holds a disabled Logger.
+type SharedLogger struct {
+	p atomic.Pointer[Logger]
+}
+
+// NewSharedLogger creates a SharedLogger holding l, which must not be
+// modified afterward.
+func NewSharedLogger(l *Logger) *SharedLogger {
+	s := &SharedLogger{}
+	s.Store(l)
+	return s
+}
+
+// Load returns the current Logger of s. It must not be modified, use Update
+// instead.
+func (s *SharedLogger) Load() *Logger {
+	if l := s.p.Load(); l != nil {
+		return l
+	}
+	return disabledLogger
+}
+
+// Store replaces the Logger of s with l, which must not be modified
+// afterward.
+func (s *SharedLogger) Store(l *Logger) {
+	s.p.Store(l)
+}
+
+// Update replaces the Logger of s with a copy of it modified by fn. fn may
+// be called more than once if s is updated concurrently.
+func (s *SharedLogger) Update(fn func(l *Logger)) {
+	for {
+		old := s.p.Load()
+		l := s.Load().clone()
+		fn(l)
+		if s.p.CompareAndSwap(old, l) {
+			return
+		}
+	}
+}
+
+// clone returns a copy of l which can be modified without affecting l.
+func (l *Logger) clone() *Logger {
+	return l.Output(l.w)
+}
+
+// Output returns a copy of the current Logger with w as output.
+func (s *SharedLogger) Output(w io.Writer) *Logger {
+	return s.Load().Output(w)
+}
+
+// With creates a child logger of the current Logger with fields added to
+// its context.
+func (s *SharedLogger) With() Context {
+	return s.Load().clone().With()
+}
+
+// Level returns a copy of the current Logger with the minimum accepted
+// level set to lvl.
+func (s *SharedLogger) Level(lvl Level) *Logger {
+	return s.Load().clone().Level(lvl)
+}
+
+// Sample returns a copy of the current Logger with the sampler sp.
+func (s *SharedLogger) Sample(sp Sampler) *Logger {
+	return s.Load().clone().Sample(sp)
+}
+
+// Hook returns a copy of the current Logger with the h Hook.
+func (s *SharedLogger) Hook(h Hook) *Logger {
+	return s.Load().clone().Hook(h)
+}
+
+// GetLevel returns the minimum accepted level of the current Logger.
+func (s *SharedLogger) GetLevel() Level {
+	return s.Load().GetLevel()
+}
+
+// Trace starts a new message with trace level with the current Logger.
+func (s *SharedLogger) Trace() *Event {
+	return s.Load().Trace()
+}
+
+// Debug starts a new message with debug level with the current Logger.
+func (s *SharedLogger) Debug() *Event {
+	return s.Load().Debug()
+}
+
+// Info starts a new message with info level with the current Logger.
+func (s *SharedLogger) Info() *Event {
+	return s.Load().Info()
+}
+
+// Warn starts a new message with warn level with the current Logger.
+func (s *SharedLogger) Warn() *Event {
+	return s.Load().Warn()
+}
+
+// Error starts a new message with error level with the current Logger.
+func (s *SharedLogger) Error() *Event {
+	return s.Load().Error()
+}
+
+// Err starts a new message with error level with err as a field if not nil,
+// or with info level if err is nil, with the current Logger.
+func (s *SharedLogger) Err(err error) *Event {
+	return s.Load().Err(err)
+}
+
+// Fatal starts a new message with fatal level with the current Logger. The
+// os.Exit(1) function is called by the Msg method.
+func (s *SharedLogger) Fatal() *Event {
+	return s.Load().Fatal()
+}
+
+// Panic starts a new message with panic level with the current Logger. The
+// message is also sent to the panic function.
+func (s *SharedLogger) Panic() *Event {
+	return s.Load().Panic()
+}
+
+// WithLevel starts a new message with level with the current Logger.
+func (s *SharedLogger) WithLevel(level Level) *Event {
+	return s.Load().WithLevel(level)
+}
+
+// Log starts a new message with no level with the current Logger.
+func (s *SharedLogger) Log() *Event {
+	return s.Load().Log()
+}
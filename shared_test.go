@@ -0,0 +1,74 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+func TestSharedLogger(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := NewSharedLogger(New(out))
+	s.Debug().Msg("one")
+	s.Update(func(l *Logger) { l.Level(InfoLevel) })
+	s.Debug().Msg("dropped")
+	s.Info().Msg("two")
+
+	// The child loggers don't affect s.
+	s.Level(DebugLevel).Debug().Msg("three")
+	s.With().Str("foo", "bar").Logger().Info().Msg("four")
+	s.Info().Msg("five")
+
+	s.Store(New(out).With().Str("new", "logger").Logger())
+	s.Debug().Msg("six")
+	want := `{"level":"debug","message":"one"}` + "\n" +
+		`{"level":"info","message":"two"}` + "\n" +
+		`{"level":"debug","message":"three"}` + "\n" +
+		`{"level":"info","foo":"bar","message":"four"}` + "\n" +
+		`{"level":"info","message":"five"}` + "\n" +
+		`{"level":"debug","new":"logger","message":"six"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestSharedLoggerZero(t *testing.T) {
+	var s SharedLogger
+	if e := s.Info(); e != nil {
+		t.Error("zero SharedLogger should be disabled")
+	}
+	s.Update(func(l *Logger) { l.Level(InfoLevel) })
+	if got := s.GetLevel(); got != InfoLevel {
+		t.Errorf("GetLevel() = %v, want %v", got, InfoLevel)
+	}
+}
+
+func TestSharedLoggerConcurrent(t *testing.T) {
+	s := NewSharedLogger(New(io.Discard))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				s.Info().Int("j", j).Msg("concurrent")
+				s.With().Int("j", j).Logger().Debug().Msg("child")
+			}
+		}()
+	}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				if i == 0 {
+					s.Update(func(l *Logger) { l.Level(Level(j % 3)).Hook(HookFunc(func(*Event, Level, string) {})) })
+				} else {
+					s.Store(New(io.Discard).With().Int("j", j).Logger())
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
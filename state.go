@@ -0,0 +1,170 @@
+package zerolog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// loggerStateVersion is the version of the format of Logger.MarshalState,
+// increased when the states can no longer be read by older versions. New
+// records can be added without increasing it, as unknown records are
+// skipped.
+const loggerStateVersion = 1
+
+// The records of the states written by Logger.MarshalState, each being its
+// tag, the uvarint length of its value and its value.
+const (
+	stateLevel      = 1  // int8
+	stateStack      = 2  // no value
+	statePrintLevel = 3  // int8
+	stateTimeFormat = 4  // string
+	stateAfterClose = 5  // uint8
+	statePrincipal  = 6  // string
+	statePprofLabel = 7  // string, one record per label
+	stateContext    = 8  // context fields, in the encoding of the state
+	stateFieldName  = 9  // uvarint index in stateFieldNames, then string
+	stateWriteLevel = 10 // int8
+)
+
+// stateFieldNames are the field name globals saved by Logger.MarshalState.
+// New ones must be appended, as their index is saved.
+var stateFieldNames = []*string{
+	&TimestampFieldName,
+	&LevelFieldName,
+	&MessageFieldName,
+	&ErrorFieldName,
+	&CallerFieldName,
+	&ErrorStackFieldName,
+}
+
+// MarshalState returns the state of l, to create an equivalent logger with
+// RestoreLogger in another process, like a worker subprocess inheriting the
+// fields of its parent:
+//
+//	state, err := log.MarshalState()
+//	...
+//	cmd.Env = append(os.Environ(), "LOG_STATE="+base64.StdEncoding.EncodeToString(state))
+//
+// The state holds the context fields, the current level, the print and
+// write levels, the time field format, the AfterClose policy, the principal
+// and pprof label fields, the main field name globals (TimestampFieldName,
+// LevelFieldName, MessageFieldName, ErrorFieldName, CallerFieldName and
+// ErrorStackFieldName) and the encoding of the events, JSON or binary.
+//
+// The writer, the hooks, the sampler and the timestamp function, which are
+// code, are not saved, and neither is the LevelVar of l, whose current level
+// is saved instead.
+func (l *Logger) MarshalState() ([]byte, error) {
+	state := []byte{loggerStateVersion, encoderKind()}
+	state = appendStateRecord(state, stateLevel, []byte{byte(l.GetLevel())})
+	if l.stack {
+		state = appendStateRecord(state, stateStack, nil)
+	}
+	if l.printLevel != nil {
+		state = appendStateRecord(state, statePrintLevel, []byte{byte(*l.printLevel)})
+	}
+	if l.writeLevel != nil {
+		state = appendStateRecord(state, stateWriteLevel, []byte{byte(*l.writeLevel)})
+	}
+	state = appendStateRecord(state, stateTimeFormat, []byte(l.timeFormat()))
+	state = appendStateRecord(state, stateAfterClose, []byte{byte(l.afterClose)})
+	if l.principal != "" {
+		state = appendStateRecord(state, statePrincipal, []byte(l.principal))
+	}
+	for _, key := range l.pprofLabels {
+		state = appendStateRecord(state, statePprofLabel, []byte(key))
+	}
+	if len(l.context) > 0 {
+		state = appendStateRecord(state, stateContext, l.context)
+	}
+	for i, name := range stateFieldNames {
+		v := binary.AppendUvarint(nil, uint64(i))
+		state = appendStateRecord(state, stateFieldName, append(v, *name...))
+	}
+	return state, nil
+}
+
+// RestoreLogger creates a logger writing to w from a state returned by
+// Logger.MarshalState, possibly in another process. The field name globals
+// are set to the ones saved in the state. The state must have been saved by
+// a program encoding the events like this one, in JSON or in binary.
+//
+// The hooks, the sampler and the timestamp function of the saved logger are
+// not restored and must be set again if needed.
+func RestoreLogger(state []byte, w io.Writer) (*Logger, error) {
+	if len(state) < 2 {
+		return nil, errors.New("invalid logger state: too short")
+	}
+	if state[0] != loggerStateVersion {
+		return nil, fmt.Errorf("unsupported logger state version %d", state[0])
+	}
+	if state[1] != encoderKind() {
+		return nil, errors.New("invalid logger state: encoded for another event format")
+	}
+	l := New(w)
+	fieldNames := make(map[*string]string)
+	for p := state[2:]; len(p) > 0; {
+		tag := p[0]
+		n, k := binary.Uvarint(p[1:])
+		if k <= 0 || n > uint64(len(p)-1-k) {
+			return nil, errors.New("invalid logger state: truncated record")
+		}
+		v := p[1+k : 1+k+int(n)]
+		p = p[1+k+int(n):]
+		switch tag {
+		case stateLevel, statePrintLevel, stateWriteLevel, stateAfterClose:
+			if len(v) != 1 {
+				return nil, fmt.Errorf("invalid logger state: record %d has %d bytes, want 1", tag, len(v))
+			}
+			switch tag {
+			case stateLevel:
+				l.level = Level(int8(v[0]))
+			case statePrintLevel:
+				lvl := Level(int8(v[0]))
+				l.printLevel = &lvl
+			case stateWriteLevel:
+				lvl := Level(int8(v[0]))
+				l.writeLevel = &lvl
+			default:
+				l.afterClose = AfterClosePolicy(v[0])
+			}
+		case stateStack:
+			l.stack = true
+		case stateTimeFormat:
+			format := string(v)
+			l.timeFieldFormat = &format
+		case statePrincipal:
+			l.principal = string(v)
+		case statePprofLabel:
+			l.pprofLabels = append(l.pprofLabels, string(v))
+		case stateContext:
+			l.context = append([]byte(nil), v...)
+		case stateFieldName:
+			i, k := binary.Uvarint(v)
+			if k <= 0 {
+				return nil, errors.New("invalid logger state: truncated field name")
+			}
+			// Field names unknown to this version are ignored.
+			if i < uint64(len(stateFieldNames)) {
+				fieldNames[stateFieldNames[i]] = string(v[k:])
+			}
+		}
+	}
+	for name, v := range fieldNames {
+		*name = v
+	}
+	return l, nil
+}
+
+func appendStateRecord(dst []byte, tag byte, v []byte) []byte {
+	dst = binary.AppendUvarint(append(dst, tag), uint64(len(v)))
+	return append(dst, v...)
+}
+
+// encoderKind returns the first byte of the objects in the event format,
+// identifying it.
+func encoderKind() byte {
+	return enc.AppendBeginMarker(nil)[0]
+}
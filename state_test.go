@@ -0,0 +1,80 @@
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLoggerStateRoundTrip(t *testing.T) {
+	defer func() { MessageFieldName = "message" }()
+	MessageFieldName = "msg"
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("service", "api").Int("pid", 42).TimestampFieldFormat(TimeFormatUnixMs).Logger().
+		Level(InfoLevel).RequirePrincipal("user").WithWriteLevel(WarnLevel)
+	state, err := log.MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	MessageFieldName = "message"
+
+	out2 := &bytes.Buffer{}
+	log2, err := RestoreLogger(state, out2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if MessageFieldName != "msg" {
+		t.Errorf("MessageFieldName = %q, want %q", MessageFieldName, "msg")
+	}
+	if got, want := log2.GetLevel(), InfoLevel; got != want {
+		t.Errorf("GetLevel() = %v, want %v", got, want)
+	}
+	for _, l := range []*Logger{log, log2} {
+		l.Debug().Msg("filtered")
+		l.Info().Msg("hello")
+	}
+	want := `{"level":"info","service":"api","pid":42,"msg":"hello"}` + "\n"
+	if got := decodeIfBinaryToString(out.Bytes()); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if got := decodeIfBinaryToString(out2.Bytes()); got != want {
+		t.Errorf("invalid restored log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	if log2.principal != "user" || log2.timeFormat() != TimeFormatUnixMs {
+		t.Errorf("restored principal %q and time format %q", log2.principal, log2.timeFormat())
+	}
+	if log2.writeLevel == nil || *log2.writeLevel != WarnLevel {
+		t.Errorf("restored write level %v, want %v", log2.writeLevel, WarnLevel)
+	}
+}
+
+func TestRestoreLoggerErrors(t *testing.T) {
+	state, err := New(nil).With().Str("foo", "bar").Logger().MarshalState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKind := '{'
+	if encoderKind() == '{' {
+		otherKind = 0xbf
+	}
+	for _, tt := range []struct {
+		name  string
+		state []byte
+	}{
+		{"empty", nil},
+		{"version", append([]byte{loggerStateVersion + 1}, state[1:]...)},
+		{"encoder", append([]byte{state[0], byte(otherKind)}, state[2:]...)},
+		{"truncated", state[:len(state)-1]},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RestoreLogger(tt.state, nil); err == nil {
+				t.Error("RestoreLogger() did not fail")
+			}
+		})
+	}
+
+	// Unknown records are skipped.
+	state = appendStateRecord(state, 200, []byte("future"))
+	if _, err := RestoreLogger(state, nil); err != nil {
+		t.Errorf("RestoreLogger() with an unknown record: %v", err)
+	}
+}
@@ -4,12 +4,18 @@ package zerolog
 
 import (
 	"io"
+	"sort"
+
+	"github.com/goccy/go-json"
 )
 
 // See http://cee.mitre.org/language/1.0-beta1/clt.html#syslog
 // or https://www.rsyslog.com/json-elasticsearch/
 const ceePrefix = "@cee:"
 
+// syslogSDID is the SD-ID of the RFC 5424 structured data element.
+const syslogSDID = "zerolog"
+
 // SyslogWriter is an interface matching a syslog.Writer struct.
 type SyslogWriter interface {
 	io.Writer
@@ -22,14 +28,15 @@ type SyslogWriter interface {
 }
 
 type syslogWriter struct {
-	w      SyslogWriter
-	prefix string
+	w          SyslogWriter
+	prefix     string
+	structured bool
 }
 
 // SyslogLevelWriter wraps a SyslogWriter and call the right syslog level
 // method matching the zerolog level.
 func SyslogLevelWriter(w SyslogWriter) LevelWriter {
-	return syslogWriter{w, ""}
+	return syslogWriter{w: w}
 }
 
 // SyslogCEEWriter wraps a SyslogWriter with a SyslogLevelWriter that adds a
@@ -37,10 +44,28 @@ func SyslogLevelWriter(w SyslogWriter) LevelWriter {
 // and syslog-ng JSON logging support.
 // See https://www.rsyslog.com/json-elasticsearch/
 func SyslogCEEWriter(w SyslogWriter) LevelWriter {
-	return syslogWriter{w, ceePrefix}
+	return syslogWriter{w: w, prefix: ceePrefix}
+}
+
+// SyslogSDWriter wraps a SyslogWriter with a SyslogLevelWriter that maps the
+// top-level fields of each event into an RFC 5424 STRUCTURED-DATA element
+// followed by the message, for instance:
+//
+//	[zerolog foo="bar" level="info"] hello world
+//
+// Fields are sorted by name and nested values are kept as JSON. Lines that
+// cannot be decoded are passed through unchanged.
+func SyslogSDWriter(w SyslogWriter) LevelWriter {
+	return syslogWriter{w: w, structured: true}
 }
 
 func (sw syslogWriter) Write(p []byte) (n int, err error) {
+	if sw.structured {
+		if sd, err := appendSyslogSD(nil, p); err == nil {
+			_, err = sw.w.Write(sd)
+			return len(p), err
+		}
+	}
 	var pn int
 	if sw.prefix != "" {
 		pn, err = sw.w.Write([]byte(sw.prefix))
@@ -52,24 +77,43 @@ func (sw syslogWriter) Write(p []byte) (n int, err error) {
 	return pn + n, err
 }
 
+// Close closes the SyslogWriter if it implements io.Closer, like
+// syslog.Writer.
+func (sw syslogWriter) Close() error {
+	if c, ok := sw.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// msg returns p formatted as a syslog message.
+func (sw syslogWriter) msg(p []byte) string {
+	if sw.structured {
+		if sd, err := appendSyslogSD(nil, p); err == nil {
+			return string(sd)
+		}
+	}
+	return sw.prefix + string(p)
+}
+
 // WriteLevel implements LevelWriter interface.
 func (sw syslogWriter) WriteLevel(level Level, p []byte) (n int, err error) {
 	switch level {
 	case TraceLevel:
 	case DebugLevel:
-		err = sw.w.Debug(sw.prefix + string(p))
+		err = sw.w.Debug(sw.msg(p))
 	case InfoLevel:
-		err = sw.w.Info(sw.prefix + string(p))
+		err = sw.w.Info(sw.msg(p))
 	case WarnLevel:
-		err = sw.w.Warning(sw.prefix + string(p))
+		err = sw.w.Warning(sw.msg(p))
 	case ErrorLevel:
-		err = sw.w.Err(sw.prefix + string(p))
+		err = sw.w.Err(sw.msg(p))
 	case FatalLevel:
-		err = sw.w.Emerg(sw.prefix + string(p))
+		err = sw.w.Emerg(sw.msg(p))
 	case PanicLevel:
-		err = sw.w.Crit(sw.prefix + string(p))
+		err = sw.w.Crit(sw.msg(p))
 	case NoLevel:
-		err = sw.w.Info(sw.prefix + string(p))
+		err = sw.w.Info(sw.msg(p))
 	default:
 		panic("invalid level")
 	}
@@ -77,3 +121,77 @@ func (sw syslogWriter) WriteLevel(level Level, p []byte) (n int, err error) {
 	n = len(p)
 	return
 }
+
+// appendSyslogSD decodes the JSON event p and appends it to dst as an
+// RFC 5424 SD-ELEMENT followed by the message.
+func appendSyslogSD(dst, p []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(p, &fields); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		if key != MessageFieldName {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	dst = append(dst, '[')
+	dst = append(dst, syslogSDID...)
+	for _, key := range keys {
+		dst = append(dst, ' ')
+		dst = appendSyslogSDName(dst, key)
+		dst = append(dst, '=', '"')
+		dst = appendSyslogSDValue(dst, syslogSDString(fields[key]))
+		dst = append(dst, '"')
+	}
+	dst = append(dst, ']')
+	if msg, ok := fields[MessageFieldName]; ok {
+		dst = append(dst, ' ')
+		dst = append(dst, syslogSDString(msg)...)
+	}
+	return dst, nil
+}
+
+// syslogSDString returns the string content of raw if it is a JSON string,
+// or the JSON text otherwise.
+func syslogSDString(raw json.RawMessage) string {
+	if len(raw) > 0 && raw[0] == '"' {
+		var s string
+		if err := json.Unmarshal(raw, &s); err == nil {
+			return s
+		}
+	}
+	return string(raw)
+}
+
+// appendSyslogSDName appends name as a PARAM-NAME: at most 32 printable
+// US-ASCII characters except '=', ' ', ']' and '"', which are replaced by '_'.
+func appendSyslogSDName(dst []byte, name string) []byte {
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c <= ' ' || c > '~' || c == '=' || c == ']' || c == '"' {
+			c = '_'
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// appendSyslogSDValue appends val as a PARAM-VALUE, escaping '"', '\' and
+// ']' with a backslash as required by RFC 5424.
+func appendSyslogSDValue(dst []byte, val string) []byte {
+	for i := 0; i < len(val); i++ {
+		switch c := val[i]; c {
+		case '"', '\\', ']':
+			dst = append(dst, '\\', c)
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
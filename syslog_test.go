@@ -112,3 +112,27 @@ func TestSyslogWriter_WithCEE(t *testing.T) {
 		t.Errorf("Bad CEE message start: want %v, got %v", want, got)
 	}
 }
+
+func TestSyslogWriter_WithSD(t *testing.T) {
+	var buf bytes.Buffer
+	sw := testCEEwriter{&buf}
+	log := New(SyslogSDWriter(sw))
+	log.Info().Str("key", `va]l"u\e`).Int("n", 42).Strs("list", []string{"a"}).Msg("message string")
+	got := buf.String()
+	want := `[zerolog key="va\]l\"u\\e" level="info" list="[\"a\"\]" n="42"] message string`
+	if got != want {
+		t.Errorf("Bad SD message: want %v, got %v", want, got)
+	}
+}
+
+func TestSyslogWriter_WithSDInvalidJSON(t *testing.T) {
+	var buf bytes.Buffer
+	sw := testCEEwriter{&buf}
+	_, err := SyslogSDWriter(sw).WriteLevel(InfoLevel, []byte("not json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "not json"; got != want {
+		t.Errorf("Bad passthrough message: want %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,179 @@
+package zerolog
+
+// SlotWidth is the width of a slot large enough for any int64 in JSON.
+const SlotWidth = 20
+
+// Template is a precompiled event for hot paths always logging events with
+// the same fields, only some numbers changing. It is created by Precompile
+// and is safe for concurrent use.
+type Template struct {
+	l     *Logger
+	level Level
+	buf   []byte
+	slots []templateSlot
+	args  int // number of slots reserved, redacted ones included
+}
+
+type templateSlot struct {
+	off  int
+	size int
+	arg  int // index of the argument of Emit filling the slot
+}
+
+// templateWriter captures the event built by the build function of
+// Precompile.
+type templateWriter struct {
+	t *Template
+}
+
+func (w templateWriter) Write(p []byte) (n int, err error) {
+	w.t.buf = append(w.t.buf[:0], p...)
+	return len(p), nil
+}
+
+func (w templateWriter) WriteLevel(_ Level, p []byte) (n int, err error) {
+	return w.Write(p)
+}
+
+// Precompile creates a template of the events at level logged by l. The
+// build function adds the fields of the event, reserving the varying
+// numbers with Event.Slot, and must send it with Msg or Send. The event is
+// then serialized once and each call to Emit only fills the slots.
+//
+// The level, the sampler and the closing of l are checked on each Emit, but
+// hooks, including the timestamp and the caller added by Context.Timestamp
+// and Context.Caller, are not run: neither by Precompile nor by Emit. The
+// fields redacted by a RedactHook of l, slots included, are logged as
+// "[REDACTED]" in the template.
+// Fields added by build, like a timestamp, are logged with the value they
+// had when precompiled.
+func Precompile(l *Logger, level Level, build func(e *Event)) *Template {
+	t := &Template{l: l, level: level}
+	e := l.initEvent(templateWriter{t}, level)
+	e.ch = nil
+	e.tpl = t
+	build(e)
+	return t
+}
+
+// Slot adds the field key with a number reserved to be filled by
+// Template.Emit. It is only meaningful on the event passed to the build
+// function of Precompile, other events log 0.
+//
+// In JSON, width is the number of bytes reserved for the number, which is
+// right-aligned with spaces. Emit writes the event without padding when a
+// number doesn't fit, which is slower. A width lower than 1 reserves
+// SlotWidth bytes. In binary mode, width is ignored.
+//
+// A slot redacted by a RedactHook is logged as "[REDACTED]", its argument
+// of Emit being ignored.
+func (e *Event) Slot(key string, width int) *Event {
+	if e == nil {
+		return e
+	}
+	if e.redacted(key) {
+		if e.tpl != nil {
+			// The argument of the redacted slot is ignored by Emit.
+			e.tpl.args++
+		}
+		return e
+	}
+	e.buf = enc.AppendKey(e.buf, key)
+	if e.tpl == nil {
+		e.buf = enc.AppendInt64(e.buf, 0)
+		return e
+	}
+	if width < 1 {
+		width = SlotWidth
+	}
+	off := len(e.buf)
+	e.buf = appendSlot(e.buf, width)
+	e.tpl.slots = append(e.tpl.slots, templateSlot{off: off, size: len(e.buf) - off, arg: e.tpl.args})
+	e.tpl.args++
+	return e
+}
+
+// Emit writes an event of t with the slots filled with args, in the order
+// they were reserved. Missing args are logged as 0, and extraneous ones are
+// ignored.
+func (t *Template) Emit(args ...int64) {
+	e := t.newEvent()
+	if e == nil {
+		return
+	}
+	for _, s := range t.slots {
+		var v int64
+		if s.arg < len(args) {
+			v = args[s.arg]
+		}
+		if !putSlotInt64(e.buf[s.off:s.off+s.size], v) {
+			e.buf = t.appendCompact(e.buf[:0], func(dst []byte, arg int) []byte {
+				if arg < len(args) {
+					return enc.AppendInt64(dst, args[arg])
+				}
+				return enc.AppendInt64(dst, 0)
+			})
+			break
+		}
+	}
+	t.write(e)
+}
+
+// EmitUint64 is like Emit with unsigned args.
+func (t *Template) EmitUint64(args ...uint64) {
+	e := t.newEvent()
+	if e == nil {
+		return
+	}
+	for _, s := range t.slots {
+		var v uint64
+		if s.arg < len(args) {
+			v = args[s.arg]
+		}
+		if !putSlotUint64(e.buf[s.off:s.off+s.size], v) {
+			e.buf = t.appendCompact(e.buf[:0], func(dst []byte, arg int) []byte {
+				if arg < len(args) {
+					return enc.AppendUint64(dst, args[arg])
+				}
+				return enc.AppendUint64(dst, 0)
+			})
+			break
+		}
+	}
+	t.write(e)
+}
+
+// newEvent returns an event holding a copy of the template, or nil if the
+// event is filtered out.
+func (t *Template) newEvent() *Event {
+	if t.buf == nil || t.level == Disabled || !t.l.should(t.level) {
+		return nil
+	}
+	w := t.l.writer()
+	if w == nil {
+		return nil
+	}
+	e := newEvent(w, t.level)
+	e.buf = append(e.buf[:0], t.buf...)
+	return e
+}
+
+// appendCompact appends the template to dst with the slots replaced by the
+// numbers appended by num for their argument, without padding.
+func (t *Template) appendCompact(dst []byte, num func(dst []byte, arg int) []byte) []byte {
+	prev := 0
+	for _, s := range t.slots {
+		dst = append(dst, t.buf[prev:s.off]...)
+		dst = num(dst, s.arg)
+		prev = s.off + s.size
+	}
+	return append(dst, t.buf[prev:]...)
+}
+
+func (t *Template) write(e *Event) {
+	_, err := e.w.WriteLevel(e.level, e.buf)
+	putEvent(e)
+	if err != nil {
+		handleWriteError(err)
+	}
+}
@@ -0,0 +1,108 @@
+//go:build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).With().Str("foo", "bar").Logger()
+	tpl := Precompile(log, InfoLevel, func(e *Event) {
+		e.Slot("n", 4).Str("s", "x").Slot("m", 0).Msg("hot")
+	})
+
+	tpl.Emit(42, -7)
+	tpl.Emit(-999, math.MinInt64)
+	tpl.Emit(1)
+	want := `{"level":"info","foo":"bar","n":  42,"s":"x","m":                  -7,"message":"hot"}` + "\n" +
+		`{"level":"info","foo":"bar","n":-999,"s":"x","m":-9223372036854775808,"message":"hot"}` + "\n" +
+		`{"level":"info","foo":"bar","n":   1,"s":"x","m":                   0,"message":"hot"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTemplateOverflow(t *testing.T) {
+	out := &bytes.Buffer{}
+	tpl := Precompile(New(out), InfoLevel, func(e *Event) {
+		e.Slot("a", 2).Slot("b", 2).Send()
+	})
+
+	tpl.Emit(12345, 1)
+	tpl.Emit(-10, 3)
+	tpl.EmitUint64(math.MaxUint64)
+	tpl.EmitUint64(9, 99)
+	want := `{"level":"info","a":12345,"b":1}` + "\n" +
+		`{"level":"info","a":-10,"b":3}` + "\n" +
+		`{"level":"info","a":18446744073709551615,"b":0}` + "\n" +
+		`{"level":"info","a": 9,"b":99}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTemplateLevel(t *testing.T) {
+	out := &bytes.Buffer{}
+	lv := NewLevelVar(WarnLevel)
+	log := New(out).LevelVar(lv)
+	tpl := Precompile(log, InfoLevel, func(e *Event) {
+		e.Slot("n", 1).Msg("")
+	})
+
+	tpl.Emit(1)
+	lv.Set(InfoLevel)
+	tpl.Emit(2)
+	if got, want := out.String(), `{"level":"info","n":2}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTemplateHooks(t *testing.T) {
+	out := &bytes.Buffer{}
+	var runs int
+	log := New(out).Hook(HookFunc(func(e *Event, level Level, msg string) {
+		runs++
+		e.Str("hook", "run")
+	}))
+	tpl := Precompile(log, InfoLevel, func(e *Event) {
+		e.Slot("n", 1).Msg("")
+	})
+	tpl.Emit(1)
+
+	if runs != 0 {
+		t.Errorf("hook run %d times, want 0", runs)
+	}
+	if got, want := out.String(), `{"level":"info","n":1}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTemplateNotSent(t *testing.T) {
+	out := &bytes.Buffer{}
+	tpl := Precompile(New(out), InfoLevel, func(e *Event) {
+		e.Slot("n", 1)
+	})
+	tpl.Emit(1)
+	New(out).Log().Slot("n", 3).Send()
+
+	if got, want := out.String(), `{"n":0}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTemplateRedact(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(out).Hook(RedactHook("ssn", "pin"))
+	tpl := Precompile(log, InfoLevel, func(e *Event) {
+		e.Str("ssn", "123-45-6789").Int("id", 7).Slot("pin", 4).Slot("n", 1).Msg("")
+	})
+	tpl.Emit(1234, 5)
+
+	if got, want := out.String(), `{"level":"info","ssn":"[REDACTED]","id":7,"pin":"[REDACTED]","n":5}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,120 @@
+package zerolog
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/x0f5c3/zerolog/internal/cbor"
+)
+
+// textFormatLevel formats the levels of RenderText, like ConsoleWriter
+// without colors.
+var textFormatLevel = consoleDefaultFormatLevel(consoleNoColors)
+
+// RenderText renders the event line, in JSON or in binary format, as a
+// single human readable line without color and without trailing newline:
+//
+//	2024-06-01T12:00:00Z ERR the message error="not found" key=value
+//
+// The time, level and message are omitted if the event has none, and the
+// other fields follow sorted by name, the error first. Unlike ConsoleWriter,
+// it has no options and needs no setup, for instance to print the events of
+// a log file from a tool with no JSON processor at hand. A line which is not
+// an event is returned as is, without its surrounding spaces.
+func RenderText(line []byte) string {
+	if len(line) > 0 && line[0] > 0x7f {
+		var b bytes.Buffer
+		if err := cbor.ManyObjCBOR2JSON(bytes.NewReader(line), &b); err != nil {
+			return strconv.Quote(string(line))
+		}
+		line = b.Bytes()
+	}
+	evt := map[string]interface{}{}
+	if err := consoleDecodeEvent(line, evt); err != nil {
+		return string(bytes.TrimSpace(line))
+	}
+
+	var sb strings.Builder
+	part := func(s string) {
+		if s == "" {
+			return
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(s)
+	}
+	if t, ok := evt[TimestampFieldName]; ok {
+		part(textValue(t, false))
+	}
+	if l, ok := evt[LevelFieldName]; ok {
+		part(textFormatLevel(l))
+	}
+	if m, ok := evt[MessageFieldName]; ok {
+		part(textValue(m, false))
+	}
+
+	fields := make([]string, 0, len(evt))
+	for field := range evt {
+		switch field {
+		case TimestampFieldName, LevelFieldName, MessageFieldName:
+			continue
+		}
+		fields = append(fields, field)
+	}
+	sortFields(fields)
+	for i, field := range fields {
+		if field == ErrorFieldName {
+			copy(fields[1:i+1], fields[:i])
+			fields[0] = ErrorFieldName
+			break
+		}
+	}
+	for _, field := range fields {
+		part(field + "=" + textValue(evt[field], true))
+	}
+	return sb.String()
+}
+
+// textValue formats the decoded value v of a field for RenderText. The
+// strings are quoted if quote is true and they contain spaces or special
+// characters.
+func textValue(v interface{}, quote bool) string {
+	switch v := v.(type) {
+	case string:
+		if quote && needsQuote(v) {
+			return strconv.Quote(v)
+		}
+		return v
+	case json.Number:
+		return string(v)
+	}
+	b, err := InterfaceMarshalFunc(v)
+	if err != nil {
+		return "[error: " + err.Error() + "]"
+	}
+	return string(b)
+}
+
+// TextifyStream reads the events of r, in JSON lines or in binary format,
+// and writes each of them rendered by RenderText on its own line to w. The
+// lines of r which are not events are copied as is.
+func TextifyStream(r io.Reader, w io.Writer) error {
+	next := replayReader(bufio.NewReader(r))
+	for {
+		evt, err := next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, RenderText(evt)+"\n"); err != nil {
+			return err
+		}
+	}
+}
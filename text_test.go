@@ -0,0 +1,61 @@
+package zerolog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRenderText(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"typical", `{"level":"error","error":"not found","time":"2024-06-01T12:00:00Z","message":"the message","key":"value","n":1.5}` + "\n",
+			`2024-06-01T12:00:00Z ERR the message error="not found" key=value n=1.5`},
+		{"nested", `{"level":"info","obj":{"a":[1,2]},"ok":true,"nil":null}`,
+			`INF nil=null obj={"a":[1,2]} ok=true`},
+		{"unix time", `{"time":1717243200,"message":"msg"}`, `1717243200 msg`},
+		{"custom level", `{"level":"notice","message":"msg"}`, `notice msg`},
+		{"no level nor time", `{"message":"only message"}`, `only message`},
+		{"fields only", `{"b":"2","a":"1"}`, `a=1 b=2`},
+		{"empty object", `{}`, ``},
+		{"empty line", "\n", ``},
+		{"not an event", "  plain text\n", `plain text`},
+		{"invalid JSON", `{"level":`, `{"level":`},
+		{"binary", "\xbf\x65level\x65error\x67message\x64boom\xff", `ERR boom`},
+		{"invalid binary", "\xbf\x65lev", `"\xbfelev"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderText([]byte(tt.line)); got != tt.want {
+				t.Errorf("invalid text:\ngot:  %v\nwant: %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextifyStream(t *testing.T) {
+	in := `{"level":"info","message":"one"}` + "\n" +
+		"\n" +
+		"not an event\n" +
+		`{"level":"warn","message":"two","k":"v"}`
+	var out bytes.Buffer
+	if err := TextifyStream(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := "INF one\nnot an event\nWRN two k=v\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	out.Reset()
+	bin := "\xbf\x65level\x64info\x67message\x63one\xff\xbf\x67message\x63two\xff"
+	if err := TextifyStream(strings.NewReader(bin), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "INF one\ntwo\n"; got != want {
+		t.Errorf("invalid output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package zerolog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// encoderTraceSink is the destination of the encoder traces, set with
+// TraceEncoder.
+var encoderTraceSink atomic.Pointer[traceSink]
+
+type traceSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// TraceEncoder makes the events record the bytes each of their fields
+// appends to the encoded event, and writes this trace to w after writing
+// the event, to debug the encoding of events: a missing delimiter or a
+// duplicated key can be traced back to the method which produced it.
+// Passing nil disables it.
+//
+// Each field is written on its own line, indented by its nesting depth,
+// with the Event method adding it, its key, and the range and content of
+// the bytes appended by the encoder, a blank line ending the event:
+//
+//	Str "level" [1:15] "\"level\":\"info\""
+//	Dict "req" [15:47] ",\"req\":{\"method\":\"GET\",\"size\":2}"
+//	  Str "method" [23:37] "\"method\":\"GET\""
+//	  Int "size" [37:46] ",\"size\":2"
+//
+// It is meant for development: tracing is costly, while the events only
+// check whether it is enabled when it is not.
+func TraceEncoder(w io.Writer) {
+	if w == nil {
+		encoderTraceSink.Store(nil)
+		return
+	}
+	encoderTraceSink.Store(&traceSink{w: w})
+}
+
+// encoderTrace records the fields of an event, see TraceEncoder.
+type encoderTrace struct {
+	spans []encoderSpan
+}
+
+// encoderSpan is the range of the bytes of a field of an event. end is -1
+// until the field is complete.
+type encoderSpan struct {
+	method     string
+	key        string
+	start, end int
+	depth      int
+}
+
+// traceSpan starts a span for the field key added by method, or by the
+// exported Event method in the call stack if method is empty. It completes
+// the spans of the previous fields at the same depth or deeper. A method
+// delegating the field to another one, like DictFn to Dict, keeps its span.
+func (e *Event) traceSpan(method, key string) {
+	if n := len(e.trace.spans); n > 0 {
+		if s := e.trace.spans[n-1]; s.end < 0 && s.key == key && s.depth == e.depth && s.start == len(e.buf) {
+			return
+		}
+	}
+	if method == "" {
+		method = traceMethod()
+	}
+	e.trace.close(e.depth, len(e.buf))
+	e.trace.spans = append(e.trace.spans, encoderSpan{method, key, len(e.buf), -1, e.depth})
+}
+
+// close completes the spans at depth or deeper at end.
+func (t *encoderTrace) close(depth, end int) {
+	for i := range t.spans {
+		if s := &t.spans[i]; s.end < 0 && s.depth >= depth {
+			s.end = end
+		}
+	}
+}
+
+// merge adds the spans of dict, appended at offset to the event of t with
+// depth, to t.
+func (t *encoderTrace) merge(dict *Event, offset, depth int) {
+	dict.trace.close(0, len(dict.buf))
+	for _, s := range dict.trace.spans {
+		s.start += offset
+		s.end += offset
+		s.depth += depth - dict.depth
+		t.spans = append(t.spans, s)
+	}
+}
+
+// flush writes the spans of t, for the encoded event p, to the sink.
+func (t *encoderTrace) flush(p []byte) {
+	sink := encoderTraceSink.Load()
+	if sink == nil {
+		return
+	}
+	var buf bytes.Buffer
+	for _, s := range t.spans {
+		if s.end < 0 || s.end > len(p) {
+			s.end = len(p)
+		}
+		fmt.Fprintf(&buf, "%s%s %q [%d:%d] %q\n", strings.Repeat("  ", s.depth), s.method, s.key, s.start, s.end, p[s.start:s.end])
+	}
+	buf.WriteByte('\n')
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	_, _ = sink.w.Write(buf.Bytes())
+}
+
+// traceMethod returns the name of the first exported Event method in the
+// call stack of its caller.
+func traceMethod() string {
+	var pcs [16]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if i := strings.LastIndex(f.Function, ".(*Event)."); i >= 0 {
+			name := f.Function[i+len(".(*Event)."):]
+			if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+				return name
+			}
+		}
+		if !more {
+			return "?"
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !binary_log
+
+package zerolog
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTraceEncoder(t *testing.T) {
+	var out, trace bytes.Buffer
+	TraceEncoder(&trace)
+	defer TraceEncoder(nil)
+	log := New(&out).With().Str("app", "x").Logger()
+	log.Info().
+		Str("foo", "bar").
+		Dict("req", Dict().Str("method", "GET").Int("size", 2)).
+		Object("obj", obj{"a", "b", 1}).
+		Msg("hello")
+
+	wantOut := `{"level":"info","app":"x","foo":"bar","req":{"method":"GET","size":2},"obj":{"Pub":"a","Tag":"b","priv":1},"message":"hello"}` + "\n"
+	if got := out.String(); got != wantOut {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, wantOut)
+	}
+	want := `Str "level" [1:15] "\"level\":\"info\""
+Context "" [15:25] ",\"app\":\"x\""
+Str "foo" [25:37] ",\"foo\":\"bar\""
+Dict "req" [37:69] ",\"req\":{\"method\":\"GET\",\"size\":2}"
+  Str "method" [45:59] "\"method\":\"GET\""
+  Int "size" [59:68] ",\"size\":2"
+Object "obj" [69:106] ",\"obj\":{\"Pub\":\"a\",\"Tag\":\"b\",\"priv\":1}"
+  Str "Pub" [77:86] "\"Pub\":\"a\""
+  Str "Tag" [86:96] ",\"Tag\":\"b\""
+  Int "priv" [96:105] ",\"priv\":1"
+Msg "message" [106:124] ",\"message\":\"hello\""
+
+`
+	if got := trace.String(); got != want {
+		t.Errorf("invalid trace:\ngot:  %v\nwant: %v", got, want)
+	}
+	// The ranges are the ones of the event.
+	if got, want := wantOut[37:69], `,"req":{"method":"GET","size":2}`; got != want {
+		t.Errorf("invalid range: %q, want: %q", got, want)
+	}
+
+	trace.Reset()
+	TraceEncoder(nil)
+	log.Info().Str("foo", "bar").Msg("")
+	if trace.Len() != 0 {
+		t.Errorf("trace written while disabled: %q", trace.String())
+	}
+}
+
+func TestTraceEncoderDictFn(t *testing.T) {
+	var out, trace bytes.Buffer
+	TraceEncoder(&trace)
+	defer TraceEncoder(nil)
+	New(&out).Log().
+		DictFn("a", func(d *Event) {
+			d.DictFn("b", func(d *Event) { d.Int("c", 1) })
+		}).
+		Bool("d", true).
+		Send()
+
+	if got, want := out.String(), `{"a":{"b":{"c":1}},"d":true}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+	want := `DictFn "a" [1:18] "\"a\":{\"b\":{\"c\":1}}"
+  DictFn "b" [6:17] "\"b\":{\"c\":1}"
+    Int "c" [11:16] "\"c\":1"
+Bool "d" [18:27] ",\"d\":true"
+
+`
+	if got := trace.String(); got != want {
+		t.Errorf("invalid trace:\ngot:  %v\nwant: %v", got, want)
+	}
+}
@@ -0,0 +1,314 @@
+package zerolog
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	durationType     = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerT = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// UnmarshalEvent decodes line, an event written by a logger in JSON or in
+// binary mode, into the struct pointed to by v, for instance to replay the
+// parameters of a logged request.
+//
+// The fields of the event and of its dicts are mapped to the fields of the
+// structs by their json tag, or by their name if they have none. Unknown
+// fields are ignored. time.Time fields are parsed with TimeFieldFormat, or
+// as RFC 3339 times, and time.Duration fields with DurationFieldUnit, so
+// these globals must have the values they had when the event was written.
+//
+// An error naming the path of the field, like "user.tags[1]", is returned if
+// a value cannot be stored in the field it maps to.
+func UnmarshalEvent(line []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot unmarshal event into %T: not a pointer to a struct", v)
+	}
+	pe, err := parseMemoryEvent(memoryEvent{raw: line})
+	if err != nil {
+		return err
+	}
+	return unmarshalStruct("", pe.Fields, rv.Elem())
+}
+
+// unmarshalValue stores the decoded value src into dst, path being the path
+// of dst in the event.
+func unmarshalValue(path string, src interface{}, dst reflect.Value) error {
+	if src == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	switch dst.Type() {
+	case timeType:
+		t, err := unmarshalTime(src)
+		if err != nil {
+			return unmarshalError(path, src, dst, err)
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	case durationType:
+		d, err := unmarshalDuration(src)
+		if err != nil {
+			return unmarshalError(path, src, dst, err)
+		}
+		dst.SetInt(int64(d))
+		return nil
+	}
+	if s, ok := src.(string); ok && reflect.PtrTo(dst.Type()).Implements(textUnmarshalerT) {
+		if err := dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return unmarshalError(path, src, dst, err)
+		}
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.Ptr:
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		return unmarshalValue(path, src, dst.Elem())
+	case reflect.Interface:
+		if dst.NumMethod() == 0 {
+			dst.Set(reflect.ValueOf(src))
+			return nil
+		}
+	case reflect.Struct:
+		if m, ok := src.(map[string]interface{}); ok {
+			return unmarshalStruct(path, m, dst)
+		}
+	case reflect.Map:
+		if m, ok := src.(map[string]interface{}); ok && dst.Type().Key().Kind() == reflect.String {
+			if dst.IsNil() {
+				dst.Set(reflect.MakeMapWithSize(dst.Type(), len(m)))
+			}
+			for key, val := range m {
+				elem := reflect.New(dst.Type().Elem()).Elem()
+				if err := unmarshalValue(joinPath(path, key), val, elem); err != nil {
+					return err
+				}
+				dst.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+			}
+			return nil
+		}
+	case reflect.Slice:
+		if s, ok := src.(string); ok && dst.Type().Elem().Kind() == reflect.Uint8 {
+			dst.SetBytes([]byte(s))
+			return nil
+		}
+		if a, ok := src.([]interface{}); ok {
+			s := reflect.MakeSlice(dst.Type(), len(a), len(a))
+			for i, val := range a {
+				if err := unmarshalValue(path+"["+strconv.Itoa(i)+"]", val, s.Index(i)); err != nil {
+					return err
+				}
+			}
+			dst.Set(s)
+			return nil
+		}
+	case reflect.Array:
+		if a, ok := src.([]interface{}); ok && len(a) == dst.Len() {
+			for i, val := range a {
+				if err := unmarshalValue(path+"["+strconv.Itoa(i)+"]", val, dst.Index(i)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	case reflect.String:
+		if s, ok := src.(string); ok {
+			dst.SetString(s)
+			return nil
+		}
+	case reflect.Bool:
+		if b, ok := src.(bool); ok {
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := src.(json.Number); ok {
+			i, err := strconv.ParseInt(n.String(), 10, dst.Type().Bits())
+			if err != nil {
+				return unmarshalError(path, src, dst, err)
+			}
+			dst.SetInt(i)
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n, ok := src.(json.Number); ok {
+			u, err := strconv.ParseUint(n.String(), 10, dst.Type().Bits())
+			if err != nil {
+				return unmarshalError(path, src, dst, err)
+			}
+			dst.SetUint(u)
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		switch n := src.(type) {
+		case json.Number:
+			f, err := strconv.ParseFloat(n.String(), dst.Type().Bits())
+			if err != nil {
+				return unmarshalError(path, src, dst, err)
+			}
+			dst.SetFloat(f)
+			return nil
+		case string:
+			// NaN and infinities are logged as strings.
+			if f, err := strconv.ParseFloat(n, dst.Type().Bits()); err == nil && (math.IsNaN(f) || math.IsInf(f, 0)) {
+				dst.SetFloat(f)
+				return nil
+			}
+		}
+	}
+	return unmarshalError(path, src, dst, nil)
+}
+
+// unmarshalStruct stores the fields of m into the fields of the struct dst.
+func unmarshalStruct(path string, m map[string]interface{}, dst reflect.Value) error {
+	t := dst.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := unmarshalFieldName(f)
+		if !ok {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			fv := dst.Field(i)
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if err := unmarshalStruct(path, m, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		val, ok := m[name]
+		if !ok {
+			continue
+		}
+		if err := unmarshalValue(joinPath(path, name), val, dst.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalFieldName returns the name of the event field mapped to f, empty
+// for the embedded structs whose fields are promoted, and false if f is not
+// mapped.
+func unmarshalFieldName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if f.Anonymous && name == "" {
+		t := f.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct && t != timeType {
+			return "", true
+		}
+	}
+	if f.PkgPath != "" { // unexported
+		return "", false
+	}
+	if name == "" {
+		name = f.Name
+	}
+	return name, true
+}
+
+// unmarshalTime parses a time logged with TimeFieldFormat.
+func unmarshalTime(src interface{}) (time.Time, error) {
+	switch v := src.(type) {
+	case string:
+		switch TimeFieldFormat {
+		case TimeFormatUnix, TimeFormatUnixMs, TimeFormatUnixMicro, TimeFormatUnixNano:
+		default:
+			if t, err := time.Parse(TimeFieldFormat, v); err == nil {
+				return t, nil
+			}
+		}
+		return time.Parse(time.RFC3339Nano, v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			f, err := v.Float64()
+			if err != nil {
+				return time.Time{}, err
+			}
+			sec, frac := math.Modf(f)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+		switch TimeFieldFormat {
+		case TimeFormatUnixMs:
+			return time.UnixMilli(i), nil
+		case TimeFormatUnixMicro:
+			return time.UnixMicro(i), nil
+		case TimeFormatUnixNano:
+			return time.Unix(0, i), nil
+		}
+		return time.Unix(i, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("not a time")
+}
+
+// unmarshalDuration parses a duration logged with DurationFieldUnit.
+func unmarshalDuration(src interface{}) (time.Duration, error) {
+	switch v := src.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return time.Duration(i) * DurationFieldUnit, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(math.Round(f * float64(DurationFieldUnit))), nil
+	case string:
+		return time.ParseDuration(v)
+	}
+	return 0, fmt.Errorf("not a duration")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func unmarshalError(path string, src interface{}, dst reflect.Value, err error) error {
+	kind := "value"
+	switch src.(type) {
+	case string:
+		kind = "string"
+	case json.Number:
+		kind = "number"
+	case bool:
+		kind = "bool"
+	case map[string]interface{}:
+		kind = "object"
+	case []interface{}:
+		kind = "array"
+	}
+	if err != nil {
+		return fmt.Errorf("cannot unmarshal %s into field %s of type %s: %v", kind, path, dst.Type(), err)
+	}
+	return fmt.Errorf("cannot unmarshal %s into field %s of type %s", kind, path, dst.Type())
+}
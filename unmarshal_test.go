@@ -0,0 +1,127 @@
+package zerolog
+
+import (
+	"bytes"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type replayAddress struct {
+	City string `json:"city"`
+	Zip  int    `json:"zip"`
+}
+
+type replayMeta struct {
+	Trace string `json:"trace"`
+}
+
+type replayRequest struct {
+	replayMeta
+	Method   string            `json:"method"`
+	Size     uint16            `json:"size"`
+	Ratio    float64           `json:"ratio"`
+	Secure   bool              `json:"secure"`
+	At       time.Time         `json:"at"`
+	Took     time.Duration     `json:"took"`
+	Tags     []string          `json:"tags"`
+	Body     []byte            `json:"body"`
+	IP       net.IP            `json:"ip"`
+	Address  *replayAddress    `json:"address"`
+	Headers  map[string]string `json:"headers"`
+	Extra    interface{}       `json:"extra"`
+	Ignored  string            `json:"-"`
+	Untagged string
+	internal string
+}
+
+func (r replayRequest) MarshalZerologObject(e *Event) {
+	e.Str("trace", r.Trace).
+		Str("method", r.Method).
+		Uint16("size", r.Size).
+		Float64("ratio", r.Ratio).
+		Bool("secure", r.Secure).
+		Time("at", r.At).
+		Dur("took", r.Took).
+		Strs("tags", r.Tags).
+		Bytes("body", r.Body).
+		Str("ip", r.IP.String()).
+		Dict("address", Dict().Str("city", r.Address.City).Int("zip", r.Address.Zip)).
+		Fields(map[string]interface{}{"headers": r.Headers}).
+		Int("extra", 1).
+		Str("Untagged", r.Untagged).
+		Str("unknown", "x")
+}
+
+func TestUnmarshalEvent(t *testing.T) {
+	want := replayRequest{
+		replayMeta: replayMeta{Trace: "abc"},
+		Method:     "POST",
+		Size:       512,
+		Ratio:      0.25,
+		Secure:     true,
+		At:         time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC),
+		Took:       1500 * time.Microsecond,
+		Tags:       []string{"a", "b"},
+		Body:       []byte("payload"),
+		IP:         net.ParseIP("127.0.0.1"),
+		Address:    &replayAddress{City: "Paris", Zip: 75001},
+		Headers:    map[string]string{"Accept": "*/*"},
+		Untagged:   "yes",
+	}
+
+	out := &bytes.Buffer{}
+	New(out).Info().EmbedObject(want).Msg("replay")
+
+	var got replayRequest
+	if err := UnmarshalEvent(out.Bytes(), &got); err != nil {
+		t.Fatalf("UnmarshalEvent() error = %v", err)
+	}
+	if !got.At.Equal(want.At) {
+		t.Errorf("UnmarshalEvent() At = %v, want %v", got.At, want.At)
+	}
+	got.At = want.At
+	if got.Extra == nil {
+		t.Errorf("UnmarshalEvent() Extra = nil, want 1")
+	}
+	got.Extra = nil
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UnmarshalEvent() = %+v, want %+v", got, want)
+	}
+}
+
+func TestUnmarshalEventErrors(t *testing.T) {
+	var dst struct {
+		Address struct {
+			Zips []uint16 `json:"zips"`
+		} `json:"address"`
+	}
+	tests := []struct {
+		name string
+		line func(e *Event)
+		v    interface{}
+		want string
+	}{
+		{"not a pointer", func(e *Event) {}, dst, "not a pointer to a struct"},
+		{"type mismatch", func(e *Event) {
+			e.Dict("address", Dict().Str("zips", "x"))
+		}, &dst, "cannot unmarshal string into field address.zips of type []uint16"},
+		{"overflow", func(e *Event) {
+			e.Dict("address", Dict().Ints("zips", []int{1, 70000}))
+		}, &dst, "field address.zips[1] of type uint16"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			e := New(out).Log()
+			tt.line(e)
+			e.Msg("")
+			err := UnmarshalEvent(out.Bytes(), tt.v)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("UnmarshalEvent() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
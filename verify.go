@@ -0,0 +1,178 @@
+package zerolog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// IssueKind is the kind of an Issue.
+type IssueKind int
+
+const (
+	// IssueMsgTwice is reported when Msg, Msgf, MsgFunc or Send is called
+	// on an event which was already sent.
+	IssueMsgTwice IssueKind = iota + 1
+
+	// IssueFieldAfterSend is reported when a field is added to an event
+	// which was already sent.
+	IssueFieldAfterSend
+
+	// IssueNotSent is reported when an event is garbage collected without
+	// having been sent or discarded.
+	IssueNotSent
+
+	// IssueNoWriter is reported the first time a Logger without writer, like
+	// the zero value of Logger, is used. Its events are silently dropped.
+	IssueNoWriter
+)
+
+// String returns a description of k.
+func (k IssueKind) String() string {
+	switch k {
+	case IssueMsgTwice:
+		return "event sent twice"
+	case IssueFieldAfterSend:
+		return "field added after the event was sent"
+	case IssueNotSent:
+		return "event never sent"
+	case IssueNoWriter:
+		return "logger without writer"
+	}
+	return fmt.Sprintf("IssueKind(%d)", int(k))
+}
+
+// Issue is a misuse of the API detected by VerifyMode.
+type Issue struct {
+	Kind IssueKind
+
+	// Key is the key of the field for IssueFieldAfterSend.
+	Key string
+
+	// Function, File and Line locate the call misusing the API, the call
+	// creating the event for IssueNotSent.
+	Function string
+	File     string
+	Line     int
+}
+
+// String returns a description of i with its call site.
+func (i Issue) String() string {
+	s := "zerolog: " + i.Kind.String()
+	if i.Key != "" {
+		s += fmt.Sprintf(" (%q)", i.Key)
+	}
+	if i.File != "" {
+		s += fmt.Sprintf(" at %s:%d", i.File, i.Line)
+	}
+	return s
+}
+
+var (
+	verifyEnabled  uint32
+	verifyReporter atomic.Value // func(Issue)
+
+	// verifySampling is the rate of the events tracked for IssueNotSent:
+	// capturing their call site and setting a finalizer is expensive.
+	verifySampling uint32 = 16
+	verifyCount    uint32
+
+	// verifyNoWriter holds the loggers reported for IssueNoWriter.
+	verifyNoWriter sync.Map // map[*Logger]struct{}
+)
+
+// VerifyMode enables the detection of the misuses of the API, like sending
+// an event twice or never, reporting each to reporter. A nil reporter
+// disables it. It is a debugging facility: once enabled, the events are no
+// longer pooled, and one event out of 16 is tracked to detect it is never
+// sent, when it is garbage collected. The reporter may be called
+// concurrently, and from the goroutine running the finalizers.
+//
+//	zerolog.VerifyMode(func(i zerolog.Issue) {
+//	    log.Println(i)
+//	})
+//
+// When disabled, the cost of the detection is a check of a global flag.
+func VerifyMode(reporter func(issue Issue)) {
+	if reporter == nil {
+		atomic.StoreUint32(&verifyEnabled, 0)
+		return
+	}
+	verifyReporter.Store(reporter)
+	atomic.StoreUint32(&verifyEnabled, 1)
+}
+
+func verifying() bool {
+	return atomic.LoadUint32(&verifyEnabled) == 1
+}
+
+// reportIssue reports the issue of kind at the call site frame to the
+// reporter of VerifyMode if it is enabled.
+func reportIssue(kind IssueKind, key string, frame runtime.Frame) {
+	if !verifying() {
+		return
+	}
+	reporter, _ := verifyReporter.Load().(func(Issue))
+	if reporter == nil {
+		return
+	}
+	reporter(Issue{
+		Kind:     kind,
+		Key:      key,
+		Function: frame.Function,
+		File:     frame.File,
+		Line:     frame.Line,
+	})
+}
+
+// verifyTrack tracks e, a new event, for IssueNotSent if it is sampled.
+func verifyTrack(e *Event) {
+	if atomic.AddUint32(&verifyCount, 1)%atomic.LoadUint32(&verifySampling) != 0 {
+		return
+	}
+	frame := verifyCaller()
+	runtime.SetFinalizer(e, nil)
+	runtime.SetFinalizer(e, func(e *Event) {
+		if !e.finished && e.level != Disabled {
+			reportIssue(IssueNotSent, "", frame)
+		}
+	})
+}
+
+// verifyLogger reports l for IssueNoWriter the first time it is used.
+func verifyLogger(l *Logger) {
+	if _, loaded := verifyNoWriter.LoadOrStore(l, struct{}{}); !loaded {
+		reportIssue(IssueNoWriter, "", verifyCaller())
+	}
+}
+
+// verifyCaller returns the frame of the first caller outside of the package
+// and of its log subpackage.
+func verifyCaller() runtime.Frame {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		if !verifyInternal(frame) || !more {
+			return frame
+		}
+	}
+}
+
+const verifyPackage = "github.com/x0f5c3/zerolog"
+
+// verifyInternal returns true if frame is a function of the package, not
+// of its tests.
+func verifyInternal(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	name := strings.TrimPrefix(frame.Function, verifyPackage)
+	if len(name) == len(frame.Function) {
+		return false
+	}
+	name = strings.TrimPrefix(name, "/log")
+	return strings.HasPrefix(name, ".")
+}
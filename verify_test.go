@@ -0,0 +1,130 @@
+package zerolog
+
+import (
+	"bytes"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collectIssues enables VerifyMode for the duration of the test, returning
+// the function listing the reported issues of kind.
+func collectIssues(t *testing.T) func(kind IssueKind) []Issue {
+	var mu sync.Mutex
+	var issues []Issue
+	VerifyMode(func(i Issue) {
+		mu.Lock()
+		issues = append(issues, i)
+		mu.Unlock()
+	})
+	t.Cleanup(func() { VerifyMode(nil) })
+	return func(kind IssueKind) []Issue {
+		mu.Lock()
+		defer mu.Unlock()
+		var res []Issue
+		for _, i := range issues {
+			if i.Kind == kind {
+				res = append(res, i)
+			}
+		}
+		return res
+	}
+}
+
+// line returns the line following the call.
+func line() int {
+	_, _, l, _ := runtime.Caller(1)
+	return l + 1
+}
+
+func checkIssue(t *testing.T, issues []Issue, key string, line int) {
+	t.Helper()
+	if len(issues) != 1 {
+		t.Fatalf("reported issues = %v, want 1", issues)
+	}
+	i := issues[0]
+	if i.Key != key || filepath.Base(i.File) != "verify_test.go" || i.Line != line {
+		t.Errorf("reported issue = %+v, want key %q at verify_test.go:%d", i, key, line)
+	}
+}
+
+func TestVerifyModeMsgTwice(t *testing.T) {
+	issues := collectIssues(t)
+	out := &bytes.Buffer{}
+	e := New(out).Info()
+	e.Msg("a")
+	l := line()
+	e.Msg("b")
+	checkIssue(t, issues(IssueMsgTwice), "", l)
+	if got, want := decodeIfBinaryToString(out.Bytes()), `{"level":"info","message":"a"}`+"\n"; got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestVerifyModeMsgTwiceLarge(t *testing.T) {
+	issues := collectIssues(t)
+	e := New(io.Discard).Info().Str("foo", strings.Repeat("a", 1<<17))
+	e.Msg("a")
+	l := line()
+	e.Msg("b")
+	checkIssue(t, issues(IssueMsgTwice), "", l)
+}
+
+func TestVerifyModeFieldAfterSend(t *testing.T) {
+	issues := collectIssues(t)
+	e := New(&bytes.Buffer{}).Info()
+	e.Send()
+	l := line()
+	e.Str("foo", "bar")
+	checkIssue(t, issues(IssueFieldAfterSend), "foo", l)
+}
+
+func TestVerifyModeNoWriter(t *testing.T) {
+	issues := collectIssues(t)
+	var log Logger
+	l := line()
+	log.Info().Msg("a")
+	log.Info().Msg("b")
+	checkIssue(t, issues(IssueNoWriter), "", l)
+}
+
+func TestVerifyModeNotSent(t *testing.T) {
+	issues := collectIssues(t)
+	defer atomic.StoreUint32(&verifySampling, atomic.LoadUint32(&verifySampling))
+	atomic.StoreUint32(&verifySampling, 1)
+
+	log := New(&bytes.Buffer{})
+	l := line()
+	log.Info().Str("foo", "bar")
+	log.Info().Discard()
+	log.Info().Send()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(issues(IssueNotSent)) == 0 && time.Now().Before(deadline) {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	checkIssue(t, issues(IssueNotSent), "", l)
+}
+
+func TestVerifyModeDisabled(t *testing.T) {
+	issues := collectIssues(t)
+	VerifyMode(nil)
+	var log Logger
+	log.Info().Msg("")
+	e := New(&bytes.Buffer{}).Info()
+	e.Send()
+	if e.finished {
+		t.Error("event marked as sent with VerifyMode disabled")
+	}
+	for _, kind := range []IssueKind{IssueMsgTwice, IssueFieldAfterSend, IssueNotSent, IssueNoWriter} {
+		if got := issues(kind); len(got) != 0 {
+			t.Errorf("reported issues = %v, want none", got)
+		}
+	}
+}
@@ -2,12 +2,18 @@ package zerolog
 
 import (
 	"bytes"
+	"compress/gzip"
+	"errors"
 	"io"
+	"os"
 	"path"
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // LevelWriter defines as interface a writer may implement in order
@@ -58,6 +64,20 @@ func (s *syncWriter) WriteLevel(l Level, p []byte) (n int, err error) {
 	return s.lw.WriteLevel(l, p)
 }
 
+// Close closes the wrapped writer, see closeOutput.
+func (s *syncWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return closeOutput(s.lw)
+}
+
+// Sync syncs the wrapped writer, see syncOutput.
+func (s *syncWriter) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return syncOutput(s.lw)
+}
+
 type multiLevelWriter struct {
 	writers []LevelWriter
 }
@@ -90,9 +110,41 @@ func (t multiLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
 	return n, err
 }
 
+// Close closes the writers implementing io.Closer, once each, and returns
+// their errors joined.
+func (t multiLevelWriter) Close() error {
+	var errs []error
+	var closed []io.Writer
+	for _, w := range t.writers {
+		if writerIn(w, closed) {
+			continue
+		}
+		closed = append(closed, w)
+		if err := closeOutput(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync calls the Sync method of the writers having one, and returns their
+// errors joined.
+func (t multiLevelWriter) Sync() error {
+	var errs []error
+	for _, w := range t.writers {
+		if err := syncOutput(w); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // MultiLevelWriter creates a writer that duplicates its writes to all the
 // provided writers, similar to the Unix tee(1) command. If some writers
 // implement LevelWriter, their WriteLevel method will be used instead of Write.
+//
+// Its Close and Sync methods, called by Logger.Close and Logger.Sync, close
+// and sync the writers implementing them.
 func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 	lwriters := make([]LevelWriter, 0, len(writers))
 	for _, w := range writers {
@@ -105,6 +157,119 @@ func MultiLevelWriter(writers ...io.Writer) LevelWriter {
 	return multiLevelWriter{lwriters}
 }
 
+// NoLevelPolicy defines how a FilteredLevelWriter handles the events
+// without level, like the ones sent with Logger.Log or written with Write.
+type NoLevelPolicy int
+
+const (
+	// NoLevelPass writes the events without level regardless of the
+	// minimum level.
+	NoLevelPass NoLevelPolicy = iota
+	// NoLevelAsTrace filters the events without level like trace level
+	// events.
+	NoLevelAsTrace
+)
+
+// FilteredLevelWriter writes to Writer the events of Level or above,
+// dropping the others. Combined with MultiLevelWriter, it routes the events
+// to writers with different minimum levels, e.g. all the events to stdout
+// and the errors to a file as well:
+//
+//	w := zerolog.MultiLevelWriter(
+//	    os.Stdout,
+//	    zerolog.FilteredLevelWriter{Writer: f, Level: zerolog.ErrorLevel},
+//	)
+//
+// The WriteLevel method of Writer is used if it implements LevelWriter.
+type FilteredLevelWriter struct {
+	Writer  io.Writer
+	Level   Level
+	NoLevel NoLevelPolicy
+}
+
+// Write writes p, an event without level, according to the NoLevel
+// policy of w.
+func (w FilteredLevelWriter) Write(p []byte) (n int, err error) {
+	return w.WriteLevel(NoLevel, p)
+}
+
+// WriteLevel writes p to w.Writer if l is w.Level or above. The dropped
+// events are reported as written.
+func (w FilteredLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	if l == NoLevel && w.NoLevel == NoLevelAsTrace {
+		l = TraceLevel
+	}
+	if l != NoLevel && levelLess(l, w.Level) {
+		return len(p), nil
+	}
+	if lw, ok := w.Writer.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Writer.Write(p)
+}
+
+// Close closes w.Writer, see closeOutput.
+func (w FilteredLevelWriter) Close() error {
+	return closeOutput(w.Writer)
+}
+
+// Sync syncs w.Writer, see syncOutput.
+func (w FilteredLevelWriter) Sync() error {
+	return syncOutput(w.Writer)
+}
+
+// closeOutput closes w if it implements io.Closer, unless it is os.Stdout
+// or os.Stderr, which are left open for the rest of the program.
+func closeOutput(w io.Writer) error {
+	if lw, ok := w.(levelWriterAdapter); ok {
+		w = lw.Writer
+	}
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if c, ok := w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// syncOutput calls the Sync method of w if it has one, like os.File, unless
+// w is os.Stdout or os.Stderr, which may not support it.
+func syncOutput(w io.Writer) error {
+	if lw, ok := w.(levelWriterAdapter); ok {
+		w = lw.Writer
+	}
+	if w == os.Stdout || w == os.Stderr {
+		return nil
+	}
+	if s, ok := w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// writerIn returns true if w is one of writers, comparing only the
+// pointers, as the other values may hold uncomparable ones, like the
+// multiLevelWriter in a FilteredLevelWriter.
+func writerIn(w io.Writer, writers []io.Writer) bool {
+	if lw, ok := w.(levelWriterAdapter); ok {
+		w = lw.Writer
+	}
+	v := reflect.ValueOf(w)
+	if v.Kind() != reflect.Ptr {
+		return false
+	}
+	for _, w2 := range writers {
+		if lw, ok := w2.(levelWriterAdapter); ok {
+			w2 = lw.Writer
+		}
+		if v2 := reflect.ValueOf(w2); v2.Type() == v.Type() && v2.Pointer() == v.Pointer() {
+			return true
+		}
+	}
+	return false
+}
+
 // TestingLog is the logging interface of testing.TB.
 type TestingLog interface {
 	Log(args ...interface{})
@@ -118,13 +283,37 @@ type TestWriter struct {
 
 	// Frame skips caller frames to capture the original file and line numbers.
 	Frame int
+
+	// FailOnError makes the events of ErrorLevel or above fail the test,
+	// with the Errorf method of T if it has one like testing.TB.
+	FailOnError bool
+
+	// done is set once the test completed, if T has a Cleanup method.
+	done *uint32
 }
 
-// NewTestWriter creates a writer that logs to the testing.TB.
+// NewTestWriter creates a writer that logs to the testing.TB. If t has a
+// Cleanup method like testing.TB, the events written once the test
+// completed, for instance by a goroutine outliving it, are dropped instead
+// of making the test panic.
 //
 //goland:noinspection GoUnusedExportedFunction
-func NewTestWriter(t TestingLog) TestWriter {
-	return TestWriter{T: t}
+func NewTestWriter(t TestingLog, options ...func(w *TestWriter)) TestWriter {
+	w := TestWriter{T: t}
+	if c, ok := t.(interface{ Cleanup(func()) }); ok {
+		done := new(uint32)
+		c.Cleanup(func() { atomic.StoreUint32(done, 1) })
+		w.done = done
+	}
+	for _, opt := range options {
+		opt(&w)
+	}
+	return w
+}
+
+// TestWriterFailOnError is a NewTestWriter option setting FailOnError.
+func TestWriterFailOnError(w *TestWriter) {
+	w.FailOnError = true
 }
 
 // Write to testing.TB.
@@ -132,6 +321,9 @@ func (t TestWriter) Write(p []byte) (n int, err error) {
 	t.T.Helper()
 
 	n = len(p)
+	if t.completed() {
+		return n, nil
+	}
 
 	// Strip trailing newline because t.Log always adds one.
 	p = bytes.TrimRight(p, "\n")
@@ -151,9 +343,423 @@ func (t TestWriter) Write(p []byte) (n int, err error) {
 	return n, err
 }
 
+// WriteLevel implements the LevelWriter interface. The events of ErrorLevel
+// or above fail the test if FailOnError is set.
+func (t TestWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	t.T.Helper()
+	n, err = t.Write(p)
+	if t.FailOnError && l != NoLevel && !levelLess(l, ErrorLevel) && !t.completed() {
+		if f, ok := t.T.(interface{ Errorf(string, ...interface{}) }); ok {
+			f.Errorf("zerolog: %s level event logged: %s", l.String(), bytes.TrimRight(decodeIfBinaryToBytes(p), "\n"))
+		}
+	}
+	return n, err
+}
+
+// completed reports whether the test of t completed.
+func (t TestWriter) completed() bool {
+	return t.done != nil && atomic.LoadUint32(t.done) == 1
+}
+
 // ConsoleTestWriter creates an option that correctly sets the file frame depth for testing.TB log.
 func ConsoleTestWriter(t TestingLog) func(w *ConsoleWriter) {
 	return func(w *ConsoleWriter) {
-		w.Out = TestWriter{T: t, Frame: 6}
+		tw := NewTestWriter(t)
+		tw.Frame = 6
+		w.Out = tw
+	}
+}
+
+// GzipFlushInterval is the default interval at which a GzipLevelWriter
+// flushes the compressed data buffered since its last write.
+const GzipFlushInterval = time.Second
+
+// GzipLevelWriter is a LevelWriter compressing the events written to it with
+// gzip. It is safe for concurrent use.
+type GzipLevelWriter struct {
+	mu       sync.Mutex
+	gz       *gzip.Writer
+	w        io.Writer
+	interval time.Duration
+	timer    *time.Timer
+	closed   bool
+}
+
+// GzipWriter creates a GzipLevelWriter writing the events compressed with the
+// gzip compression level to w. If level is invalid, gzip.DefaultCompression
+// is used instead. The compressed data is flushed to w at most
+// GzipFlushInterval after a write, and on Close, which must be called to
+// write the gzip footer. It is flushed by FlushAll until closed, see
+// AutoRegisterFlushers.
+func GzipWriter(w io.Writer, level int) *GzipLevelWriter {
+	gz, err := gzip.NewWriterLevel(w, level)
+	if err != nil {
+		gz = gzip.NewWriter(w)
+	}
+	gw := &GzipLevelWriter{gz: gz, w: w, interval: GzipFlushInterval}
+	autoRegisterFlusher(gw, gw.Flush)
+	return gw
+}
+
+// SetFlushInterval sets the interval at which w flushes the compressed data
+// buffered since its last write. An interval lower than 1 disables the
+// periodic flush.
+func (w *GzipLevelWriter) SetFlushInterval(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.interval = d
+}
+
+// Write implements the io.Writer interface.
+func (w *GzipLevelWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errors.New("zerolog: write to closed GzipLevelWriter")
 	}
+	n, err = w.gz.Write(p)
+	if err == nil && w.timer == nil && w.interval > 0 {
+		w.timer = time.AfterFunc(w.interval, func() {
+			_ = w.Flush()
+		})
+	}
+	return n, err
+}
+
+// WriteLevel implements the LevelWriter interface.
+//
+//goland:noinspection GoUnusedParameter
+func (w *GzipLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	return w.Write(p)
+}
+
+// Flush writes the compressed data buffered so far to the underlying writer.
+func (w *GzipLevelWriter) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.stopTimer()
+	if w.closed {
+		return nil
+	}
+	return w.gz.Flush()
+}
+
+// Close flushes the compressed data and writes the gzip footer. It closes
+// the underlying writer, see closeOutput.
+func (w *GzipLevelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	UnregisterFlusher(w)
+	w.stopTimer()
+	err := w.gz.Close()
+	if cerr := closeOutput(w.w); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *GzipLevelWriter) stopTimer() {
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// BufferedWriterSize is the default size of the buffer of a
+// BufferedLevelWriter.
+const BufferedWriterSize = 64 << 10 // 64KiB
+
+// BufferedLevelWriter is a LevelWriter coalescing the events written to it
+// into larger writes to the underlying writer. It is safe for concurrent
+// use.
+type BufferedLevelWriter struct {
+	mu     sync.Mutex
+	buf    []byte
+	w      io.Writer
+	done   chan struct{}
+	wg     sync.WaitGroup
+	closed bool
+}
+
+// BufferedWriter creates a BufferedLevelWriter buffering up to size bytes of
+// events, BufferedWriterSize if size is lower than 1, before writing them to
+// w. The buffered events are also written every flushEvery by a background
+// goroutine, unless flushEvery is lower than 1, on Sync, and on Close, which
+// must be called to stop the goroutine.
+//
+// The fatal and panic level events are written immediately, with the ones
+// buffered before them, so they are not lost when the program exits. It is
+// flushed by FlushAll until closed, see AutoRegisterFlushers.
+func BufferedWriter(w io.Writer, size int, flushEvery time.Duration) *BufferedLevelWriter {
+	if size <= 0 {
+		size = BufferedWriterSize
+	}
+	b := &BufferedLevelWriter{
+		buf:  make([]byte, 0, size),
+		w:    w,
+		done: make(chan struct{}),
+	}
+	if flushEvery > 0 {
+		b.wg.Add(1)
+		go b.flushLoop(flushEvery)
+	}
+	autoRegisterFlusher(b, b.Sync)
+	return b
+}
+
+func (b *BufferedLevelWriter) flushLoop(d time.Duration) {
+	defer b.wg.Done()
+	t := time.NewTicker(d)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			b.mu.Lock()
+			err := b.flush()
+			b.mu.Unlock()
+			if err != nil {
+				handleWriteError(err)
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// Write implements the io.Writer interface.
+func (b *BufferedLevelWriter) Write(p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.write(p)
+}
+
+// WriteLevel implements the LevelWriter interface. The fatal and panic level
+// events are written immediately.
+func (b *BufferedLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n, err = b.write(p)
+	if err == nil && (l == FatalLevel || l == PanicLevel) {
+		err = b.flush()
+	}
+	return n, err
+}
+
+func (b *BufferedLevelWriter) write(p []byte) (n int, err error) {
+	if b.closed {
+		return 0, errors.New("zerolog: write to closed BufferedLevelWriter")
+	}
+	if len(b.buf)+len(p) > cap(b.buf) {
+		if err := b.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) > cap(b.buf) {
+		return b.w.Write(p)
+	}
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// flush writes the buffered events to the underlying writer, keeping the
+// ones it failed to write.
+func (b *BufferedLevelWriter) flush() error {
+	if len(b.buf) == 0 {
+		return nil
+	}
+	n, err := b.w.Write(b.buf)
+	if err == nil && n < len(b.buf) {
+		err = io.ErrShortWrite
+	}
+	b.buf = b.buf[:copy(b.buf, b.buf[n:])]
+	return err
+}
+
+// Sync writes the buffered events to the underlying writer, and calls its
+// Sync method if it has one, like os.File.
+func (b *BufferedLevelWriter) Sync() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.flush(); err != nil {
+		return err
+	}
+	if s, ok := b.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close stops the background goroutine and writes the buffered events. It
+// closes the underlying writer, see closeOutput.
+func (b *BufferedLevelWriter) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	UnregisterFlusher(b)
+	close(b.done)
+	b.mu.Unlock()
+	b.wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	err := b.flush()
+	if cerr := closeOutput(b.w); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// TriggerBufferSize is the default maximum size of the events buffered by a
+// TriggerLevelWriter.
+const TriggerBufferSize = 1 << 20 // 1MiB
+
+// TriggerLevelWriter buffers the events of ConditionalLevel or below, and
+// writes them only if an event of TriggerLevel or above is written, or if
+// Trigger is called, for instance to keep the debug events of a request
+// only when it fails:
+//
+//	w := &zerolog.TriggerLevelWriter{Writer: os.Stderr, ConditionalLevel: zerolog.DebugLevel, TriggerLevel: zerolog.ErrorLevel}
+//	defer w.Close()
+//	log := zerolog.New(w)
+//
+// The buffered events are written in order, each with its own call to
+// Writer, before the triggering event. Once triggered, the events are
+// written directly until Close. The other events, and the ones written with
+// Write, are always written directly. The WriteLevel method of Writer is
+// used if it implements LevelWriter.
+//
+// It is safe for concurrent use. Its zero value buffers the trace and debug
+// events until an event of any other level is written.
+type TriggerLevelWriter struct {
+	Writer           io.Writer
+	ConditionalLevel Level
+	TriggerLevel     Level
+
+	// MaxBufferSize is the maximum size of the buffered events,
+	// TriggerBufferSize if 0. The oldest events are dropped to stay under
+	// it.
+	MaxBufferSize int
+
+	mu        sync.Mutex
+	triggered bool
+	events    []triggerEvent
+	size      int
+}
+
+// NewTriggerLevelWriter creates a TriggerLevelWriter writing to w which
+// buffers up to bufferSize bytes of the events of the levels below
+// triggerLevel, TriggerBufferSize if 0, and writes them with the first event
+// of triggerLevel or above.
+func NewTriggerLevelWriter(w io.Writer, bufferSize int, triggerLevel Level) *TriggerLevelWriter {
+	return &TriggerLevelWriter{
+		Writer:           w,
+		ConditionalLevel: triggerLevel - 1,
+		TriggerLevel:     triggerLevel,
+		MaxBufferSize:    bufferSize,
+	}
+}
+
+// triggerEvent is an event buffered by a TriggerLevelWriter.
+type triggerEvent struct {
+	level Level
+	p     []byte
+}
+
+// Write implements the io.Writer interface. It writes p directly.
+func (w *TriggerLevelWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.Writer.Write(p)
+}
+
+// WriteLevel implements the LevelWriter interface.
+func (w *TriggerLevelWriter) WriteLevel(l Level, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.triggered && l != NoLevel {
+		if !levelLess(w.ConditionalLevel, l) {
+			w.buffer(l, p)
+			return len(p), nil
+		}
+		if !levelLess(l, w.TriggerLevel) {
+			if err := w.trigger(); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return w.write(l, p)
+}
+
+func (w *TriggerLevelWriter) write(l Level, p []byte) (n int, err error) {
+	if lw, ok := w.Writer.(LevelWriter); ok {
+		return lw.WriteLevel(l, p)
+	}
+	return w.Writer.Write(p)
+}
+
+// buffer adds a copy of p, which is pooled by the logger, to the buffered
+// events, dropping the oldest ones above MaxBufferSize.
+func (w *TriggerLevelWriter) buffer(l Level, p []byte) {
+	max := w.MaxBufferSize
+	if max <= 0 {
+		max = TriggerBufferSize
+	}
+	if len(p) > max {
+		return
+	}
+	w.events = append(w.events, triggerEvent{l, append([]byte(nil), p...)})
+	w.size += len(p)
+	drop := 0
+	for w.size > max {
+		w.size -= len(w.events[drop].p)
+		drop++
+	}
+	if drop > 0 {
+		n := copy(w.events, w.events[drop:])
+		for i := n; i < len(w.events); i++ {
+			w.events[i] = triggerEvent{}
+		}
+		w.events = w.events[:n]
+	}
+}
+
+// Trigger writes the buffered events, and the next events directly until
+// Close.
+func (w *TriggerLevelWriter) Trigger() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.trigger()
+}
+
+func (w *TriggerLevelWriter) trigger() error {
+	w.triggered = true
+	for len(w.events) > 0 {
+		e := w.events[0]
+		if _, err := w.write(e.level, e.p); err != nil {
+			return err
+		}
+		w.events[0] = triggerEvent{}
+		w.events = w.events[1:]
+		w.size -= len(e.p)
+	}
+	w.events = nil
+	return nil
+}
+
+// Close discards the buffered events, and resets w to buffer the next
+// ones, for instance to reuse it for another request. It doesn't close
+// Writer.
+func (w *TriggerLevelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.triggered = false
+	w.events, w.size = nil, 0
+	return nil
 }
@@ -4,11 +4,18 @@ package zerolog
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestMultiSyslogWriter(t *testing.T) {
@@ -113,6 +120,268 @@ func TestResilientMultiWriter(t *testing.T) {
 	}
 }
 
+// closeCounter counts the calls to its Close and Sync methods, which
+// return err.
+type closeCounter struct {
+	bytes.Buffer
+	closed, synced int
+	err            error
+}
+
+func (c *closeCounter) Close() error {
+	c.closed++
+	return c.err
+}
+
+func (c *closeCounter) Sync() error {
+	c.synced++
+	return c.err
+}
+
+func TestMultiLevelWriterClose(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	a, b, c := &closeCounter{err: errA}, &closeCounter{err: errB}, &closeCounter{}
+	log := New(MultiLevelWriter(a, SyncWriter(b), FilteredLevelWriter{Writer: c}, a, os.Stderr, &bytes.Buffer{}))
+
+	err := log.Sync()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Sync() = %v, want errors a and b", err)
+	}
+	if a.synced != 2 || b.synced != 1 || c.synced != 1 {
+		t.Errorf("invalid syncs: a %d, b %d, c %d", a.synced, b.synced, c.synced)
+	}
+
+	err = log.Close()
+	if !errors.Is(err, errA) || !errors.Is(err, errB) {
+		t.Errorf("Close() = %v, want errors a and b", err)
+	}
+	if a.closed != 1 || b.closed != 1 || c.closed != 1 {
+		t.Errorf("invalid closes: a %d, b %d, c %d", a.closed, b.closed, c.closed)
+	}
+	if err := log.Close(); err != nil || a.closed != 1 {
+		t.Errorf("second Close() = %v, closed a %d times", err, a.closed)
+	}
+}
+
+func TestMultiLevelWriterCloseNested(t *testing.T) {
+	a, b, c, d := &closeCounter{}, &closeCounter{}, &closeCounter{}, &closeCounter{}
+	log := New(MultiLevelWriter(
+		FilteredLevelWriter{Writer: MultiLevelWriter(a, b)},
+		FilteredLevelWriter{Writer: MultiLevelWriter(c, d)},
+	))
+	if err := log.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if a.closed != 1 || b.closed != 1 || c.closed != 1 || d.closed != 1 {
+		t.Errorf("invalid closes: a %d, b %d, c %d, d %d", a.closed, b.closed, c.closed, d.closed)
+	}
+}
+
+func TestConsoleWriterClose(t *testing.T) {
+	out, errOut := &closeCounter{}, &closeCounter{}
+	for _, tt := range []struct {
+		name string
+		w    ConsoleWriter
+		want [2]int
+	}{
+		{"Out", ConsoleWriter{Out: out}, [2]int{1, 0}},
+		{"Same", ConsoleWriter{Out: out, ErrOut: out}, [2]int{1, 0}},
+		{"Both", ConsoleWriter{Out: out, ErrOut: errOut}, [2]int{1, 1}},
+		{"Stdout", ConsoleWriter{Out: os.Stdout, ErrOut: os.Stderr}, [2]int{0, 0}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			out.closed, errOut.closed = 0, 0
+			if err := tt.w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got := [2]int{out.closed, errOut.closed}; got != tt.want {
+				t.Errorf("closed %v times, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilteredLevelWriter(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		policy NoLevelPolicy
+		errors string
+	}{
+		{"pass", NoLevelPass, "warn,error,nolevel,"},
+		{"as trace", NoLevelAsTrace, "warn,error,"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			all, errs := &bytes.Buffer{}, &bytes.Buffer{}
+			log := New(MultiLevelWriter(all, FilteredLevelWriter{Writer: errs, Level: WarnLevel, NoLevel: tt.policy}))
+			log.Trace().Msg("trace")
+			log.Debug().Msg("debug")
+			log.Info().Msg("info")
+			log.Warn().Msg("warn")
+			log.Error().Msg("error")
+			log.Log().Msg("nolevel")
+
+			messages := func(b *bytes.Buffer) string {
+				var s string
+				for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+					var evt struct{ Message string }
+					if err := json.Unmarshal([]byte(line), &evt); err != nil {
+						t.Fatalf("invalid JSON %q: %v", line, err)
+					}
+					s += evt.Message + ","
+				}
+				return s
+			}
+			if got, want := messages(all), "trace,debug,info,warn,error,nolevel,"; got != want {
+				t.Errorf("all messages = %q, want %q", got, want)
+			}
+			if got := messages(errs); got != tt.errors {
+				t.Errorf("filtered messages = %q, want %q", got, tt.errors)
+			}
+		})
+	}
+
+	var w FilteredLevelWriter
+	w.Writer, w.Level = &bytes.Buffer{}, ErrorLevel
+	if n, err := w.WriteLevel(InfoLevel, []byte("dropped")); n != 7 || err != nil {
+		t.Errorf("WriteLevel() of a dropped event = %d, %v, want 7, nil", n, err)
+	}
+	if w.Writer.(*bytes.Buffer).Len() != 0 {
+		t.Errorf("WriteLevel() wrote a dropped event")
+	}
+}
+
+func TestTriggerLevelWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := &TriggerLevelWriter{Writer: out, ConditionalLevel: DebugLevel, TriggerLevel: ErrorLevel}
+	log := New(w)
+
+	log.Debug().Msg("a")
+	log.Info().Msg("b")
+	log.Trace().Msg("c")
+	if got, want := out.String(), `{"level":"info","message":"b"}`+"\n"; got != want {
+		t.Errorf("invalid log output before the trigger:\ngot:  %v\nwant: %v", got, want)
+	}
+	log.Error().Msg("d")
+	log.Debug().Msg("e")
+	want := `{"level":"info","message":"b"}` + "\n" +
+		`{"level":"debug","message":"a"}` + "\n" +
+		`{"level":"trace","message":"c"}` + "\n" +
+		`{"level":"error","message":"d"}` + "\n" +
+		`{"level":"debug","message":"e"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	// Close discards the buffered events and rearms the writer.
+	out.Reset()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log.Debug().Msg("f")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	log.Debug().Msg("g")
+	if out.Len() != 0 {
+		t.Errorf("invalid log output after Close: %q", out.String())
+	}
+	if err := w.Trigger(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), `{"level":"debug","message":"g"}`+"\n"; got != want {
+		t.Errorf("invalid log output after Trigger:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestNewTriggerLevelWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	log := New(NewTriggerLevelWriter(out, 0, ErrorLevel))
+	for i := 0; i < 3; i++ {
+		log.Debug().Int("i", i).Msg("")
+	}
+	log.Warn().Msg("almost")
+	if out.Len() != 0 {
+		t.Errorf("invalid log output before the trigger: %q", out.String())
+	}
+	log.Error().Msg("failed")
+	want := `{"level":"debug","i":0}` + "\n" +
+		`{"level":"debug","i":1}` + "\n" +
+		`{"level":"debug","i":2}` + "\n" +
+		`{"level":"warn","message":"almost"}` + "\n" +
+		`{"level":"error","message":"failed"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTriggerLevelWriterMaxBufferSize(t *testing.T) {
+	out := &bytes.Buffer{}
+	line := `{"level":"debug","message":"0"}` + "\n"
+	w := &TriggerLevelWriter{Writer: out, ConditionalLevel: DebugLevel, TriggerLevel: ErrorLevel, MaxBufferSize: 2 * len(line)}
+	log := New(w)
+	for i := 0; i < 5; i++ {
+		log.Debug().Msg(strconv.Itoa(i))
+	}
+	if err := w.Trigger(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"debug","message":"3"}` + "\n" + `{"level":"debug","message":"4"}` + "\n"
+	if got := out.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestTriggerLevelWriterInterleaved(t *testing.T) {
+	buf := &bytes.Buffer{}
+	out := SyncWriter(buf)
+	const requests, lines = 20, 10
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w := &TriggerLevelWriter{Writer: out, ConditionalLevel: DebugLevel, TriggerLevel: ErrorLevel}
+			defer w.Close()
+			log := New(w).With().Int("req", i).Logger()
+			var lwg sync.WaitGroup
+			for j := 0; j < lines; j++ {
+				lwg.Add(1)
+				go func(j int) {
+					defer lwg.Done()
+					log.Debug().Int("line", j).Msg("")
+				}(j)
+			}
+			lwg.Wait()
+			if i%2 == 0 {
+				log.Error().Msg("failed")
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	counts := map[int]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var evt struct {
+			Level   string
+			Req     int
+			Message string
+		}
+		if err := json.Unmarshal([]byte(line), &evt); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		if evt.Req%2 != 0 {
+			t.Errorf("event of a request without error written: %q", line)
+		}
+		if evt.Level == "error" && counts[evt.Req] != lines {
+			t.Errorf("error of request %d written after %d debug events, want %d", evt.Req, counts[evt.Req], lines)
+		}
+		counts[evt.Req]++
+	}
+	if len(counts) != requests/2 {
+		t.Errorf("events of %d requests written, want %d", len(counts), requests/2)
+	}
+}
+
 type testingLog struct {
 	testing.TB
 	buf bytes.Buffer
@@ -174,3 +443,257 @@ func TestTestWriter(t *testing.T) {
 	}
 
 }
+
+func TestGzipWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := GzipWriter(&buf, gzip.BestSpeed)
+	log := New(w)
+	log.Info().Int("i", 1).Msg("first")
+	log.Warn().Int("i", 2).Msg("second")
+	log.Error().Int("i", 3).Msg("third")
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("Write() after Close() should have failed")
+	}
+
+	r, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"info","i":1,"message":"first"}` + "\n" +
+		`{"level":"warn","i":2,"message":"second"}` + "\n" +
+		`{"level":"error","i":3,"message":"third"}` + "\n"
+	if got := string(b); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestGzipWriterFlushInterval(t *testing.T) {
+	pr, pw := io.Pipe()
+	w := GzipWriter(pw, gzip.DefaultCompression)
+	w.SetFlushInterval(10 * time.Millisecond)
+	log := New(w)
+
+	// Read before logging: writes block until the pipe is read.
+	want := strings.Repeat(`{"level":"info","message":"concurrent"}`+"\n", 4)
+	got := make(chan string, 1)
+	go func() {
+		// The periodic flush must make the events readable without Close.
+		b := make([]byte, len(want))
+		if r, err := gzip.NewReader(pr); err == nil {
+			_, _ = io.ReadFull(r, b)
+		}
+		got <- string(b)
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Info().Msg("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case got := <-got:
+		if got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("events were not flushed")
+	}
+	_ = w.Close()
+}
+
+func TestBufferedWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := BufferedWriter(&buf, 1024, 0)
+	log := New(w)
+	log.Info().Msg("first")
+	log.Warn().Msg("second")
+	if buf.Len() != 0 {
+		t.Errorf("events written before Sync: %q", buf.String())
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	want := `{"level":"info","message":"first"}` + "\n" + `{"level":"warn","message":"second"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+
+	func() {
+		defer func() { _ = recover() }()
+		log.Info().Msg("third")
+		log.Panic().Msg("fourth")
+	}()
+	want += `{"level":"info","message":"third"}` + "\n" + `{"level":"panic","message":"fourth"}` + "\n"
+	if got := buf.String(); got != want {
+		t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+	}
+}
+
+func TestWritersCloseStderr(t *testing.T) {
+	stderr := os.Stderr
+	defer func() { os.Stderr = stderr }()
+	for _, tt := range []struct {
+		name  string
+		close func(w io.Writer) error
+	}{
+		{"BufferedWriter", func(w io.Writer) error { return BufferedWriter(w, 64, time.Hour).Close() }},
+		{"GzipWriter", func(w io.Writer) error { return GzipWriter(w, 1).Close() }},
+		{"RoutingWriter", func(w io.Writer) error {
+			rw := RoutingWriter(func(Level, []byte) string { return "a" }, func(string) (io.Writer, error) { return w, nil })
+			if _, err := rw.Write([]byte("{}\n")); err != nil {
+				return err
+			}
+			return rw.Close()
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := os.CreateTemp(t.TempDir(), "stderr")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			os.Stderr = f
+			if err := tt.close(f); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Stat(); err != nil {
+				t.Errorf("stderr closed: %v", err)
+			}
+		})
+	}
+}
+
+func TestBufferedWriterClose(t *testing.T) {
+	var buf bytes.Buffer
+	// A buffer smaller than some events, flushed while logging.
+	w := BufferedWriter(&buf, 64, time.Millisecond)
+	log := New(w)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				log.Info().Int("i", i).Int("j", j).Msg(strings.Repeat("x", j))
+			}
+		}(i)
+	}
+	wg.Wait()
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte("late")); err == nil {
+		t.Error("Write() after Close() should have failed")
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("got %d events, want 800", len(lines))
+	}
+	for _, line := range lines {
+		var e struct{ I, J int }
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid event %q: %v", line, err)
+		}
+	}
+}
+
+func TestBufferedWriterFlushInterval(t *testing.T) {
+	pr, pw := io.Pipe()
+	w := BufferedWriter(pw, 0, 10*time.Millisecond)
+	log := New(w)
+
+	want := `{"level":"info","message":"flushed"}` + "\n"
+	got := make(chan string, 1)
+	go func() {
+		// The periodic flush must make the event readable without Sync.
+		b := make([]byte, len(want))
+		_, _ = io.ReadFull(pr, b)
+		got <- string(b)
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	log.Info().Msg("flushed")
+
+	select {
+	case got := <-got:
+		if got != want {
+			t.Errorf("invalid log output:\ngot:  %v\nwant: %v", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("events were not flushed")
+	}
+	_ = w.Close()
+}
+
+// fakeTB is a TestingLog recording the calls of the TestWriter methods.
+type fakeTB struct {
+	logs     []string
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeTB) Log(args ...interface{}) { t.logs = append(t.logs, fmt.Sprint(args...)) }
+func (t *fakeTB) Logf(format string, args ...interface{}) {
+	t.logs = append(t.logs, fmt.Sprintf(format, args...))
+}
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+func (t *fakeTB) Helper()          {}
+func (t *fakeTB) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func TestTestWriterFailOnError(t *testing.T) {
+	tb := &fakeTB{}
+	log := New(NewTestWriter(tb, TestWriterFailOnError))
+	log.Info().Msg("info")
+	log.Warn().Msg("warn")
+	log.Log().Msg("no level")
+	log.Error().Msg("error")
+	wantLogs := []string{
+		`{"level":"info","message":"info"}`,
+		`{"level":"warn","message":"warn"}`,
+		`{"message":"no level"}`,
+		`{"level":"error","message":"error"}`,
+	}
+	if !reflect.DeepEqual(tb.logs, wantLogs) {
+		t.Errorf("invalid logs:\ngot:  %q\nwant: %q", tb.logs, wantLogs)
+	}
+	wantErrors := []string{`zerolog: error level event logged: {"level":"error","message":"error"}`}
+	if !reflect.DeepEqual(tb.errors, wantErrors) {
+		t.Errorf("invalid errors:\ngot:  %q\nwant: %q", tb.errors, wantErrors)
+	}
+
+	tb = &fakeTB{}
+	New(NewTestWriter(tb)).Error().Msg("error")
+	if len(tb.logs) != 1 || len(tb.errors) != 0 {
+		t.Errorf("without FailOnError: logs %q, errors %q", tb.logs, tb.errors)
+	}
+}
+
+func TestTestWriterAfterCompletion(t *testing.T) {
+	tb := &fakeTB{}
+	log := New(NewTestWriter(tb, TestWriterFailOnError))
+	log.Info().Msg("during")
+	if len(tb.cleanups) != 1 {
+		t.Fatalf("got %d cleanups, want 1", len(tb.cleanups))
+	}
+	tb.cleanups[0]()
+	log.Info().Msg("after")
+	log.Error().Msg("after")
+	if want := []string{`{"level":"info","message":"during"}`}; !reflect.DeepEqual(tb.logs, want) || len(tb.errors) != 0 {
+		t.Errorf("events written after completion: logs %q, errors %q", tb.logs, tb.errors)
+	}
+}